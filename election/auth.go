@@ -0,0 +1,38 @@
+package election
+
+import (
+	"fmt"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Connects and authenticates the zookeeper sessions
+//
+
+// dial - creates a new zookeeper connection authenticated using the configured scheme (if any),
+// the connection is closed if the authentication fails (the caller replaces the current one using setConn)
+func (m *Manager) dial() (ZKConnection, <-chan zk.Event, error) {
+
+	conn, events, err := m.connector(m.config.ZKURL, m.sessionTimeoutDuration)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(m.config.AuthScheme) == 0 {
+		return conn, events, nil
+	}
+
+	if err := conn.AddAuth(m.config.AuthScheme, []byte(m.config.AuthCredential)); err != nil {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "dial").Err(err).Msg("zookeeper authentication failed, closing the connection")
+		}
+
+		conn.Close()
+
+		return nil, nil, fmt.Errorf("zookeeper authentication failed using scheme \"%s\": %w", m.config.AuthScheme, err)
+	}
+
+	return conn, events, nil
+}
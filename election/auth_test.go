@@ -0,0 +1,125 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the zookeeper session authentication and the configured ACL
+//
+
+// authConfig - configures the digest authentication and ACL
+func authConfig(c *Config) {
+
+	c.AuthScheme = "digest"
+	c.AuthCredential = "user:pass"
+	c.ACL = zk.DigestACL(zk.PermAll, "user", "pass")
+}
+
+// TestDigestAuth - tests if every session is authenticated and the nodes are created using the configured ACL
+func TestDigestAuth(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(authConfig, func(c *Config) { c.ZKElectionNodeURI = "/election/master" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.Equal(t, []string{"digest:user:pass"}, fake.lastConnection().authentications(), "expected the authenticated session")
+
+	acl := zk.DigestACL(zk.PermAll, "user", "pass")
+
+	for _, path := range []string{"/election", "/election/master", "/slaves"} {
+		node, ok := fake.node(path)
+		if assert.True(t, ok, "expected node: %s", path) {
+			assert.Equal(t, acl, node.acl, "expected the configured acl: %s", path)
+		}
+	}
+
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return m.ReconnectCount() == 1 })
+	if !assert.True(t, ok, "expected the reconnection") {
+		return
+	}
+
+	assert.Equal(t, []string{"digest:user:pass"}, fake.lastConnection().authentications(), "expected the reconnected session authenticated")
+}
+
+// TestAuthFailure - tests if the start fails and the connection is closed when the authentication fails
+func TestAuthFailure(t *testing.T) {
+
+	fake := newFakeZK()
+	fake.authError = zk.ErrAuthFailed
+
+	m := fake.newManager(authConfig)
+
+	_, err := m.Start()
+	if !assert.Error(t, err, "expected an error starting") {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "authentication failed", "expected the authentication error")
+	assert.Equal(t, zk.StateDisconnected, fake.lastConnection().State(), "expected the connection closed")
+	assert.False(t, m.IsMaster(), "expected no election")
+}
+
+// TestReconnectAuthFailure - tests if the reconnection stops and signals the failure when the authentication fails
+func TestReconnectAuthFailure(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(authConfig)
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	signals := record(feedback)
+
+	fake.mutex.Lock()
+	fake.authError = zk.ErrAuthFailed
+	fake.mutex.Unlock()
+
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return signals.contains(Failed) })
+	if !assert.True(t, ok, "expected the failed signal") {
+		return
+	}
+
+	connections := fake.numConnections()
+	<-time.After(100 * time.Millisecond)
+
+	assert.Equal(t, connections, fake.numConnections(), "expected no more reconnection attempts")
+	assert.Equal(t, 0, m.ReconnectCount(), "expected no reconnection")
+}
+
+// TestInvalidAuthConfig - tests the authentication configuration validation
+func TestInvalidAuthConfig(t *testing.T) {
+
+	config := &Config{
+		ReconnectionTimeout:    "1s",
+		SessionTimeout:         "1s",
+		ClusterChangeCheckTime: "1s",
+		ClusterChangeWaitTime:  "1s",
+		AuthScheme:             "digest",
+	}
+
+	_, err := New(config)
+	assert.Error(t, err, "expected an error without the credential")
+
+	config.AuthScheme = ""
+	config.AuthCredential = "user:pass"
+
+	_, err = New(config)
+	assert.Error(t, err, "expected an error without the scheme")
+}
@@ -0,0 +1,82 @@
+package election
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+//
+// The exponential reconnection backoff
+//
+
+const (
+	// reconnectionJitter - the random variation (+/-20%) added to each reconnection wait, so the nodes
+	// disconnected together do not reconnect in lockstep
+	reconnectionJitter float64 = 0.2
+
+	// defaultReconnectionBackoffFactor - the default multiplier of the reconnection wait
+	defaultReconnectionBackoffFactor float64 = 2
+
+	// defaultReconnectionMaxTimeout - the default limit of the reconnection wait (the reconnection timeout if greater)
+	defaultReconnectionMaxTimeout time.Duration = time.Minute
+)
+
+// parseBackoffConfig - parses the reconnection max timeout (defaults to 1 minute or the timeout, if greater)
+// and the backoff factor (defaults to 2)
+func parseBackoffConfig(config *Config, timeout time.Duration) (time.Duration, float64, error) {
+
+	maxTimeout := defaultReconnectionMaxTimeout
+	if timeout > maxTimeout {
+		maxTimeout = timeout
+	}
+
+	var err error
+
+	if len(config.ReconnectionMaxTimeout) > 0 {
+		maxTimeout, err = time.ParseDuration(config.ReconnectionMaxTimeout)
+		if err != nil || maxTimeout < timeout {
+			return 0, 0, fmt.Errorf("invalid reconnection max timeout duration: %s", config.ReconnectionMaxTimeout)
+		}
+	}
+
+	factor := config.ReconnectionBackoffFactor
+	if factor == 0 {
+		factor = defaultReconnectionBackoffFactor
+	} else if factor < 1 {
+		return 0, 0, fmt.Errorf("invalid reconnection backoff factor: %f", config.ReconnectionBackoffFactor)
+	}
+
+	return maxTimeout, factor, nil
+}
+
+// reconnectionBackoff - the reconnection wait multiplied by the factor on each attempt, limited by the max timeout
+type reconnectionBackoff struct {
+	current    time.Duration
+	maxTimeout time.Duration
+	factor     float64
+}
+
+// newReconnectionBackoff - creates a new backoff starting with the reconnection timeout
+// (created on each disconnection, so it is reset after a successful reconnection)
+func (m *Manager) newReconnectionBackoff() *reconnectionBackoff {
+
+	return &reconnectionBackoff{
+		current:    m.reconnectionTimeoutDuration,
+		maxTimeout: m.reconnectionMaxTimeout,
+		factor:     m.reconnectionBackoffFactor,
+	}
+}
+
+// next - returns the next reconnection wait added by the random jitter (a factor of 1 keeps the wait fixed)
+func (b *reconnectionBackoff) next() time.Duration {
+
+	wait := b.current
+
+	b.current = time.Duration(float64(b.current) * b.factor)
+	if b.current > b.maxTimeout {
+		b.current = b.maxTimeout
+	}
+
+	return time.Duration(float64(wait) * (1 + reconnectionJitter*(2*rand.Float64()-1)))
+}
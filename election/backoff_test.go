@@ -0,0 +1,181 @@
+package election
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the exponential reconnection backoff
+//
+
+// assertWait - asserts the wait is the expected one added by the jitter
+func assertWait(t *testing.T, expected, wait time.Duration) {
+
+	min := time.Duration(float64(expected) * (1 - reconnectionJitter))
+	max := time.Duration(float64(expected) * (1 + reconnectionJitter))
+
+	assert.True(t, wait >= min && wait <= max, "expected a wait between %s and %s: %s", min, max, wait)
+}
+
+// TestReconnectionBackoff - tests if the wait grows exponentially up to the max timeout
+func TestReconnectionBackoff(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.ReconnectionTimeout = "100ms"
+		c.ReconnectionMaxTimeout = "400ms"
+		c.ReconnectionBackoffFactor = 2
+	})
+
+	backoff := m.newReconnectionBackoff()
+
+	for _, expected := range []time.Duration{100, 200, 400, 400} {
+		assertWait(t, expected*time.Millisecond, backoff.next())
+	}
+
+	assertWait(t, 100*time.Millisecond, m.newReconnectionBackoff().next())
+}
+
+// TestDefaultReconnectionMaxTimeout - tests if the wait is limited by default when the max timeout is not configured
+func TestDefaultReconnectionMaxTimeout(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.ReconnectionTimeout = "20s"
+		c.ReconnectionBackoffFactor = 2
+	})
+
+	backoff := m.newReconnectionBackoff()
+
+	for _, expected := range []time.Duration{20, 40, 60, 60} {
+		assertWait(t, expected*time.Second, backoff.next())
+	}
+
+	m = fake.newManager(func(c *Config) {
+		c.ReconnectionTimeout = "2m"
+		c.ReconnectionBackoffFactor = 2
+	})
+
+	backoff = m.newReconnectionBackoff()
+
+	for i := 0; i < 3; i++ {
+		assertWait(t, 2*time.Minute, backoff.next())
+	}
+}
+
+// TestDefaultReconnectionBackoff - tests if the wait grows by the default factor when no factor is configured
+func TestDefaultReconnectionBackoff(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.ReconnectionTimeout = "100ms" })
+
+	backoff := m.newReconnectionBackoff()
+
+	for _, expected := range []time.Duration{100, 200, 400} {
+		assertWait(t, expected*time.Millisecond, backoff.next())
+	}
+}
+
+// TestFixedReconnectionBackoff - tests if the wait is fixed but still randomized with a factor of 1
+func TestFixedReconnectionBackoff(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.ReconnectionTimeout = "100ms"
+		c.ReconnectionBackoffFactor = 1
+	})
+
+	backoff := m.newReconnectionBackoff()
+
+	distinct := map[time.Duration]struct{}{}
+
+	for i := 0; i < 20; i++ {
+		wait := backoff.next()
+		assertWait(t, 100*time.Millisecond, wait)
+		distinct[wait] = struct{}{}
+	}
+
+	assert.Greater(t, len(distinct), 1, "expected randomized waits")
+}
+
+// TestReconnectionBackoffReset - tests if the backoff restarts from the reconnection timeout after a reconnection
+func TestReconnectionBackoffReset(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.ReconnectionTimeout = "20ms"
+		c.ReconnectionMaxTimeout = "1s"
+		c.ReconnectionBackoffFactor = 2
+	})
+
+	var failures int32
+	m.connector = func(servers []string, sessionTimeout time.Duration) (ZKConnection, <-chan zk.Event, error) {
+		if atomic.AddInt32(&failures, -1) >= 0 {
+			return nil, nil, fmt.Errorf("no server available")
+		}
+
+		return fake.connect(servers, sessionTimeout)
+	}
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	drain(feedback)
+
+	atomic.StoreInt32(&failures, 3)
+
+	start := time.Now()
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return m.ReconnectCount() == 1 })
+	if !assert.True(t, ok, "expected the reconnection") {
+		return
+	}
+
+	assert.True(t, time.Since(start) >= 240*time.Millisecond, "expected the growing waits (20ms, 40ms, 80ms and 160ms)")
+
+	start = time.Now()
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok = waitFor(2*time.Second, func() bool { return m.ReconnectCount() == 2 })
+	if !assert.True(t, ok, "expected the second reconnection") {
+		return
+	}
+
+	assert.True(t, time.Since(start) < 150*time.Millisecond, "expected the backoff restarted")
+}
+
+// TestInvalidReconnectionBackoff - tests the backoff configuration validation
+func TestInvalidReconnectionBackoff(t *testing.T) {
+
+	newConfig := func() *Config {
+		return &Config{
+			ReconnectionTimeout:    "1s",
+			SessionTimeout:         "1s",
+			ClusterChangeCheckTime: "1s",
+			ClusterChangeWaitTime:  "1s",
+		}
+	}
+
+	config := newConfig()
+	config.ReconnectionBackoffFactor = 0.5
+
+	_, err := New(config)
+	assert.Error(t, err, "expected an error with a factor lower than 1")
+
+	config = newConfig()
+	config.ReconnectionMaxTimeout = "500ms"
+
+	_, err = New(config)
+	assert.Error(t, err, "expected an error with a max timeout lower than the timeout")
+}
@@ -0,0 +1,226 @@
+package election
+
+import (
+	"sync/atomic"
+
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The typed election callbacks (preferred over the feedback channel)
+//
+
+const callbackChannelSize int = 100
+
+// callbacks - the registered election callbacks
+type callbacks struct {
+	registered       bool
+	onMaster         func()
+	onSlave          func()
+	onDisconnected   func()
+	onClusterChanged func(cluster *Cluster)
+}
+
+// OnMaster - sets a callback invoked when this node becomes the master
+func (m *Manager) OnMaster(callback func()) {
+
+	m.register(func(c *callbacks) { c.onMaster = callback })
+}
+
+// OnSlave - sets a callback invoked when this node becomes a slave
+func (m *Manager) OnSlave(callback func()) {
+
+	m.register(func(c *callbacks) { c.onSlave = callback })
+}
+
+// OnDisconnected - sets a callback invoked when the election is disconnected
+// (like the Disconnected signal, it is invoked once until this node is elected again)
+func (m *Manager) OnDisconnected(callback func()) {
+
+	m.register(func(c *callbacks) { c.onDisconnected = callback })
+}
+
+// OnClusterChanged - sets a callback invoked with the new cluster info when the cluster nodes change
+// (the cluster has the added and removed nodes, so there is no need to diff the cluster info)
+func (m *Manager) OnClusterChanged(callback func(cluster *Cluster)) {
+
+	m.register(func(c *callbacks) { c.onClusterChanged = callback })
+}
+
+// register - sets the callback (before or after starting) and starts the goroutine running the callbacks,
+// so a slow callback never blocks the election loops (callbacks are discarded if too many are pending),
+// once a callback is registered the feedback channel does not need to be consumed (signals are discarded if it is full)
+func (m *Manager) register(set func(c *callbacks)) {
+
+	m.callbacksMutex.Lock()
+	defer m.callbacksMutex.Unlock()
+
+	set(&m.callbacks)
+	m.callbacks.registered = true
+	m.runCallbacks()
+}
+
+// resumeCallbacks - starts running the registered callbacks of this manager and its groups again after a Disconnect
+func (m *Manager) resumeCallbacks() {
+
+	m.callbacksMutex.Lock()
+	if m.callbacks.registered {
+		m.runCallbacks()
+	}
+	m.callbacksMutex.Unlock()
+
+	for _, group := range m.groups {
+		group.resumeCallbacks()
+	}
+}
+
+// runCallbacks - starts the goroutine running the callbacks if it is not running, after the previous one ends
+// to keep the callbacks in order (must be called locked)
+func (m *Manager) runCallbacks() {
+
+	if m.callbackStop != nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	previous := m.callbackDone
+
+	m.callbackStop = stop
+	m.callbackDone = done
+
+	go func() {
+		defer close(done)
+
+		if previous != nil {
+			<-previous
+		}
+
+		for {
+			select {
+			case callback := <-m.callbackChannel:
+				callback()
+			case <-stop:
+				m.runPendingCallbacks()
+				return
+			}
+		}
+	}()
+}
+
+// runPendingCallbacks - runs the callbacks queued before the goroutine running them was stopped
+func (m *Manager) runPendingCallbacks() {
+
+	for {
+		select {
+		case callback := <-m.callbackChannel:
+			callback()
+		default:
+			return
+		}
+	}
+}
+
+// stopCallbacks - stops the goroutine running the callbacks after it runs the pending ones
+func (m *Manager) stopCallbacks() {
+
+	m.callbacksMutex.Lock()
+	defer m.callbacksMutex.Unlock()
+
+	if m.callbackStop == nil {
+		return
+	}
+
+	close(m.callbackStop)
+	m.callbackStop = nil
+}
+
+// signal - sends the signal to the feedback channel and runs the matching callback (if any)
+func (m *Manager) signal(signal int) {
+
+	m.dispatch(signal, nil)
+}
+
+// signalClusterChanged - sends the cluster changed signal and runs the cluster changed callback (if any)
+func (m *Manager) signalClusterChanged(cluster *Cluster) {
+
+	m.dispatch(ClusterChanged, cluster)
+}
+
+// sendFeedback - sends the signal to the feedback channel unless it is closed, the blocking send
+// ends when the start context is done (the channel is closed next)
+func (m *Manager) sendFeedback(signal int, block bool) {
+
+	m.feedbackMutex.RLock()
+	defer m.feedbackMutex.RUnlock()
+
+	if m.feedbackClosed {
+		return
+	}
+
+	select {
+	case m.feedbackChannel <- signal:
+		return
+	default:
+	}
+
+	if !block {
+		return
+	}
+
+	select {
+	case m.feedbackChannel <- signal:
+	case <-m.getContext().Done():
+	}
+}
+
+// dispatch - sends the signal to the feedback channel (without blocking if there are callbacks)
+// and queues the matching callback (the Disconnected signal is sent once, every ending event loop sends it)
+func (m *Manager) dispatch(signal int, cluster *Cluster) {
+
+	if signal == Disconnected && !atomic.CompareAndSwapInt32(&m.disconnectNotified, 0, 1) {
+		return
+	}
+
+	if signal == Master || signal == Slave {
+		atomic.StoreInt32(&m.disconnectNotified, 0)
+	}
+
+	m.callbacksMutex.RLock()
+	c := m.callbacks
+	m.callbacksMutex.RUnlock()
+
+	if !c.registered {
+		m.sendFeedback(signal, true)
+		return
+	}
+
+	m.sendFeedback(signal, false)
+
+	var callback func()
+
+	switch signal {
+	case Master:
+		callback = c.onMaster
+	case Slave:
+		callback = c.onSlave
+	case Disconnected:
+		callback = c.onDisconnected
+	case ClusterChanged:
+		if c.onClusterChanged != nil {
+			callback = func() { c.onClusterChanged(cluster) }
+		}
+	}
+
+	if callback == nil {
+		return
+	}
+
+	select {
+	case m.callbackChannel <- callback:
+	default:
+		if logh.WarnEnabled {
+			m.logger.Warn().Str("func", "dispatch").Msgf("election callbacks are too slow, discarding signal: %d", signal)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package election
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the typed election callbacks
+//
+
+// TestCallbacks - tests the master, cluster changed and disconnected callbacks without consuming the feedback channel
+func TestCallbacks(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	var masters, disconnections int32
+	clusters := make(chan *Cluster, 10)
+
+	m.OnMaster(func() { atomic.AddInt32(&masters, 1) })
+
+	_, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	ok := waitFor(time.Second, func() bool { return atomic.LoadInt32(&masters) == 1 })
+	assert.True(t, ok, "expected the master callback")
+
+	m.OnClusterChanged(func(cluster *Cluster) { clusters <- cluster })
+	m.OnDisconnected(func() { atomic.AddInt32(&disconnections, 1) })
+
+	other, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	_, err = other.Create("/slaves/node-b", []byte("node-b"), int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the other node") {
+		return
+	}
+
+	select {
+	case cluster := <-clusters:
+		assert.Equal(t, 2, cluster.NumNodes, "expected the new cluster size")
+		assert.Equal(t, []string{"node-b"}, cluster.Slaves, "expected the new slave")
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the cluster changed callback registered after starting")
+	}
+
+	m.Disconnect()
+
+	ok = waitFor(time.Second, func() bool { return atomic.LoadInt32(&disconnections) > 0 })
+	assert.True(t, ok, "expected the disconnected callback")
+
+	m.callbacksMutex.RLock()
+	done := m.callbackDone
+	m.callbacksMutex.RUnlock()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the callbacks goroutine stopped")
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&disconnections), "expected a single disconnected callback")
+}
+
+// TestCallbacksAfterReconnection - tests if the callbacks keep running after a reconnection
+// and the disconnected callback is invoked once per disconnection
+func TestCallbacksAfterReconnection(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	var elections, disconnections int32
+
+	m.OnMaster(func() { atomic.AddInt32(&elections, 1) })
+	m.OnSlave(func() { atomic.AddInt32(&elections, 1) })
+	m.OnDisconnected(func() { atomic.AddInt32(&disconnections, 1) })
+
+	_, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return atomic.LoadInt32(&elections) == 2 })
+	assert.True(t, ok, "expected the election callback after the reconnection")
+
+	<-time.After(100 * time.Millisecond)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&disconnections), "expected a single disconnected callback")
+}
+
+// TestSlaveCallback - tests the slave callback
+func TestSlaveCallback(t *testing.T) {
+
+	fake := newFakeZK()
+
+	holder, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	_, err = holder.Create("/master", []byte("node-b"), 0, zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the election node") {
+		return
+	}
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-c" })
+
+	slaves := make(chan struct{}, 1)
+	m.OnSlave(func() { slaves <- struct{}{} })
+
+	_, err = m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	select {
+	case <-slaves:
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the slave callback")
+	}
+}
+
+// TestSlowCallback - tests if a slow callback does not block the election
+func TestSlowCallback(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	m.OnMaster(func() { <-release })
+
+	started := make(chan error, 1)
+	go func() {
+		_, err := m.Start()
+		started <- err
+	}()
+
+	select {
+	case err := <-started:
+		assert.NoError(t, err, "expected no error starting")
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the election not blocked by the callback")
+		return
+	}
+
+	assert.True(t, m.IsMaster(), "expected this node as master")
+
+	m.Disconnect()
+}
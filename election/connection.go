@@ -0,0 +1,39 @@
+package election
+
+import (
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+//
+// The zookeeper connection abstraction used by the election manager
+//
+
+// ZKConnection - the zookeeper operations used by the election (implemented by *zk.Conn)
+type ZKConnection interface {
+	Get(path string) ([]byte, *zk.Stat, error)
+	GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error)
+	ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error)
+	Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error)
+	Delete(path string, version int32) error
+	Children(path string) ([]string, *zk.Stat, error)
+	ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error)
+	AddAuth(scheme string, auth []byte) error
+	State() zk.State
+	Close()
+}
+
+// connector - creates a new zookeeper connection
+type connector func(servers []string, sessionTimeout time.Duration) (ZKConnection, <-chan zk.Event, error)
+
+// zkConnect - connects to the zookeeper servers
+func zkConnect(servers []string, sessionTimeout time.Duration) (ZKConnection, <-chan zk.Event, error) {
+
+	conn, events, err := zk.Connect(servers, sessionTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return conn, events, nil
+}
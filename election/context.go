@@ -0,0 +1,105 @@
+package election
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Ends the election when the start context is done
+//
+
+// StartWithContext - starts to listen zk events until the context is done, then disconnects, waits the event loops
+// to end, sends the Terminated signal and closes the feedback channels (the consumers can range over them)
+// (the reconnection loop also ends when the context is done, the manager can not be started again after it)
+func (m *Manager) StartWithContext(ctx context.Context) (*chan int, error) {
+
+	if ctx == nil {
+		return nil, fmt.Errorf("null context found")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.setContext(ctx)
+
+	feedback, err := m.start()
+	if err != nil {
+		return nil, err
+	}
+
+	go m.terminateOnDone()
+
+	return feedback, nil
+}
+
+// terminateOnDone - disconnects and closes the feedback channels when the context is done
+func (m *Manager) terminateOnDone() {
+
+	<-m.getContext().Done()
+
+	if logh.InfoEnabled {
+		m.logger.Info().Str("func", "terminateOnDone").Msg("context is done, terminating the election")
+	}
+
+	m.Disconnect()
+
+	for _, group := range m.groups {
+		group.closeFeedback()
+	}
+
+	m.closeFeedback()
+}
+
+// closeFeedback - waits the event loops to end, sends the Terminated signal (discarded if the channel is full)
+// and closes the feedback channel, the signals sent after it are discarded
+func (m *Manager) closeFeedback() {
+
+	m.loops.Wait()
+
+	m.feedbackMutex.Lock()
+	defer m.feedbackMutex.Unlock()
+
+	if m.feedbackClosed {
+		return
+	}
+
+	select {
+	case m.feedbackChannel <- Terminated:
+	default:
+		if logh.WarnEnabled {
+			m.logger.Warn().Str("func", "closeFeedback").Msg("feedback channel is full, discarding the terminated signal")
+		}
+	}
+
+	m.feedbackClosed = true
+	close(m.feedbackChannel)
+}
+
+// goLoop - runs the event loop in its own goroutine, tracking it until it ends
+func (m *Manager) goLoop(loop func()) {
+
+	m.loops.Add(1)
+
+	go func() {
+		defer m.loops.Done()
+		loop()
+	}()
+}
+
+// cancelled - checks if the context is done, logging the end of the event loop
+func (m *Manager) cancelled(funcName string) bool {
+
+	if m.getContext().Err() == nil {
+		return false
+	}
+
+	if logh.InfoEnabled {
+		m.logger.Info().Str("func", funcName).Msg("context is done, ending the event loop")
+	}
+
+	return true
+}
@@ -0,0 +1,229 @@
+package election
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the end of the election when the start context is done
+//
+
+// collect - ranges over the feedback channel until it is closed (returns false on timeout)
+func collect(feedback *chan int, timeout time.Duration) ([]int, bool) {
+
+	signals := []int{}
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for signal := range *feedback {
+			signals = append(signals, signal)
+		}
+	}()
+
+	select {
+	case <-done:
+		return signals, true
+	case <-time.After(timeout):
+		return nil, false
+	}
+}
+
+// TestStartWithContext - tests if the feedback channel is terminated and closed when the context is cancelled
+func TestStartWithContext(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(groupsConfig)
+
+	group, err := m.GroupFeedback("compactor")
+	if !assert.NoError(t, err, "expected the group feedback") {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	feedback, err := m.StartWithContext(ctx)
+	if !assert.NoError(t, err, "expected no error starting") {
+		cancel()
+		return
+	}
+
+	assert.True(t, m.IsMaster(), "expected this node as master")
+
+	groupSignals := make(chan []int, 1)
+	go func() {
+		signals, _ := collect(group, 2*time.Second)
+		groupSignals <- signals
+	}()
+
+	cancel()
+
+	signals, closed := collect(feedback, 2*time.Second)
+	if !assert.True(t, closed, "expected the feedback channel closed") {
+		return
+	}
+
+	if assert.NotEmpty(t, signals, "expected the feedback signals") {
+		assert.Equal(t, Master, signals[0], "expected the master signal first")
+		assert.Equal(t, Terminated, signals[len(signals)-1], "expected the terminated signal last")
+	}
+
+	signals = <-groupSignals
+	if assert.NotEmpty(t, signals, "expected the group feedback closed") {
+		assert.Equal(t, Terminated, signals[len(signals)-1], "expected the group terminated signal last")
+	}
+}
+
+// TestStartWithContextReconnection - tests if the reconnection loop ends when the context is cancelled
+func TestStartWithContextReconnection(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.ReconnectionTimeout = "1h" })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	feedback, err := m.StartWithContext(ctx)
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	m.connector = func(servers []string, sessionTimeout time.Duration) (ZKConnection, <-chan zk.Event, error) {
+		return nil, nil, fmt.Errorf("no server available")
+	}
+
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	<-time.After(50 * time.Millisecond)
+
+	start := time.Now()
+	cancel()
+
+	signals, closed := collect(feedback, 2*time.Second)
+	if !assert.True(t, closed, "expected the feedback channel closed while reconnecting") {
+		return
+	}
+
+	assert.True(t, time.Since(start) < time.Second, "expected the reconnection wait interrupted")
+	assert.Equal(t, Terminated, signals[len(signals)-1], "expected the terminated signal last")
+	assert.Equal(t, 1, fake.numConnections(), "expected no reconnection")
+}
+
+// TestRestartWithContext - tests if the election restarts with a new context while the loops of the previous start end
+func TestRestartWithContext(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	<-time.After(50 * time.Millisecond)
+
+	m.Disconnect()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = m.StartWithContext(ctx)
+	if !assert.NoError(t, err, "expected no error restarting") {
+		return
+	}
+
+	assert.Equal(t, 2, fake.numConnections(), "expected a new connection")
+
+	<-time.After(50 * time.Millisecond)
+
+	assert.True(t, m.IsMaster(), "expected this node as master again")
+}
+
+// TestStartWithDoneContext - tests the context validation
+func TestStartWithDoneContext(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := m.StartWithContext(ctx)
+	assert.Equal(t, context.Canceled, err, "expected the context error")
+	assert.Equal(t, 0, fake.numConnections(), "expected no connection")
+}
+
+// feedbackClosed - checks if the feedback channel was closed
+func feedbackClosed(m *Manager) bool {
+
+	m.feedbackMutex.RLock()
+	defer m.feedbackMutex.RUnlock()
+
+	return m.feedbackClosed
+}
+
+// TestSignalAfterTermination - tests if the signals sent after the feedback channel is closed are discarded
+func TestSignalAfterTermination(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	feedback, err := m.StartWithContext(ctx)
+	if !assert.NoError(t, err, "expected no error starting") {
+		cancel()
+		return
+	}
+
+	cancel()
+
+	_, closed := collect(feedback, 2*time.Second)
+	if !assert.True(t, closed, "expected the feedback channel closed") {
+		return
+	}
+
+	assert.NotPanics(t, func() {
+		m.signal(Disconnected)
+		m.signal(Master)
+	}, "expected the signals discarded")
+
+	assert.NotPanics(t, func() {
+		if _, err := m.TryBecomeMaster(); err == nil {
+			m.Disconnect()
+		}
+	}, "expected the master attempt signal discarded")
+}
+
+// TestTerminationFullFeedback - tests if the feedback channel is closed when it is full and not consumed
+func TestTerminationFullFeedback(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err := m.StartWithContext(ctx)
+	if !assert.NoError(t, err, "expected no error starting") {
+		cancel()
+		return
+	}
+
+	for i := 0; i < defaultChannelSize; i++ {
+		m.sendFeedback(ClusterChanged, false)
+	}
+
+	cancel()
+
+	ok := waitFor(2*time.Second, func() bool { return feedbackClosed(m) })
+	assert.True(t, ok, "expected the full feedback channel closed")
+}
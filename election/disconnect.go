@@ -0,0 +1,35 @@
+package election
+
+import (
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+//
+// Waits the closed zookeeper connection to report the disconnection
+//
+
+const (
+	// defaultDisconnectWaitTime - the default max time waiting for the closed connection to report the disconnection
+	defaultDisconnectWaitTime time.Duration = 2 * time.Second
+
+	// disconnectCheckInterval - the interval checking the closed connection state
+	disconnectCheckInterval time.Duration = 10 * time.Millisecond
+)
+
+// waitDisconnected - waits the connection state to be disconnected (returns false if the timeout is reached)
+func (m *Manager) waitDisconnected(timeout time.Duration) bool {
+
+	deadline := time.Now().Add(timeout)
+
+	for m.conn().State() != zk.StateDisconnected {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		<-time.After(disconnectCheckInterval)
+	}
+
+	return true
+}
@@ -0,0 +1,74 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the wait for the disconnection of the closed connection
+//
+
+// openConn - a connection never reporting the disconnection
+type openConn struct {
+	*fakeConn
+}
+
+// Close - does not change the connection state
+func (c *openConn) Close() {}
+
+// TestDisconnectPrompt - tests if the disconnection returns as soon as the connection reports it is closed
+func TestDisconnectPrompt(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	start := time.Now()
+	m.Disconnect()
+
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "expected a prompt disconnection")
+}
+
+// TestDisconnectWaitTime - tests if the wait for the disconnection is bounded by the configured time
+func TestDisconnectWaitTime(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.DisconnectWaitTime = "100ms" })
+
+	conn, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting") {
+		return
+	}
+
+	m.setConn(&openConn{fakeConn: conn.(*fakeConn)})
+
+	start := time.Now()
+	m.Disconnect()
+	elapsed := time.Since(start)
+
+	assert.True(t, elapsed >= 100*time.Millisecond, "expected the wait for the disconnection")
+	assert.True(t, elapsed < time.Second, "expected the wait bounded by the configured time")
+}
+
+// TestInvalidDisconnectWaitTime - tests the disconnect wait time validation
+func TestInvalidDisconnectWaitTime(t *testing.T) {
+
+	_, err := New(&Config{
+		ReconnectionTimeout:    "1s",
+		SessionTimeout:         "1s",
+		ClusterChangeCheckTime: "1s",
+		ClusterChangeWaitTime:  "1s",
+		DisconnectWaitTime:     "-1s",
+	})
+
+	assert.Error(t, err, "expected an error with a negative disconnect wait time")
+}
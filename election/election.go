@@ -1,14 +1,16 @@
 package election
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/uol/gobol/logh"
 
-	"github.com/uol/gobol/util"
-
 	"sync"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -21,23 +23,64 @@ import (
 
 const defaultChannelSize int = 5
 
+// maxCreateSlaveDirAttempts - the number of attempts creating the slave directory on transient errors
+const maxCreateSlaveDirAttempts int = 3
+
 // Manager - handles the zookeeper election
 type Manager struct {
-	zkConnection                   *zk.Conn
+	zkConnection                   ZKConnection
+	connectionMutex                sync.RWMutex
+	connector                      connector
 	config                         *Config
-	isMaster                       bool
+	isMaster                       int32
 	defaultACL                     []zk.ACL
+	electionACL                    []zk.ACL
+	slaveACL                       []zk.ACL
 	logger                         *logh.ContextualLogger
 	feedbackChannel                chan int
-	clusterConnectionEventChannel  <-chan zk.Event
+	feedbackClosed                 bool
+	feedbackMutex                  sync.RWMutex
 	sessionID                      int64
 	nodeName                       string
 	clusterNodes                   sync.Map
-	terminate                      bool
+	clusterNodesMutex              sync.Mutex
+	terminate                      int32
 	sessionTimeoutDuration         time.Duration
 	reconnectionTimeoutDuration    time.Duration
+	reconnectionMaxTimeout         time.Duration
+	reconnectionBackoffFactor      float64
 	clusterChangeCheckTimeDuration time.Duration
 	clusterChangeWaitTimeDuration  time.Duration
+	clusterChangeCheckJitter       time.Duration
+	clusterChangeCheckMaxTime      time.Duration
+	staleSlaveDeleteRetries        int
+	staleSlaveRetryInterval        time.Duration
+	disconnectWaitTime             time.Duration
+	groups                         map[string]*Manager
+	reconnectCount                 int64
+	lastReconnect                  int64
+	sessionState                   zk.State
+	readOnly                       int32
+	stateChangeChannel             chan stateTransition
+	stateMutex                     sync.Mutex
+	role                           int
+	roleChanged                    chan struct{}
+	roleMutex                      sync.Mutex
+	transitions                    *transitionCounter
+	masterDataCallback             func(data []byte)
+	reconnectCallback              func() error
+	inFlightOperations             int32
+	operationsMutex                sync.Mutex
+	resigned                       int32
+	ctx                            context.Context
+	contextMutex                   sync.RWMutex
+	loops                          sync.WaitGroup
+	callbacks                      callbacks
+	callbacksMutex                 sync.RWMutex
+	callbackChannel                chan func()
+	callbackStop                   chan struct{}
+	callbackDone                   chan struct{}
+	disconnectNotified             int32
 }
 
 // New - creates a new instance
@@ -53,6 +96,11 @@ func New(config *Config) (*Manager, error) {
 		return nil, fmt.Errorf("invalid reconnection timeout duration: %s", config.ReconnectionTimeout)
 	}
 
+	reconnectionMaxTimeout, reconnectionBackoffFactor, err := parseBackoffConfig(config, reconnectionTimeoutDuration)
+	if err != nil {
+		return nil, err
+	}
+
 	clusterChangeCheckTimeDuration, err := time.ParseDuration(config.ClusterChangeCheckTime)
 	if err != nil {
 		return nil, fmt.Errorf("invalid cluster change check time duration: %s", config.ClusterChangeCheckTime)
@@ -63,26 +111,98 @@ func New(config *Config) (*Manager, error) {
 		return nil, fmt.Errorf("invalid cluster change wait time duration: %s", config.ClusterChangeWaitTime)
 	}
 
+	clusterChangeCheckJitter, clusterChangeCheckMaxTime, err := parsePollingConfig(config, clusterChangeCheckTimeDuration)
+	if err != nil {
+		return nil, err
+	}
+
+	staleSlaveDeleteRetries, staleSlaveRetryInterval, err := parseStaleSlaveConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := newGroups(config)
+	if err != nil {
+		return nil, err
+	}
+
+	flappingWindowDuration := defaultFlappingWindow
+	if len(config.FlappingWindow) > 0 {
+		flappingWindowDuration, err = time.ParseDuration(config.FlappingWindow)
+		if err != nil || flappingWindowDuration < time.Duration(numTransitionBuckets) {
+			return nil, fmt.Errorf("invalid flapping window duration: %s", config.FlappingWindow)
+		}
+	}
+
+	disconnectWaitTime := defaultDisconnectWaitTime
+	if len(config.DisconnectWaitTime) > 0 {
+		disconnectWaitTime, err = time.ParseDuration(config.DisconnectWaitTime)
+		if err != nil || disconnectWaitTime < 0 {
+			return nil, fmt.Errorf("invalid disconnect wait time duration: %s", config.DisconnectWaitTime)
+		}
+	}
+
+	flappingThreshold := defaultFlappingThreshold
+	if config.FlappingThreshold < 0 {
+		return nil, fmt.Errorf("invalid flapping threshold: %d", config.FlappingThreshold)
+	} else if config.FlappingThreshold > 0 {
+		flappingThreshold = config.FlappingThreshold
+	}
+
+	if (len(config.AuthScheme) > 0) != (len(config.AuthCredential) > 0) {
+		return nil, fmt.Errorf("the auth scheme and the auth credential must be configured together")
+	}
+
+	defaultACL := zk.WorldACL(zk.PermAll)
+	if len(config.ACL) > 0 {
+		defaultACL = config.ACL
+	}
+
+	electionACL := defaultACL
+	if len(config.ElectionACL) > 0 {
+		electionACL = config.ElectionACL
+	}
+
+	slaveACL := defaultACL
+	if len(config.SlaveACL) > 0 {
+		slaveACL = config.SlaveACL
+	}
+
 	return &Manager{
 		zkConnection:                   nil,
+		connector:                      zkConnect,
 		config:                         config,
-		defaultACL:                     zk.WorldACL(zk.PermAll),
+		defaultACL:                     defaultACL,
+		electionACL:                    electionACL,
+		slaveACL:                       slaveACL,
 		logger:                         logh.CreateContextualLogger("pkg", "election"),
 		feedbackChannel:                make(chan int, defaultChannelSize),
-		clusterConnectionEventChannel:  nil,
 		clusterNodes:                   sync.Map{},
-		terminate:                      false,
 		sessionTimeoutDuration:         sessionTimeoutDuration,
 		reconnectionTimeoutDuration:    reconnectionTimeoutDuration,
+		reconnectionMaxTimeout:         reconnectionMaxTimeout,
+		reconnectionBackoffFactor:      reconnectionBackoffFactor,
 		clusterChangeCheckTimeDuration: clusterChangeCheckTimeDuration,
 		clusterChangeWaitTimeDuration:  clusterChangeWaitTimeDuration,
+		clusterChangeCheckJitter:       clusterChangeCheckJitter,
+		clusterChangeCheckMaxTime:      clusterChangeCheckMaxTime,
+		staleSlaveDeleteRetries:        staleSlaveDeleteRetries,
+		staleSlaveRetryInterval:        staleSlaveRetryInterval,
+		disconnectWaitTime:             disconnectWaitTime,
+		groups:                         groups,
+		sessionState:                   zk.StateUnknown,
+		role:                           noRole,
+		roleChanged:                    make(chan struct{}),
+		transitions:                    newTransitionCounter(flappingWindowDuration, flappingThreshold),
+		ctx:                            context.Background(),
+		callbackChannel:                make(chan func(), callbackChannelSize),
 	}, nil
 }
 
 // getNodeData - check if node exists
 func (m *Manager) getNodeData(node string) (*string, error) {
 
-	data, _, err := m.zkConnection.Get(node)
+	data, _, err := m.conn().Get(node)
 
 	exists := true
 	if err != nil {
@@ -105,7 +225,7 @@ func (m *Manager) getNodeData(node string) (*string, error) {
 // getZKMasterNode - returns zk master node name
 func (m *Manager) getZKMasterNode() (*string, error) {
 
-	if m.zkConnection == nil {
+	if m.conn() == nil {
 		return nil, nil
 	}
 
@@ -120,82 +240,138 @@ func (m *Manager) getZKMasterNode() (*string, error) {
 	return data, nil
 }
 
-// connect - connects to the zookeeper
+// connect - connects to the zookeeper, consuming the session events until the election ends
+// or the connection is replaced by a reconnection
 func (m *Manager) connect() error {
 
 	if logh.InfoEnabled {
 		m.logger.Info().Str("func", "connect").Msg("connecting to zookeeper...")
 	}
 
-	var err error
-
 	// Create the ZK connection
-	m.zkConnection, m.clusterConnectionEventChannel, err = zk.Connect(m.config.ZKURL, m.sessionTimeoutDuration)
+	conn, events, err := m.dial()
 	if err != nil {
 		return err
 	}
 
-	go func() {
+	m.setConn(conn)
+	m.setTerminating(false)
+
+	m.goLoop(func() {
 		for {
 
-			if m.terminate {
+			if m.conn() != conn {
+				return
+			}
+
+			if m.terminating() {
 				if logh.InfoEnabled {
 					m.logger.Info().Str("func", "connect").Msg("ending cluster connection event loop")
 				}
-				m.feedbackChannel <- Disconnected
+				m.signal(Disconnected)
 				return
 			}
 
-			event := <-m.clusterConnectionEventChannel
+			var event zk.Event
+
+			select {
+			case event = <-events:
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("connect") {
+				return
+			}
+
+			if m.terminating() || m.conn() != conn {
+				continue
+			}
+
 			if event.Type == zk.EventSession {
+				m.notifyStateChange(event.State)
 				if event.State == zk.StateConnected ||
 					event.State == zk.StateConnectedReadOnly {
 					if logh.InfoEnabled {
-						m.logger.Info().Str("func", "connect").Msg("connection established with zookeeper")
+						m.withState(m.logger.Info()).Str("func", "connect").Msg("connection established with zookeeper")
 					}
 				} else if event.State == zk.StateSaslAuthenticated ||
 					event.State == zk.StateHasSession {
 					if logh.InfoEnabled {
 						m.logger.Info().Str("func", "connect").Msg("session created in zookeeper")
 					}
-				} else if event.State == zk.StateAuthFailed ||
-					event.State == zk.StateDisconnected ||
+				} else if event.State == zk.StateAuthFailed {
+					if logh.ErrorEnabled {
+						m.logger.Error().Str("func", "connect").Msg("zookeeper authentication failed, not reconnecting")
+					}
+					m.Disconnect()
+					m.signal(Failed)
+					return
+				} else if event.State == zk.StateDisconnected ||
 					event.State == zk.StateExpired {
 					if logh.InfoEnabled {
-						m.logger.Info().Str("func", "connect").Msg("zookeeper connection was lost")
+						m.withState(m.logger.Info()).Str("func", "connect").Msg("zookeeper connection was lost")
 					}
-					m.Disconnect()
-					m.feedbackChannel <- Disconnected
+					m.disconnect()
+					m.signal(Disconnected)
+					backoff := m.newReconnectionBackoff()
 					for {
-						<-time.After(m.reconnectionTimeoutDuration)
-						m.zkConnection, m.clusterConnectionEventChannel, err = zk.Connect(m.config.ZKURL, m.sessionTimeoutDuration)
-						if err != nil {
+						select {
+						case <-time.After(backoff.next()):
+						case <-m.getContext().Done():
+						}
+
+						if m.cancelled("connect") {
+							return
+						}
+
+						err := m.reconnect()
+						if errors.Is(err, zk.ErrAuthFailed) {
 							if logh.ErrorEnabled {
-								m.logger.Error().Str("func", "connect").Err(err).Msg("error reconnecting to zookeeper")
+								m.logger.Error().Str("func", "connect").Err(err).Msg("zookeeper authentication failed, not reconnecting")
 							}
-						} else {
-							_, err := m.Start()
-							if err != nil {
-								if logh.ErrorEnabled {
-									m.logger.Error().Str("func", "connect").Err(err).Msg("error starting election loop")
-								}
-							} else {
-								return
+							m.signal(Failed)
+							return
+						} else if err != nil {
+							if logh.ErrorEnabled {
+								m.logger.Error().Str("func", "connect").Err(err).Msg("error reconnecting to zookeeper")
 							}
+							continue
 						}
+
+						atomic.AddInt64(&m.reconnectCount, 1)
+						atomic.StoreInt64(&m.lastReconnect, time.Now().UnixNano())
+						return
 					}
 				}
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
-// Start - starts to listen zk events
+// Start - starts to listen zk events, the returned feedback channel receives the election signals
+// (kept for compatibility, the typed callbacks are preferred: OnMaster, OnSlave, OnDisconnected and OnClusterChanged)
 func (m *Manager) Start() (*chan int, error) {
 
-	m.terminate = false
+	m.setContext(context.Background())
+
+	return m.start()
+}
+
+// start - starts to listen zk events using the current context
+func (m *Manager) start() (*chan int, error) {
+
+	m.setTerminating(false)
+	m.resumeCallbacks()
+
+	if m.config.Standalone {
+		if err := m.startGroupsStandalone(); err != nil {
+			return nil, err
+		}
+
+		return m.startStandalone()
+	}
 
 	err := m.connect()
 	if err != nil {
@@ -205,12 +381,38 @@ func (m *Manager) Start() (*chan int, error) {
 		return nil, err
 	}
 
+	if err := m.startElection(); err != nil {
+		return nil, err
+	}
+
+	if err := m.startGroups(); err != nil {
+		return nil, err
+	}
+
+	return &m.feedbackChannel, nil
+}
+
+// startElection - elects this node using the current connection and starts to listen the election and slave nodes
+func (m *Manager) startElection() error {
+
+	var err error
+
+	for _, path := range []string{m.config.ZKElectionNodeURI, m.config.ZKSlaveNodesURI} {
+		err = m.createAncestors(path)
+		if err != nil {
+			if logh.ErrorEnabled {
+				m.logger.Error().Str("func", "Start").Err(err).Msg("error creating the ancestors of node: " + path)
+			}
+			return err
+		}
+	}
+
 	err = m.electForMaster()
 	if err != nil {
 		if logh.ErrorEnabled {
 			m.logger.Error().Str("func", "Start").Err(err).Msg("error electing this node for master")
 		}
-		return nil, err
+		return err
 	}
 
 	err = m.createSlaveDir("Start")
@@ -218,7 +420,7 @@ func (m *Manager) Start() (*chan int, error) {
 		if logh.ErrorEnabled {
 			m.logger.Error().Str("func", "Start").Err(err).Msg("error creating slave directory")
 		}
-		return nil, err
+		return err
 	}
 
 	err = m.listenForElectionEvents()
@@ -226,7 +428,7 @@ func (m *Manager) Start() (*chan int, error) {
 		if logh.ErrorEnabled {
 			m.logger.Error().Str("func", "Start").Err(err).Msg("error listening for zk election node events")
 		}
-		return nil, err
+		return err
 	}
 
 	err = m.listenForNodeEvents()
@@ -234,35 +436,64 @@ func (m *Manager) Start() (*chan int, error) {
 		if logh.ErrorEnabled {
 			m.logger.Error().Str("func", "Start").Err(err).Msg("error listening for zk slave node events")
 		}
-		return nil, err
+		return err
 	}
 
-	return &m.feedbackChannel, nil
+	if m.masterDataCallback != nil {
+		m.watchMasterData()
+	}
+
+	return nil
 }
 
 // listenForElectionEvents - starts to listen for election node events
+// (the loop ends when the election ends or the connection is replaced by a reconnection)
 func (m *Manager) listenForElectionEvents() error {
 
-	_, _, electionEventsChannel, err := m.zkConnection.ExistsW(m.config.ZKElectionNodeURI)
+	conn := m.conn()
+
+	_, _, electionEventsChannel, err := conn.ExistsW(m.config.ZKElectionNodeURI)
 	if err != nil {
 		return err
 	}
 
-	go func() {
+	m.goLoop(func() {
 		for {
 
-			if m.terminate {
+			if m.conn() != conn {
+				return
+			}
+
+			if m.terminating() {
 				if logh.InfoEnabled {
 					m.logger.Info().Str("func", "listenForElectionEvents").Msg("ending election events loop")
 				}
-				m.feedbackChannel <- Disconnected
+				m.signal(Disconnected)
+				return
+			}
+
+			var event zk.Event
+
+			select {
+			case event = <-electionEventsChannel:
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("listenForElectionEvents") {
 				return
 			}
 
-			event := <-electionEventsChannel
-			if event.Type == zk.EventNodeDeleted {
+			if m.terminating() || m.conn() != conn {
+				continue
+			}
+
+			if event.Type == zk.EventNodeDeleted && m.hasResigned() {
+				if logh.InfoEnabled {
+					m.logger.Info().Str("func", "listenForElectionEvents").Msg("master has resigned, not running for the election")
+				}
+			} else if event.Type == zk.EventNodeDeleted {
 				if logh.InfoEnabled {
-					m.logger.Info().Str("func", "listenForElectionEvents").Msg("master has quit, trying to be the new master...")
+					m.withState(m.logger.Info()).Str("func", "listenForElectionEvents").Msg("master has quit, trying to be the new master...")
 				}
 				err := m.electForMaster()
 				if err != nil {
@@ -272,17 +503,17 @@ func (m *Manager) listenForElectionEvents() error {
 				}
 			} else if event.Type == zk.EventNodeCreated {
 				if logh.InfoEnabled {
-					m.logger.Info().Str("func", "listenForElectionEvents").Msg("a new master has been elected...")
+					m.withState(m.logger.Info()).Str("func", "listenForElectionEvents").Msg("a new master has been elected...")
 				}
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
-// listenForNodeEvents - starts to listen for node events
-// Note: the zkConnection.ExistsW(...) and zkConnection.ChildrenW(...) does not work in the expected way, so I'm doing this manually
+// listenForNodeEvents - starts to listen for node events watching the slave nodes, or polling the cluster nodes
+// if the UsePollingForNodeEvents is configured or the watch can not be set
 func (m *Manager) listenForNodeEvents() error {
 
 	cluster, err := m.GetClusterInfo()
@@ -290,72 +521,101 @@ func (m *Manager) listenForNodeEvents() error {
 		return err
 	}
 
+	m.clusterNodesMutex.Lock()
 	for _, node := range cluster.Nodes {
 		m.clusterNodes.Store(node, true)
 	}
+	m.clusterNodesMutex.Unlock()
 
-	go func() {
+	if !m.config.UsePollingForNodeEvents {
+		err = m.watchNodeEvents()
+		if err == nil {
+			return nil
+		}
+
+		if logh.WarnEnabled {
+			m.logger.Warn().Str("func", "listenForNodeEvents").Err(err).Msg("error watching the slave nodes, polling the cluster instead")
+		}
+	}
+
+	conn := m.conn()
+
+	m.goLoop(func() {
 		for {
 
-			if m.terminate {
+			if m.conn() != conn {
+				return
+			}
+
+			if m.terminating() {
 				if logh.InfoEnabled {
 					m.logger.Info().Str("func", "listenForNodeEvents").Msg("ending node events loop")
 				}
-				m.feedbackChannel <- Disconnected
+				m.signal(Disconnected)
 				return
 			}
 
-			<-time.After(m.clusterChangeCheckTimeDuration)
+			select {
+			case <-time.After(m.nextPollInterval()):
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("listenForNodeEvents") {
+				return
+			}
+
+			if m.terminating() || m.conn() != conn {
+				continue
+			}
 
 			cluster, err := m.GetClusterInfo()
 			if err != nil {
 				if logh.ErrorEnabled {
 					m.logger.Error().Str("func", "listenForNodeEvents").Err(err).Send()
 				}
-			} else {
-				changed := false
-				if len(cluster.Nodes) != util.GetSyncMapSize(&m.clusterNodes) {
-					changed = true
-				} else {
-					for _, node := range cluster.Nodes {
-						if _, ok := m.clusterNodes.Load(node); !ok {
-							changed = true
-							break
-						}
-					}
-				}
-
-				if changed {
-					if logh.InfoEnabled {
-						m.logger.Info().Str("func", "listenForNodeEvents").Msg("cluster node configuration changed")
-					}
-					m.clusterNodes.Range(func(k, _ interface{}) bool {
-						m.clusterNodes.Delete(k)
-						return true
-					})
-					for _, node := range cluster.Nodes {
-						m.clusterNodes.Store(node, true)
-					}
-					m.feedbackChannel <- ClusterChanged
-					<-time.After(m.clusterChangeWaitTimeDuration)
-				}
+			} else if m.notifyClusterChange("listenForNodeEvents", cluster) {
+				<-time.After(m.clusterChangeWaitTimeDuration)
 			}
 		}
-	}()
+	})
 
 	return nil
 }
 
-// Disconnect - disconnects from the zookeeper
+// Disconnect - disconnects from the zookeeper and stops running the callbacks (after the pending ones)
 func (m *Manager) Disconnect() {
 
-	m.terminate = true
-	if m.zkConnection != nil && m.zkConnection.State() != zk.StateDisconnected {
-		m.zkConnection.Close()
-		m.feedbackChannel <- Disconnected
-		time.Sleep(2 * time.Second)
+	m.disconnect()
+	m.stopCallbacks()
+
+	for _, group := range m.groups {
+		group.stopCallbacks()
+	}
+}
+
+// disconnect - closes the zookeeper connection ending the event loops (the callbacks keep running for the reconnection)
+func (m *Manager) disconnect() {
+
+	m.stopOperations()
+	m.setRole(noRole)
+	m.terminateGroups()
+	conn := m.conn()
+	if conn != nil && conn.State() != zk.StateDisconnected {
+		if !m.waitOperations(inFlightWaitTimeout) {
+			if logh.WarnEnabled {
+				m.logger.Warn().Str("func", "Disconnect").Msg("closing the zk connection with operations in flight")
+			}
+		}
+		conn.Close()
+		m.signal(Disconnected)
+		m.notifyGroups(Disconnected)
+		if !m.waitDisconnected(m.disconnectWaitTime) {
+			if logh.WarnEnabled {
+				m.logger.Warn().Str("func", "Disconnect").Msg("zk connection did not report the disconnection in time")
+			}
+		}
 		if logh.InfoEnabled {
-			m.logger.Info().Str("func", "Disconnect").Msg("zk connection closed")
+			m.withState(m.logger.Info()).Str("func", "Disconnect").Msg("zk connection closed")
 		}
 	} else {
 		if logh.InfoEnabled {
@@ -386,23 +646,74 @@ func (m *Manager) createSlaveDir(funcName string) error {
 		return err
 	}
 
-	if data == nil {
-		path, err := m.zkConnection.Create(m.config.ZKSlaveNodesURI, nil, int32(0), m.defaultACL)
-		if err != nil {
+	if data != nil {
+		return nil
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		path, err := m.create(m.config.ZKSlaveNodesURI, nil, int32(0), m.slaveACL)
+		if err == nil {
+			if logh.InfoEnabled {
+				m.logger.Info().Str("func", funcName).Msg("slave node directory created: " + path)
+			}
+			return nil
+		}
+
+		if err == zk.ErrNodeExists {
+			if logh.InfoEnabled {
+				m.logger.Info().Str("func", funcName).Msg("slave node directory was created by another node")
+			}
+			return nil
+		}
+
+		if !isTransientError(err) || attempt >= maxCreateSlaveDirAttempts {
 			if logh.ErrorEnabled {
 				m.logger.Error().Str("func", funcName).Err(err).Msg("error creating slave node directory")
 			}
 			return err
 		}
 
-		if logh.InfoEnabled {
-			m.logger.Info().Str("func", funcName).Msg("slave node directory created: " + path)
+		if logh.WarnEnabled {
+			m.logger.Warn().Str("func", funcName).Err(err).Msg(fmt.Sprintf("transient error creating slave node directory, retrying (%d of %d)...", attempt, maxCreateSlaveDirAttempts))
+		}
+
+		<-time.After(m.reconnectionTimeoutDuration)
+	}
+}
+
+// createAncestors - creates the missing persistent parent nodes of the path
+func (m *Manager) createAncestors(path string) error {
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	ancestor := ""
+	for i := 0; i < len(parts)-1; i++ {
+
+		ancestor += "/" + parts[i]
+
+		_, err := m.create(ancestor, nil, int32(0), m.defaultACL)
+		if err == nil {
+			if logh.InfoEnabled {
+				m.logger.Info().Str("func", "createAncestors").Msg("ancestor node created: " + ancestor)
+			}
+			continue
+		}
+
+		if err != zk.ErrNodeExists {
+			return err
 		}
 	}
 
 	return nil
 }
 
+// isTransientError - checks if the zookeeper error may succeed when retried
+func isTransientError(err error) bool {
+
+	return err == zk.ErrConnectionClosed || err == zk.ErrNoServer || err == zk.ErrSessionMoved
+}
+
 // registerAsSlave - register this node as a slave
 func (m *Manager) registerAsSlave(nodeName string) error {
 
@@ -419,7 +730,7 @@ func (m *Manager) registerAsSlave(nodeName string) error {
 	}
 
 	if data == nil {
-		path, err := m.zkConnection.Create(slaveNode, []byte(nodeName), int32(zk.FlagEphemeral), m.defaultACL)
+		path, err := m.create(slaveNode, []byte(nodeName), int32(zk.FlagEphemeral), m.slaveACL)
 		if err != nil {
 			if logh.ErrorEnabled {
 				m.logger.Error().Str("func", "registerAsSlave").Err(err).Msg("error creating a slave node")
@@ -436,16 +747,22 @@ func (m *Manager) registerAsSlave(nodeName string) error {
 		}
 	}
 
-	m.isMaster = false
-	m.feedbackChannel <- Slave
+	m.setMaster(false)
+	m.setRole(Slave)
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", "registerAsSlave").Msg("this node became a slave")
+	}
+
+	m.signal(Slave)
 
 	return nil
 }
 
-// electForMaster - try to elect this node as the master
+// electForMaster - try to elect this node as the master (only the lowest election contender creates the election node)
 func (m *Manager) electForMaster() error {
 
-	name, err := m.GetHostname()
+	name, err := m.getNodeName()
 	if err != nil {
 		return err
 	}
@@ -460,22 +777,40 @@ func (m *Manager) electForMaster() error {
 			if logh.InfoEnabled {
 				m.logger.Info().Str("func", "electForMaster").Msg("this node is the master: " + *zkMasterNode)
 			}
-			m.isMaster = true
-		} else {
-			if logh.InfoEnabled {
-				m.logger.Info().Str("func", "electForMaster").Msg("another node is the master: " + *zkMasterNode)
-			}
-			return m.registerAsSlave(name)
+			return m.becomeMaster("electForMaster", m.config.ZKElectionNodeURI, name)
+		}
+
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "electForMaster").Msg("another node is the master: " + *zkMasterNode)
 		}
+		return m.registerAsSlave(name)
 	}
 
-	path, err := m.zkConnection.Create(m.config.ZKElectionNodeURI, []byte(name), int32(zk.FlagEphemeral), m.defaultACL)
+	err = m.registerContender(name)
+	if err != nil {
+		return err
+	}
+
+	lowest, err := m.lowestContender()
+	if err != nil {
+		return err
+	}
+
+	if lowest != name {
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "electForMaster").Msg("a lower node is running for master: " + lowest)
+		}
+		m.watchContender(lowest)
+		return m.registerAsSlave(name)
+	}
+
+	path, err := m.create(m.config.ZKElectionNodeURI, []byte(name), int32(zk.FlagEphemeral), m.electionACL)
 	if err != nil {
 		if err.Error() == "zk: node already exists" {
 			if logh.InfoEnabled {
 				m.logger.Info().Str("func", "electForMaster").Msg("some node has became master before this node")
 			}
-			return m.registerAsSlave(name)
+			return m.breakTie(name)
 		}
 
 		if logh.ErrorEnabled {
@@ -485,47 +820,123 @@ func (m *Manager) electForMaster() error {
 		return err
 	}
 
-	if logh.InfoEnabled {
-		m.logger.Info().Str("func", "electForMaster").Msg("master node created: " + path)
-	}
+	return m.becomeMaster("electForMaster", path, name)
+}
 
-	m.isMaster = true
-	m.feedbackChannel <- Master
+// becomeMaster - sets this node as the master removing its slave node (if any), if the slave node removal
+// is required and it fails, the election node is released and this node does not become the master
+func (m *Manager) becomeMaster(funcName, path, name string) error {
 
-	slaveNode := m.config.ZKSlaveNodesURI + "/" + name
-	slave, err := m.getNodeData(slaveNode)
-	if err != nil {
-		if logh.ErrorEnabled {
-			m.logger.Error().Str("func", "electForMaster").Err(err).Msgf("error retrieving a slave node data '%s'", slaveNode)
-		}
-		return nil
+	if logh.InfoEnabled {
+		m.logger.Info().Str("func", funcName).Msg("master node created: " + path)
 	}
 
-	if slave != nil {
-		err = m.zkConnection.Delete(slaveNode, 0)
-		if err != nil {
+	if err := m.removeStaleSlave(funcName, name); err != nil {
+		if !m.config.RequireStaleSlaveRemoval {
 			if logh.ErrorEnabled {
-				m.logger.Error().Str("func", "electForMaster").Err(err).Msgf("error deleting slave node '%s'", slaveNode)
+				m.logger.Error().Str("func", funcName).Err(err).Msg("proceeding as master with a stale slave node")
 			}
 		} else {
-			if logh.InfoEnabled {
-				m.logger.Info().Str("func", "electForMaster").Msg("slave node deleted: " + slaveNode)
+			if logh.ErrorEnabled {
+				m.logger.Error().Str("func", funcName).Err(err).Msg("refusing the master role, releasing the election node")
+			}
+
+			m.withdrawContender(name)
+
+			if delErr := m.delete(m.config.ZKElectionNodeURI, -1); delErr != nil {
+				if logh.ErrorEnabled {
+					m.logger.Error().Str("func", funcName).Err(delErr).Msg("error releasing the election node")
+				}
 			}
+
+			return err
 		}
 	}
 
+	m.setMaster(true)
+	m.setRole(Master)
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", funcName).Msg("this node became the master")
+	}
+
+	m.signal(Master)
+
 	return nil
 }
 
+// Connection - returns the live zookeeper connection (null if not connected)
+// ADVANCED: the connection is shared with the election, do not close it, do not change the election nodes
+// and do not keep it after a reconnection (a new session replaces it); use *zk.Conn type assertion for other operations
+func (m *Manager) Connection() ZKConnection {
+
+	return m.conn()
+}
+
+// ReconnectCount - returns the number of successful reconnections to the zookeeper
+func (m *Manager) ReconnectCount() int {
+
+	return int(atomic.LoadInt64(&m.reconnectCount))
+}
+
+// LastReconnect - returns the time of the last successful reconnection (zero if never reconnected)
+func (m *Manager) LastReconnect() time.Time {
+
+	nanos := atomic.LoadInt64(&m.lastReconnect)
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
+
 // IsMaster - check if the cluster is the master
 func (m *Manager) IsMaster() bool {
-	return m.isMaster
+
+	return atomic.LoadInt32(&m.isMaster) == 1
 }
 
 // GetClusterInfo - return cluster info
 func (m *Manager) GetClusterInfo() (*Cluster, error) {
 
-	if m.zkConnection == nil {
+	return m.GetClusterInfoCtx(context.Background())
+}
+
+// GetClusterInfoCtx - return cluster info, returning the context error if the context is done before the zookeeper calls
+func (m *Manager) GetClusterInfoCtx(ctx context.Context) (*Cluster, error) {
+
+	if ctx.Done() == nil {
+		return m.getClusterInfo()
+	}
+
+	type result struct {
+		cluster *Cluster
+		err     error
+	}
+
+	resultChannel := make(chan result, 1)
+
+	go func() {
+		cluster, err := m.getClusterInfo()
+		resultChannel <- result{cluster: cluster, err: err}
+	}()
+
+	select {
+	case r := <-resultChannel:
+		return r.cluster, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// getClusterInfo - return cluster info
+func (m *Manager) getClusterInfo() (*Cluster, error) {
+
+	if m.config.Standalone && m.IsMaster() {
+		return m.standaloneClusterInfo(), nil
+	}
+
+	if m.conn() == nil {
 		return nil, nil
 	}
 
@@ -546,10 +957,10 @@ func (m *Manager) GetClusterInfo() (*Cluster, error) {
 
 	var children []string
 	if slaveDir != nil {
-		children, _, err = m.zkConnection.Children(m.config.ZKSlaveNodesURI)
+		children, _, err = m.conn().Children(m.config.ZKSlaveNodesURI)
 		if err != nil {
 			if logh.ErrorEnabled {
-				m.logger.Error().Str("func", "GetClusterInfo").Err(err).Msg("error getting slave nodes")
+				m.logger.Error().Str("func", "getClusterInfo").Err(err).Msg("error getting slave nodes")
 			}
 			return nil, err
 		}
@@ -560,7 +971,7 @@ func (m *Manager) GetClusterInfo() (*Cluster, error) {
 	}
 
 	cluster := &Cluster{
-		IsMaster: m.isMaster,
+		IsMaster: m.IsMaster(),
 		Slaves:   children,
 		Nodes:    nodes,
 		NumNodes: len(nodes),
@@ -0,0 +1,283 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the election manager using an in memory zookeeper
+//
+
+// TestReconnectCount - tests the reconnection counter and the last reconnection time
+func TestReconnectCount(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.Equal(t, 0, m.ReconnectCount(), "expected no reconnections")
+	assert.True(t, m.LastReconnect().IsZero(), "expected no last reconnection time")
+
+	var last time.Time
+
+	for i := 1; i <= 3; i++ {
+
+		fake.lastConnection().sendState(zk.StateDisconnected)
+
+		ok := waitFor(2*time.Second, func() bool { return m.ReconnectCount() == i })
+		if !assert.True(t, ok, "expected reconnection number %d", i) {
+			return
+		}
+
+		assert.True(t, m.LastReconnect().After(last), "expected a newer last reconnection time")
+		last = m.LastReconnect()
+	}
+}
+
+// TestAuthFailed - tests if an authentication failure signals the failure without reconnecting
+func TestAuthFailed(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	signals := record(feedback)
+
+	fake.lastConnection().sendState(zk.StateAuthFailed)
+
+	ok := waitFor(5*time.Second, func() bool { return signals.contains(Failed) })
+	if !assert.True(t, ok, "expected the failed signal") {
+		return
+	}
+
+	<-time.After(100 * time.Millisecond)
+
+	assert.Equal(t, 1, fake.numConnections(), "expected no reconnection")
+	assert.Equal(t, 0, m.ReconnectCount(), "expected no reconnection")
+}
+
+// TestCustomACL - tests if the election node and the slave nodes are created using the configured ACLs
+func TestCustomACL(t *testing.T) {
+
+	electionACL := zk.WorldACL(zk.PermRead | zk.PermWrite)
+	slaveACL := zk.WorldACL(zk.PermRead | zk.PermCreate | zk.PermDelete)
+
+	configure := func(c *Config) {
+		c.ElectionACL = electionACL
+		c.SlaveACL = slaveACL
+	}
+
+	fake := newFakeZK()
+	master := fake.newManager(configure, func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(configure, func(c *Config) { c.NodeName = "node-b" })
+
+	for _, m := range []*Manager{master, slave} {
+		feedback, err := m.Start()
+		if !assert.NoError(t, err, "expected no error starting") {
+			return
+		}
+
+		drain(feedback)
+	}
+
+	nodes := map[string][]zk.ACL{
+		"/master":        electionACL,
+		"/slaves":        slaveACL,
+		"/slaves/node-b": slaveACL,
+	}
+
+	for path, acl := range nodes {
+		node, ok := fake.node(path)
+		if assert.True(t, ok, "expected node: %s", path) {
+			assert.Equal(t, acl, node.acl, "expected the configured acl: %s", path)
+		}
+	}
+}
+
+// TestDefaultACL - tests if the nodes are created using the default ACL when no ACL is configured
+func TestDefaultACL(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	for _, path := range []string{"/master", "/slaves"} {
+		node, ok := fake.node(path)
+		if assert.True(t, ok, "expected node: %s", path) {
+			assert.Equal(t, zk.WorldACL(zk.PermAll), node.acl, "expected the default acl: %s", path)
+		}
+	}
+}
+
+// TestGetClusterInfoCtx - tests if the cluster info returns when the context deadline is reached
+func TestGetClusterInfoCtx(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.ClusterChangeCheckTime = "1h" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	cluster, err := m.GetClusterInfoCtx(context.Background())
+	if assert.NoError(t, err, "expected no error getting the cluster info") {
+		assert.Equal(t, 1, cluster.NumNodes, "expected one node")
+	}
+
+	fake.mutex.Lock()
+	fake.delay = time.Second
+	fake.mutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	cluster, err = m.GetClusterInfoCtx(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err, "expected the deadline error")
+	assert.Nil(t, cluster, "expected no cluster info")
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "expected a timely return")
+}
+
+// TestCreateSlaveDirRetry - tests if a transient error creating the slave directory is retried
+func TestCreateSlaveDirRetry(t *testing.T) {
+
+	fake := newFakeZK()
+	fake.failCreate("/slaves", zk.ErrConnectionClosed)
+
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	_, ok := fake.node("/slaves")
+	assert.True(t, ok, "expected the slave directory created")
+}
+
+// TestCreateSlaveDirAlreadyExists - tests if the slave directory created concurrently by another node is tolerated
+func TestCreateSlaveDirAlreadyExists(t *testing.T) {
+
+	fake := newFakeZK()
+	fake.failCreate("/slaves", zk.ErrNodeExists)
+
+	m := fake.newManager()
+
+	err := m.connect()
+	if !assert.NoError(t, err, "expected no error connecting") {
+		return
+	}
+
+	assert.NoError(t, m.createSlaveDir("test"), "expected no error with the existing directory")
+}
+
+// TestCreateSlaveDirRetryLimit - tests if the slave directory creation fails after the maximum attempts
+func TestCreateSlaveDirRetryLimit(t *testing.T) {
+
+	fake := newFakeZK()
+	fake.failCreate("/slaves", zk.ErrConnectionClosed, zk.ErrConnectionClosed, zk.ErrConnectionClosed)
+
+	m := fake.newManager()
+
+	_, err := m.Start()
+	assert.Equal(t, zk.ErrConnectionClosed, err, "expected the transient error after all attempts")
+}
+
+// TestCreateAncestors - tests if the missing ancestors of the election paths are created
+func TestCreateAncestors(t *testing.T) {
+
+	fake := newFakeZK()
+
+	configure := func(c *Config) {
+		c.ZKElectionNodeURI = "/app/election/master"
+		c.ZKSlaveNodesURI = "/app/election/cluster/slaves"
+	}
+
+	m := fake.newManager(configure, func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	for _, path := range []string{"/app", "/app/election", "/app/election/master", "/app/election/cluster", "/app/election/cluster/slaves"} {
+		_, ok := fake.node(path)
+		assert.True(t, ok, "expected the node created: %s", path)
+	}
+
+	slave := fake.newManager(configure, func(c *Config) { c.NodeName = "node-b" })
+
+	feedback, err = slave.Start()
+	if !assert.NoError(t, err, "expected no error starting with the existing ancestors") {
+		return
+	}
+
+	drain(feedback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, slave.WaitForRole(ctx, Slave), "expected the second node as slave")
+}
+
+// TestConnection - tests reading an arbitrary node through the exposed connection
+func TestConnection(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	assert.Nil(t, m.Connection(), "expected no connection before starting")
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	_, err = fake.lastConnection().Create("/config", []byte("some configuration"), int32(0), zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the config node") {
+		return
+	}
+
+	conn := m.Connection()
+	if !assert.NotNil(t, conn, "expected the live connection") {
+		return
+	}
+
+	data, _, err := conn.Get("/config")
+	if !assert.NoError(t, err, "expected no error reading the config node") {
+		return
+	}
+
+	assert.Equal(t, "some configuration", string(data), "expected the node data")
+}
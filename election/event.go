@@ -0,0 +1,30 @@
+package election
+
+import "fmt"
+
+//
+// The feedback channel event names
+//
+
+// Event - a signal sent by the feedback channel (the channel carries ints, use Event(signal) to convert)
+type Event int
+
+// eventNames - the name of each event
+var eventNames = map[Event]string{
+	Master:         "Master",
+	Slave:          "Slave",
+	ClusterChanged: "ClusterChanged",
+	Disconnected:   "Disconnected",
+	Failed:         "Failed",
+	Terminated:     "Terminated",
+}
+
+// String - returns the event name
+func (e Event) String() string {
+
+	if name, ok := eventNames[e]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Event(%d)", int(e))
+}
@@ -0,0 +1,31 @@
+package election
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the event names
+//
+
+// TestEventString - tests the name of each event
+func TestEventString(t *testing.T) {
+
+	expected := map[int]string{
+		Master:         "Master",
+		Slave:          "Slave",
+		ClusterChanged: "ClusterChanged",
+		Disconnected:   "Disconnected",
+		Failed:         "Failed",
+		Terminated:     "Terminated",
+		99:             "Event(99)",
+	}
+
+	for signal, name := range expected {
+		assert.Equal(t, name, Event(signal).String(), "unexpected event name")
+		assert.Equal(t, name, fmt.Sprintf("%v", Event(signal)), "unexpected formatted event")
+	}
+}
@@ -0,0 +1,505 @@
+package election
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+//
+// An in memory zookeeper used by the election tests
+//
+
+// fakeNode - a fake zookeeper node
+type fakeNode struct {
+	data    []byte
+	owner   *fakeConn
+	acl     []zk.ACL
+	version int32
+}
+
+// fakeZK - an in memory zookeeper ensemble
+type fakeZK struct {
+	nodes        map[string]*fakeNode
+	watchers     map[string][]chan zk.Event
+	children     map[string][]chan zk.Event
+	connections  []*fakeConn
+	beforeCreate func(path string)
+	createErrors map[string][]error
+	deleteErrors map[string][]error
+	authError    error
+	watchError   error
+	delay        time.Duration
+	mutex        sync.Mutex
+}
+
+// fakeConn - a fake zookeeper session
+type fakeConn struct {
+	zk     *fakeZK
+	events chan zk.Event
+	state  zk.State
+	auth   []string
+	mutex  sync.Mutex
+}
+
+// newFakeZK - creates a new fake zookeeper with the root node
+func newFakeZK() *fakeZK {
+
+	return &fakeZK{
+		nodes:        map[string]*fakeNode{"/": {}},
+		watchers:     map[string][]chan zk.Event{},
+		children:     map[string][]chan zk.Event{},
+		createErrors: map[string][]error{},
+		deleteErrors: map[string][]error{},
+	}
+}
+
+// connect - the connector creating the fake sessions
+func (f *fakeZK) connect(servers []string, sessionTimeout time.Duration) (ZKConnection, <-chan zk.Event, error) {
+
+	conn := &fakeConn{
+		zk:     f,
+		events: make(chan zk.Event, 100),
+		state:  zk.StateHasSession,
+	}
+
+	f.mutex.Lock()
+	f.connections = append(f.connections, conn)
+	f.mutex.Unlock()
+
+	return conn, conn.events, nil
+}
+
+// newManager - creates a new election manager connected to this fake zookeeper
+// (the configuration functions may change the default test configuration)
+func (f *fakeZK) newManager(configure ...func(*Config)) *Manager {
+
+	config := &Config{
+		ZKURL:                  []string{"fake"},
+		ZKElectionNodeURI:      "/master",
+		ZKSlaveNodesURI:        "/slaves",
+		ReconnectionTimeout:    "10ms",
+		SessionTimeout:         "1s",
+		ClusterChangeCheckTime: "50ms",
+		ClusterChangeWaitTime:  "10ms",
+	}
+
+	for _, c := range configure {
+		c(config)
+	}
+
+	m, err := New(config)
+	if err != nil {
+		panic(err)
+	}
+
+	m.connector = f.connect
+
+	return m
+}
+
+// lastConnection - returns the last created session
+func (f *fakeZK) lastConnection() *fakeConn {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return f.connections[len(f.connections)-1]
+}
+
+// numConnections - returns the number of created sessions
+func (f *fakeZK) numConnections() int {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	return len(f.connections)
+}
+
+// node - returns a node copy
+func (f *fakeZK) node(path string) (fakeNode, bool) {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	node, ok := f.nodes[path]
+	if !ok {
+		return fakeNode{}, false
+	}
+
+	return *node, true
+}
+
+// failCreate - makes the next creations of the path return the errors in order
+func (f *fakeZK) failCreate(path string, errs ...error) {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.createErrors[path] = append(f.createErrors[path], errs...)
+}
+
+// failDelete - makes the next deletions of the path return the errors in order
+func (f *fakeZK) failDelete(path string, errs ...error) {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	f.deleteErrors[path] = append(f.deleteErrors[path], errs...)
+}
+
+// setData - changes the node data firing its watchers
+func (f *fakeZK) setData(path string, data []byte) bool {
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	node, ok := f.nodes[path]
+	if !ok {
+		return false
+	}
+
+	node.data = data
+	node.version++
+	f.fire(path, zk.EventNodeDataChanged)
+
+	return true
+}
+
+// fire - fires the watchers of the path (must be called locked)
+func (f *fakeZK) fire(path string, eventType zk.EventType) {
+
+	for _, w := range f.watchers[path] {
+		w <- zk.Event{Type: eventType, Path: path}
+	}
+
+	delete(f.watchers, path)
+
+	if eventType != zk.EventNodeCreated && eventType != zk.EventNodeDeleted {
+		return
+	}
+
+	for _, w := range f.children[parent(path)] {
+		w <- zk.Event{Type: zk.EventNodeChildrenChanged, Path: parent(path)}
+	}
+
+	delete(f.children, parent(path))
+}
+
+// parent - returns the parent path
+func parent(path string) string {
+
+	i := strings.LastIndex(path, "/")
+	if i <= 0 {
+		return "/"
+	}
+
+	return path[:i]
+}
+
+// Get - returns the node data
+func (c *fakeConn) Get(path string) ([]byte, *zk.Stat, error) {
+
+	c.zk.mutex.Lock()
+	delay := c.zk.delay
+	c.zk.mutex.Unlock()
+
+	<-time.After(delay)
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	node, ok := c.zk.nodes[path]
+	if !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+
+	return node.data, &zk.Stat{Version: node.version}, nil
+}
+
+// GetW - returns the node data and watches it
+func (c *fakeConn) GetW(path string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	node, ok := c.zk.nodes[path]
+	if !ok {
+		return nil, nil, nil, zk.ErrNoNode
+	}
+
+	watcher := make(chan zk.Event, 1)
+	c.zk.watchers[path] = append(c.zk.watchers[path], watcher)
+
+	return node.data, &zk.Stat{Version: node.version}, watcher, nil
+}
+
+// ExistsW - checks if the node exists and watches it
+func (c *fakeConn) ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error) {
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	watcher := make(chan zk.Event, 1)
+	c.zk.watchers[path] = append(c.zk.watchers[path], watcher)
+
+	_, ok := c.zk.nodes[path]
+
+	return ok, &zk.Stat{}, watcher, nil
+}
+
+// ChildrenW - returns the node children and watches them
+func (c *fakeConn) ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	if c.zk.watchError != nil {
+		return nil, nil, nil, c.zk.watchError
+	}
+
+	children, stat, err := c.zk.childrenOf(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	watcher := make(chan zk.Event, 1)
+	c.zk.children[path] = append(c.zk.children[path], watcher)
+
+	return children, stat, watcher, nil
+}
+
+// Create - creates a new node
+func (c *fakeConn) Create(path string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+
+	c.zk.mutex.Lock()
+	hook := c.zk.beforeCreate
+	c.zk.mutex.Unlock()
+
+	if hook != nil {
+		hook(path)
+	}
+
+	if c.State() == zk.StateDisconnected {
+		return "", zk.ErrConnectionClosed
+	}
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	if errs := c.zk.createErrors[path]; len(errs) > 0 {
+		c.zk.createErrors[path] = errs[1:]
+		return "", errs[0]
+	}
+
+	return c.create(path, data, flags, acl)
+}
+
+// create - creates a new node (must be called locked)
+func (c *fakeConn) create(path string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+
+	if _, ok := c.zk.nodes[path]; ok {
+		return "", zk.ErrNodeExists
+	}
+
+	if _, ok := c.zk.nodes[parent(path)]; !ok {
+		return "", zk.ErrNoNode
+	}
+
+	node := &fakeNode{data: data, acl: acl}
+	if flags&zk.FlagEphemeral != 0 {
+		node.owner = c
+	}
+
+	c.zk.nodes[path] = node
+	c.zk.fire(path, zk.EventNodeCreated)
+
+	return path, nil
+}
+
+// Delete - deletes a node
+func (c *fakeConn) Delete(path string, version int32) error {
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	if errs := c.zk.deleteErrors[path]; len(errs) > 0 {
+		c.zk.deleteErrors[path] = errs[1:]
+		return errs[0]
+	}
+
+	node, ok := c.zk.nodes[path]
+	if !ok {
+		return zk.ErrNoNode
+	}
+
+	if version != -1 && version != node.version {
+		return zk.ErrBadVersion
+	}
+
+	delete(c.zk.nodes, path)
+	c.zk.fire(path, zk.EventNodeDeleted)
+
+	return nil
+}
+
+// Children - returns the node children names
+func (c *fakeConn) Children(path string) ([]string, *zk.Stat, error) {
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	return c.zk.childrenOf(path)
+}
+
+// childrenOf - returns the node children (must be called locked)
+func (f *fakeZK) childrenOf(path string) ([]string, *zk.Stat, error) {
+
+	if _, ok := f.nodes[path]; !ok {
+		return nil, nil, zk.ErrNoNode
+	}
+
+	children := []string{}
+	for p := range f.nodes {
+		if p != path && parent(p) == path {
+			children = append(children, p[len(path)+1:])
+		}
+	}
+
+	return children, &zk.Stat{}, nil
+}
+
+// AddAuth - records the session authentication (fails with the fake auth error, if any)
+func (c *fakeConn) AddAuth(scheme string, auth []byte) error {
+
+	c.zk.mutex.Lock()
+	err := c.zk.authError
+	c.zk.mutex.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.auth = append(c.auth, scheme+":"+string(auth))
+
+	return nil
+}
+
+// authentications - returns the recorded session authentications
+func (c *fakeConn) authentications() []string {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.auth...)
+}
+
+// State - returns the session state
+func (c *fakeConn) State() zk.State {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.state
+}
+
+// Close - closes the session removing its ephemeral nodes
+func (c *fakeConn) Close() {
+
+	c.expire(zk.StateDisconnected)
+}
+
+// expire - changes the session state removing its ephemeral nodes
+func (c *fakeConn) expire(state zk.State) {
+
+	c.mutex.Lock()
+	c.state = state
+	c.mutex.Unlock()
+
+	c.zk.mutex.Lock()
+	defer c.zk.mutex.Unlock()
+
+	for path, node := range c.zk.nodes {
+		if node.owner == c {
+			delete(c.zk.nodes, path)
+			c.zk.fire(path, zk.EventNodeDeleted)
+		}
+	}
+}
+
+// sendState - changes the session state and sends the session event
+func (c *fakeConn) sendState(state zk.State) {
+
+	c.mutex.Lock()
+	c.state = state
+	c.mutex.Unlock()
+
+	c.events <- zk.Event{Type: zk.EventSession, State: state}
+}
+
+// drain - consumes the manager feedback channel
+func drain(feedback *chan int) {
+
+	go func() {
+		for range *feedback {
+		}
+	}()
+}
+
+// signalRecorder - records the signals received from the manager feedback channel
+type signalRecorder struct {
+	signals []int
+	mutex   sync.Mutex
+}
+
+// record - consumes the manager feedback channel recording the signals
+func record(feedback *chan int) *signalRecorder {
+
+	r := &signalRecorder{}
+
+	go func() {
+		for signal := range *feedback {
+			r.mutex.Lock()
+			r.signals = append(r.signals, signal)
+			r.mutex.Unlock()
+		}
+	}()
+
+	return r
+}
+
+// contains - checks if the signal was received
+func (r *signalRecorder) contains(signal int) bool {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, s := range r.signals {
+		if s == signal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// waitFor - waits until the condition is true or the timeout is reached
+func waitFor(timeout time.Duration, condition func() bool) bool {
+
+	deadline := time.Now().Add(timeout)
+
+	for time.Now().Before(deadline) {
+		if condition() {
+			return true
+		}
+
+		<-time.After(5 * time.Millisecond)
+	}
+
+	return condition()
+}
@@ -0,0 +1,109 @@
+package election
+
+import (
+	"sync"
+	"time"
+)
+
+//
+// Detects the election churn (frequent master/slave transitions)
+//
+
+const (
+	// numTransitionBuckets - the number of buckets in the sliding window
+	numTransitionBuckets int64 = 10
+
+	// defaultFlappingWindow - the default sliding window
+	defaultFlappingWindow time.Duration = time.Minute
+
+	// defaultFlappingThreshold - the default number of transitions in the window to be considered flapping
+	defaultFlappingThreshold int = 5
+)
+
+// transitionCounter - counts the role transitions in a time bucketed sliding window
+type transitionCounter struct {
+	window     time.Duration
+	bucketSize int64
+	threshold  int
+	counts     [numTransitionBuckets]int
+	epochs     [numTransitionBuckets]int64
+	lastRole   int
+	total      uint64
+	mutex      sync.Mutex
+}
+
+// newTransitionCounter - creates a new transition counter
+func newTransitionCounter(window time.Duration, threshold int) *transitionCounter {
+
+	return &transitionCounter{
+		window:     window,
+		bucketSize: int64(window) / numTransitionBuckets,
+		threshold:  threshold,
+		lastRole:   noRole,
+	}
+}
+
+// observe - observes the reported role counting a transition if it differs from the last one
+func (c *transitionCounter) observe(role int, now time.Time) {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	last := c.lastRole
+	c.lastRole = role
+
+	if last == noRole || last == role {
+		return
+	}
+
+	c.total++
+
+	epoch := now.UnixNano() / c.bucketSize
+	slot := epoch % numTransitionBuckets
+
+	if c.epochs[slot] != epoch {
+		c.epochs[slot] = epoch
+		c.counts[slot] = 0
+	}
+
+	c.counts[slot]++
+}
+
+// count - returns the number of transitions in the window
+func (c *transitionCounter) count(now time.Time) int {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	epoch := now.UnixNano() / c.bucketSize
+	total := 0
+
+	for i := int64(0); i < numTransitionBuckets; i++ {
+		if epoch-c.epochs[i] < numTransitionBuckets {
+			total += c.counts[i]
+		}
+	}
+
+	return total
+}
+
+// totalTransitions - returns the number of transitions since the creation
+func (c *transitionCounter) totalTransitions() uint64 {
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.total
+}
+
+// TransitionRate - returns the number of master/slave transitions per minute in the flapping window
+func (m *Manager) TransitionRate() float64 {
+
+	return float64(m.transitions.count(time.Now())) * float64(time.Minute) / float64(m.transitions.window)
+}
+
+// IsFlapping - checks if the number of master/slave transitions in the flapping window reached the threshold
+func (m *Manager) IsFlapping() bool {
+
+	return m.transitions.count(time.Now()) >= m.transitions.threshold
+}
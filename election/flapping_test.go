@@ -0,0 +1,119 @@
+package election
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the election churn detection
+//
+
+// newFlappingManager - creates a manager with the specified flapping configuration
+func newFlappingManager(t *testing.T, window string, threshold int) *Manager {
+
+	m, err := New(&Config{
+		ReconnectionTimeout:    "10ms",
+		SessionTimeout:         "1s",
+		ClusterChangeCheckTime: "50ms",
+		ClusterChangeWaitTime:  "10ms",
+		FlappingWindow:         window,
+		FlappingThreshold:      threshold,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return m
+}
+
+// TestFlapping - tests if rapid master/slave transitions are reported as flapping
+func TestFlapping(t *testing.T) {
+
+	m := newFlappingManager(t, "300ms", 3)
+
+	assert.False(t, m.IsFlapping(), "expected no flapping before any election")
+
+	m.setRole(Master)
+	m.setRole(Master)
+	m.setRole(Slave)
+	m.setRole(Master)
+
+	assert.False(t, m.IsFlapping(), "expected no flapping below the threshold")
+
+	m.setRole(noRole)
+	m.setRole(Slave)
+
+	assert.True(t, m.IsFlapping(), "expected flapping")
+	assert.InDelta(t, 600.0, m.TransitionRate(), 0.001, "expected 3 transitions in 300ms")
+	assert.Equal(t, 0, m.transitions.count(time.Now().Add(400*time.Millisecond)), "expected no transitions after the window")
+}
+
+// TestFlappingWindow - tests if the transitions leave the sliding window bucket by bucket
+func TestFlappingWindow(t *testing.T) {
+
+	counter := newTransitionCounter(time.Second, 2)
+	start := time.Unix(0, 0)
+
+	counter.observe(Master, start)
+	counter.observe(Slave, start)
+	counter.observe(Master, start.Add(500*time.Millisecond))
+
+	assert.Equal(t, 2, counter.count(start.Add(500*time.Millisecond)), "expected the transitions in the window")
+	assert.Equal(t, 2, counter.count(start.Add(999*time.Millisecond)), "expected the transitions in the window")
+	assert.Equal(t, 1, counter.count(start.Add(time.Second)), "expected the first bucket out of the window")
+	assert.Equal(t, 0, counter.count(start.Add(1500*time.Millisecond)), "expected no transitions after the window")
+	assert.Equal(t, uint64(2), counter.totalTransitions(), "expected the total transitions")
+}
+
+// TestConcurrentTransitions - tests if the transitions are counted while the role changes concurrently with the readers
+func TestConcurrentTransitions(t *testing.T) {
+
+	m := newFlappingManager(t, "1m", 1000)
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func(role int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				m.setRole(role)
+			}
+		}([]int{Master, Slave}[i%2])
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				m.IsFlapping()
+				m.TransitionRate()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, int(m.transitions.totalTransitions()), m.transitions.count(time.Now()), "expected every transition in the window")
+}
+
+// TestInvalidFlappingConfiguration - tests the flapping configuration validation
+func TestInvalidFlappingConfiguration(t *testing.T) {
+
+	configs := []*Config{
+		{FlappingWindow: "invalid"},
+		{FlappingWindow: "1ns"},
+		{FlappingThreshold: -1},
+	}
+
+	for _, c := range configs {
+		c.ReconnectionTimeout = "1s"
+		c.SessionTimeout = "1s"
+		c.ClusterChangeCheckTime = "1s"
+		c.ClusterChangeWaitTime = "1s"
+
+		_, err := New(c)
+		assert.Error(t, err, "expected a configuration error")
+	}
+}
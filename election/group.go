@@ -0,0 +1,148 @@
+package election
+
+import (
+	"fmt"
+
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The named election groups sharing the manager connection
+//
+
+// newGroups - creates the managers of the configured election groups
+func newGroups(config *Config) (map[string]*Manager, error) {
+
+	if len(config.Groups) == 0 {
+		return nil, nil
+	}
+
+	groups := make(map[string]*Manager, len(config.Groups))
+	electionNodes := map[string]string{config.ZKElectionNodeURI: ""}
+
+	for name, groupConfig := range config.Groups {
+
+		if len(name) == 0 {
+			return nil, fmt.Errorf("election group name is required")
+		}
+
+		if len(groupConfig.ZKElectionNodeURI) == 0 || len(groupConfig.ZKSlaveNodesURI) == 0 {
+			return nil, fmt.Errorf("election group \"%s\" requires the election and slave nodes", name)
+		}
+
+		if _, exists := electionNodes[groupConfig.ZKElectionNodeURI]; exists {
+			return nil, fmt.Errorf("election group \"%s\" election node is already in use: %s", name, groupConfig.ZKElectionNodeURI)
+		}
+
+		electionNodes[groupConfig.ZKElectionNodeURI] = name
+
+		copied := *config
+		copied.ZKElectionNodeURI = groupConfig.ZKElectionNodeURI
+		copied.ZKSlaveNodesURI = groupConfig.ZKSlaveNodesURI
+		copied.Groups = nil
+
+		group, err := New(&copied)
+		if err != nil {
+			return nil, err
+		}
+
+		group.logger = logh.CreateContextualLogger("pkg", "election", "group", name)
+		groups[name] = group
+	}
+
+	return groups, nil
+}
+
+// startGroups - starts the election of each group using this manager connection
+func (m *Manager) startGroups() error {
+
+	for name, group := range m.groups {
+
+		group.setTerminating(false)
+		group.setContext(m.getContext())
+		group.setConn(m.conn())
+
+		if err := group.startElection(); err != nil {
+			return fmt.Errorf("error starting the election group \"%s\": %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// startGroupsStandalone - declares this node as master of each group
+func (m *Manager) startGroupsStandalone() error {
+
+	for _, group := range m.groups {
+
+		group.setTerminating(false)
+
+		if _, err := group.startStandalone(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// terminateGroups - ends the event loops of the groups (the connection is closed by this manager)
+func (m *Manager) terminateGroups() {
+
+	for _, group := range m.groups {
+		group.stopOperations()
+		group.setRole(noRole)
+	}
+}
+
+// notifyGroups - sends the signal to the feedback channel of each group
+func (m *Manager) notifyGroups(signal int) {
+
+	for _, group := range m.groups {
+		group.signal(signal)
+	}
+}
+
+// group - returns the manager of the election group
+func (m *Manager) group(name string) (*Manager, error) {
+
+	group, ok := m.groups[name]
+	if !ok {
+		return nil, fmt.Errorf("election group not found: %s", name)
+	}
+
+	return group, nil
+}
+
+// GroupFeedback - returns the feedback channel of the election group, it receives the same signals of the
+// channel returned by Start and must be consumed too
+func (m *Manager) GroupFeedback(name string) (*chan int, error) {
+
+	group, err := m.group(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group.feedbackChannel, nil
+}
+
+// IsGroupMaster - checks if this node is the master of the election group
+func (m *Manager) IsGroupMaster(name string) bool {
+
+	group, err := m.group(name)
+	if err != nil {
+		return false
+	}
+
+	return group.IsMaster()
+}
+
+// GetGroupClusterInfo - returns the cluster info of the election group
+func (m *Manager) GetGroupClusterInfo(name string) (*Cluster, error) {
+
+	group, err := m.group(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return group.GetClusterInfo()
+}
@@ -0,0 +1,123 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the named election groups
+//
+
+// groupsConfig - configures the compactor and scheduler election groups
+func groupsConfig(c *Config) {
+
+	c.NodeName = "node-a"
+	c.Groups = map[string]GroupConfig{
+		"compactor": {ZKElectionNodeURI: "/compactor/master", ZKSlaveNodesURI: "/compactor/slaves"},
+		"scheduler": {ZKElectionNodeURI: "/scheduler/master", ZKSlaveNodesURI: "/scheduler/slaves"},
+	}
+}
+
+// TestElectionGroups - tests if the same node is master of a group and slave of another
+func TestElectionGroups(t *testing.T) {
+
+	fake := newFakeZK()
+
+	holder, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	for _, path := range []string{"/scheduler", "/scheduler/master"} {
+		_, err = holder.Create(path, []byte("node-b"), 0, zk.WorldACL(zk.PermAll))
+		if !assert.NoError(t, err, "expected no error creating node: %s", path) {
+			return
+		}
+	}
+
+	m := fake.newManager(groupsConfig)
+
+	compactor, err := m.GroupFeedback("compactor")
+	if !assert.NoError(t, err, "expected the compactor feedback") {
+		return
+	}
+
+	scheduler, err := m.GroupFeedback("scheduler")
+	if !assert.NoError(t, err, "expected the scheduler feedback") {
+		return
+	}
+
+	compactorSignals := record(compactor)
+	schedulerSignals := record(scheduler)
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.True(t, m.IsMaster(), "expected this node as master of the default election")
+	assert.True(t, m.IsGroupMaster("compactor"), "expected this node as compactor master")
+	assert.False(t, m.IsGroupMaster("scheduler"), "expected this node as scheduler slave")
+
+	ok := waitFor(time.Second, func() bool { return compactorSignals.contains(Master) && schedulerSignals.contains(Slave) })
+	assert.True(t, ok, "expected the role signals in each group feedback")
+
+	cluster, err := m.GetGroupClusterInfo("scheduler")
+	if assert.NoError(t, err, "expected no error getting the scheduler cluster") {
+		assert.Equal(t, "node-b", cluster.Master, "expected the other node as scheduler master")
+		assert.Equal(t, []string{"node-a"}, cluster.Slaves, "expected this node as scheduler slave")
+	}
+
+	cluster, err = m.GetGroupClusterInfo("compactor")
+	if assert.NoError(t, err, "expected no error getting the compactor cluster") {
+		assert.Equal(t, "node-a", cluster.Master, "expected this node as compactor master")
+		assert.Empty(t, cluster.Slaves, "expected no compactor slaves")
+	}
+}
+
+// TestElectionGroupsStandalone - tests if the standalone node is master of all groups
+func TestElectionGroupsStandalone(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(groupsConfig, func(c *Config) { c.Standalone = true })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.True(t, m.IsGroupMaster("compactor"), "expected this node as compactor master")
+	assert.True(t, m.IsGroupMaster("scheduler"), "expected this node as scheduler master")
+}
+
+// TestInvalidElectionGroups - tests the election group validation and the unknown group lookup
+func TestInvalidElectionGroups(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(groupsConfig)
+
+	_, err := m.GroupFeedback("unknown")
+	assert.Error(t, err, "expected an error with an unknown group")
+	assert.False(t, m.IsGroupMaster("unknown"), "expected no master of an unknown group")
+
+	config := &Config{
+		ZKElectionNodeURI: "/master",
+		Groups:            map[string]GroupConfig{"x": {ZKElectionNodeURI: "/master", ZKSlaveNodesURI: "/x/slaves"}},
+	}
+
+	_, err = newGroups(config)
+	assert.Error(t, err, "expected an error reusing the election node")
+
+	config.Groups = map[string]GroupConfig{"x": {ZKElectionNodeURI: "/x/master"}}
+
+	_, err = newGroups(config)
+	assert.Error(t, err, "expected an error without the slave nodes")
+}
@@ -0,0 +1,102 @@
+package election
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+//
+// Tracks the zookeeper write operations to let them finish before disconnecting
+//
+
+const (
+	// inFlightWaitTimeout - the max time waiting for the in flight operations when disconnecting
+	inFlightWaitTimeout time.Duration = 2 * time.Second
+
+	// inFlightCheckInterval - the interval checking if the in flight operations have finished
+	inFlightCheckInterval time.Duration = 10 * time.Millisecond
+)
+
+// beginOperation - tracks a new operation, refusing it if the manager is disconnecting
+// (checked under the lock taken by stopOperations, so Disconnect never misses a concurrently started operation)
+func (m *Manager) beginOperation() error {
+
+	m.operationsMutex.Lock()
+	defer m.operationsMutex.Unlock()
+
+	if m.terminating() {
+		return zk.ErrClosing
+	}
+
+	atomic.AddInt32(&m.inFlightOperations, 1)
+
+	return nil
+}
+
+// endOperation - ends the tracking of a finished operation
+func (m *Manager) endOperation() {
+
+	atomic.AddInt32(&m.inFlightOperations, -1)
+}
+
+// stopOperations - sets the manager as disconnecting, no new operation starts after it returns
+func (m *Manager) stopOperations() {
+
+	m.operationsMutex.Lock()
+	defer m.operationsMutex.Unlock()
+
+	m.setTerminating(true)
+}
+
+// create - creates a node tracking the operation
+func (m *Manager) create(path string, data []byte, flags int32, acl []zk.ACL) (string, error) {
+
+	if err := m.beginOperation(); err != nil {
+		return "", err
+	}
+
+	defer m.endOperation()
+
+	return m.conn().Create(path, data, flags, acl)
+}
+
+// delete - deletes a node tracking the operation
+func (m *Manager) delete(path string, version int32) error {
+
+	if err := m.beginOperation(); err != nil {
+		return err
+	}
+
+	defer m.endOperation()
+
+	return m.conn().Delete(path, version)
+}
+
+// pendingOperations - returns the number of in flight operations of this manager and its election groups
+func (m *Manager) pendingOperations() int32 {
+
+	pending := atomic.LoadInt32(&m.inFlightOperations)
+	for _, g := range m.groups {
+		pending += atomic.LoadInt32(&g.inFlightOperations)
+	}
+
+	return pending
+}
+
+// waitOperations - waits for the in flight operations to finish (returns false if the timeout is reached)
+func (m *Manager) waitOperations(timeout time.Duration) bool {
+
+	deadline := time.Now().Add(timeout)
+
+	for m.pendingOperations() > 0 {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		<-time.After(inFlightCheckInterval)
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+package election
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the wait for the in flight operations when disconnecting
+//
+
+// TestDisconnectWaitsInFlight - tests if the disconnection waits for a slow operation before closing the connection
+func TestDisconnectWaitsInFlight(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	inFlight := make(chan struct{})
+	once := sync.Once{}
+
+	fake.mutex.Lock()
+	fake.beforeCreate = func(path string) {
+		if path == "/slaves/operation" {
+			once.Do(func() { close(inFlight) })
+			<-time.After(200 * time.Millisecond)
+		}
+	}
+	fake.mutex.Unlock()
+
+	errChan := make(chan error, 1)
+
+	go func() {
+		_, err := m.create("/slaves/operation", nil, int32(0), m.slaveACL)
+		errChan <- err
+	}()
+
+	<-inFlight
+
+	m.Disconnect()
+
+	assert.NoError(t, <-errChan, "expected the in flight creation to finish before the disconnection")
+}
+
+// TestOperationsDuringDisconnect - tests if every operation either finishes before the connection is closed
+// or is refused when started during the disconnection
+func TestOperationsDuringDisconnect(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	errs := make(chan error, 100)
+	wg := sync.WaitGroup{}
+	wg.Add(cap(errs))
+
+	for i := 0; i < cap(errs); i++ {
+		path := fmt.Sprintf("/slaves/operation-%d", i)
+
+		go func() {
+			defer wg.Done()
+			_, err := m.create(path, nil, int32(0), m.slaveACL)
+			errs <- err
+		}()
+	}
+
+	m.Disconnect()
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			assert.Equal(t, zk.ErrClosing, err, "expected the operation refused instead of failing on the closed connection")
+		}
+	}
+
+	_, err = m.create("/slaves/operation", nil, int32(0), m.slaveACL)
+	assert.Equal(t, zk.ErrClosing, err, "expected the operation refused after the disconnection")
+}
+
+// TestWaitOperationsTimeout - tests if the wait for the in flight operations is bounded by the timeout
+func TestWaitOperationsTimeout(t *testing.T) {
+
+	m := newFakeZK().newManager()
+
+	assert.True(t, m.waitOperations(time.Second), "expected no operations to wait")
+
+	m.inFlightOperations = 1
+
+	start := time.Now()
+	assert.False(t, m.waitOperations(50*time.Millisecond), "expected the timeout with an operation in flight")
+	assert.True(t, time.Since(start) < time.Second, "expected the wait bounded by the timeout")
+}
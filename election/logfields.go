@@ -0,0 +1,29 @@
+package election
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/uol/gobol/util"
+)
+
+//
+// Adds the election state fields to the log events
+//
+
+// withState - adds the node name, the master flag and the number of known cluster nodes to the log event
+// (used in the election state transitions, so the logs can be queried by node and role)
+func (m *Manager) withState(event *zerolog.Event) *zerolog.Event {
+
+	if event == nil {
+		return nil
+	}
+
+	name, err := m.getNodeName()
+	if err != nil {
+		name = ""
+	}
+
+	return event.
+		Str("node", name).
+		Bool("isMaster", m.IsMaster()).
+		Int("numNodes", util.GetSyncMapSize(&m.clusterNodes))
+}
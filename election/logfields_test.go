@@ -0,0 +1,160 @@
+package election
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Tests the election state fields added to the log events
+//
+
+// logBuffer - a buffer observing the json log events written by many goroutines (discarded if not observing)
+type logBuffer struct {
+	buffer    bytes.Buffer
+	observing bool
+	mutex     sync.Mutex
+}
+
+// testLogs - receives the log events of all tests
+var testLogs = &logBuffer{}
+
+// TestMain - configures the log output once, before any manager is logging (the logh globals are not synchronized)
+func TestMain(m *testing.M) {
+
+	logh.ConfigureGlobalLoggerOutput(logh.INFO, testLogs, testLogs)
+
+	os.Exit(m.Run())
+}
+
+// Write - writes the log event
+func (b *logBuffer) Write(p []byte) (int, error) {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if !b.observing {
+		return len(p), nil
+	}
+
+	return b.buffer.Write(p)
+}
+
+// find - returns the first log event of the node with the message (null if not found)
+func (b *logBuffer) find(node, msg string) map[string]interface{} {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	scanner := bufio.NewScanner(bytes.NewReader(b.buffer.Bytes()))
+	for scanner.Scan() {
+
+		event := map[string]interface{}{}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+
+		if event["message"] == msg && event["node"] == node {
+			return event
+		}
+	}
+
+	return nil
+}
+
+// observeLogs - keeps the info log events in the returned buffer
+func observeLogs() *logBuffer {
+
+	testLogs.mutex.Lock()
+	defer testLogs.mutex.Unlock()
+
+	testLogs.buffer.Reset()
+	testLogs.observing = true
+
+	return testLogs
+}
+
+// silenceLogs - discards the log events
+func silenceLogs() {
+
+	testLogs.mutex.Lock()
+	defer testLogs.mutex.Unlock()
+
+	testLogs.buffer.Reset()
+	testLogs.observing = false
+}
+
+// TestMasterLogFields - tests the state fields of the master transition log event
+func TestMasterLogFields(t *testing.T) {
+
+	logs := observeLogs()
+	defer silenceLogs()
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+	defer m.Disconnect()
+
+	event := logs.find("node-a", "this node became the master")
+	if !assert.NotNil(t, event, "expected the master transition logged") {
+		return
+	}
+
+	assert.Equal(t, "node-a", event["node"], "expected the node name field")
+	assert.Equal(t, true, event["isMaster"], "expected the master flag field")
+	assert.Contains(t, event, "numNodes", "expected the number of nodes field")
+	assert.Equal(t, "electForMaster", event["func"], "expected the function field")
+}
+
+// TestSlaveLogFields - tests the state fields of the slave transition log event
+func TestSlaveLogFields(t *testing.T) {
+
+	logs := observeLogs()
+	defer silenceLogs()
+
+	fake := newFakeZK()
+
+	holder, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	_, err = holder.Create("/master", []byte("node-b"), 0, zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the election node") {
+		return
+	}
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-c" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+	defer m.Disconnect()
+
+	ok := waitFor(time.Second, func() bool { return logs.find("node-c", "this node became a slave") != nil })
+	if !assert.True(t, ok, "expected the slave transition logged") {
+		return
+	}
+
+	event := logs.find("node-c", "this node became a slave")
+	assert.Equal(t, false, event["isMaster"], "expected the master flag field")
+	assert.Contains(t, event, "numNodes", "expected the number of nodes field")
+}
@@ -0,0 +1,76 @@
+package election
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The master node data change notifications
+//
+
+// OnMasterDataChange - sets a callback invoked with the new data every time the election node data changes
+// (call it before starting), the data read when starting is not notified
+func (m *Manager) OnMasterDataChange(callback func(data []byte)) {
+
+	m.masterDataCallback = callback
+}
+
+// watchMasterData - watches the election node data until the election ends or the connection is replaced
+// by a reconnection
+func (m *Manager) watchMasterData() {
+
+	conn := m.conn()
+
+	m.goLoop(func() {
+
+		var last []byte
+		first := true
+
+		for !m.terminating() && m.conn() == conn {
+
+			data, _, events, err := conn.GetW(m.config.ZKElectionNodeURI)
+			if err == zk.ErrNoNode {
+				var exists bool
+				exists, _, events, err = conn.ExistsW(m.config.ZKElectionNodeURI)
+				if err == nil && exists {
+					continue
+				}
+			} else if err == nil {
+				if !first && !bytes.Equal(data, last) {
+					m.masterDataCallback(data)
+				}
+
+				first = false
+				last = data
+			}
+
+			if err != nil {
+				if logh.ErrorEnabled {
+					m.logger.Error().Str("func", "watchMasterData").Err(err).Msg("error watching the election node data")
+				}
+
+				select {
+				case <-time.After(m.reconnectionTimeoutDuration):
+				case <-m.getContext().Done():
+				}
+			} else {
+				select {
+				case <-events:
+				case <-m.getContext().Done():
+				}
+			}
+
+			if m.cancelled("watchMasterData") {
+				return
+			}
+		}
+
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "watchMasterData").Msg("ending master data watch loop")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package election
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the master data change notifications
+//
+
+// TestOnMasterDataChange - tests if the callback receives the new master data
+func TestOnMasterDataChange(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	changes := make(chan []byte, 10)
+	m.OnMasterDataChange(func(data []byte) {
+		changes <- data
+	})
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	select {
+	case data := <-changes:
+		assert.Fail(t, "expected no notification of the initial data", "received: %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	for _, address := range []string{"10.0.0.1:8080", "10.0.0.2:8080"} {
+
+		if !assert.True(t, fake.setData("/master", []byte(address)), "expected the master node") {
+			return
+		}
+
+		select {
+		case data := <-changes:
+			assert.Equal(t, address, string(data), "expected the new master data")
+		case <-time.After(2 * time.Second):
+			assert.Fail(t, "expected the master data change notification")
+			return
+		}
+	}
+
+	assert.True(t, m.IsMaster(), "expected the same master")
+}
+
+// TestMasterDataAfterRestart - tests if the callback is invoked once per change after the election restarts
+// (the watch loop of the previous connection must end)
+func TestMasterDataAfterRestart(t *testing.T) {
+
+	fake := newFakeZK()
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	var changes int32
+	slave.OnMasterDataChange(func(data []byte) {
+		atomic.AddInt32(&changes, 1)
+	})
+
+	for _, m := range []*Manager{master, slave} {
+		feedback, err := m.Start()
+		if !assert.NoError(t, err, "expected no error starting") {
+			return
+		}
+
+		defer m.Disconnect()
+
+		drain(feedback)
+	}
+
+	<-time.After(100 * time.Millisecond)
+
+	slave.Disconnect()
+
+	if _, err := slave.Start(); !assert.NoError(t, err, "expected no error restarting") {
+		return
+	}
+
+	<-time.After(100 * time.Millisecond)
+
+	if !assert.True(t, fake.setData("/master", []byte("10.0.0.1:8080")), "expected the master node") {
+		return
+	}
+
+	<-time.After(200 * time.Millisecond)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&changes), "expected a single notification")
+}
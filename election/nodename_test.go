@@ -0,0 +1,59 @@
+package election
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the configured node identity
+//
+
+// TestNodeName - tests if many instances sharing the same host participate in the election using their node names
+func TestNodeName(t *testing.T) {
+
+	fake := newFakeZK()
+
+	for _, name := range []string{"instance-1", "instance-2"} {
+		nodeName := name
+		feedback, err := fake.newManager(func(c *Config) { c.NodeName = nodeName }).Start()
+		if !assert.NoError(t, err, "expected no error starting: %s", name) {
+			return
+		}
+
+		drain(feedback)
+	}
+
+	master, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node") {
+		assert.Equal(t, "instance-1", string(master.data), "expected the first instance as master")
+	}
+
+	_, ok = fake.node("/slaves/instance-2")
+	assert.True(t, ok, "expected the second instance as slave")
+}
+
+// TestNodeNameHostname - tests if the hostname identifies the node when no node name is configured
+func TestNodeNameHostname(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	hostname, err := m.GetHostname()
+	if !assert.NoError(t, err, "expected no error getting the hostname") {
+		return
+	}
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	master, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node") {
+		assert.Equal(t, hostname, string(master.data), "expected the hostname as master")
+	}
+}
@@ -0,0 +1,140 @@
+package election
+
+import (
+	"sort"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Watches the slave nodes to detect the cluster changes
+//
+
+// watchNodeEvents - watches the slave nodes, re-arming the watch and checking the cluster on each event
+// (the loop ends when the election ends or the connection is replaced by a reconnection)
+func (m *Manager) watchNodeEvents() error {
+
+	conn := m.conn()
+
+	_, _, events, err := conn.ChildrenW(m.config.ZKSlaveNodesURI)
+	if err != nil {
+		return err
+	}
+
+	m.goLoop(func() {
+		for {
+
+			if m.conn() != conn {
+				return
+			}
+
+			if m.terminating() {
+				if logh.InfoEnabled {
+					m.logger.Info().Str("func", "watchNodeEvents").Msg("ending node watch loop")
+				}
+				m.signal(Disconnected)
+				return
+			}
+
+			select {
+			case <-events:
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("watchNodeEvents") {
+				return
+			}
+
+			events = m.armNodeWatch(conn)
+			if events == nil {
+				continue
+			}
+
+			cluster, err := m.GetClusterInfo()
+			if err != nil {
+				if logh.ErrorEnabled {
+					m.logger.Error().Str("func", "watchNodeEvents").Err(err).Send()
+				}
+				continue
+			}
+
+			m.notifyClusterChange("watchNodeEvents", cluster)
+		}
+	})
+
+	return nil
+}
+
+// armNodeWatch - watches the slave nodes again, retrying until it succeeds or the election ends (returns null if it ends)
+func (m *Manager) armNodeWatch(conn ZKConnection) <-chan zk.Event {
+
+	for !m.terminating() && m.conn() == conn && m.getContext().Err() == nil {
+
+		_, _, events, err := conn.ChildrenW(m.config.ZKSlaveNodesURI)
+		if err == nil {
+			return events
+		}
+
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "armNodeWatch").Err(err).Msg("error watching the slave nodes")
+		}
+
+		select {
+		case <-time.After(m.reconnectionTimeoutDuration):
+		case <-m.getContext().Done():
+		}
+	}
+
+	return nil
+}
+
+// notifyClusterChange - stores the cluster nodes and, if they changed, signals the cluster change
+// with the added and removed nodes (returns true if changed), the stored nodes are compared and replaced
+// under a lock, so each change is signaled once even if the polling and the watch loops detect it together
+func (m *Manager) notifyClusterChange(funcName string, cluster *Cluster) bool {
+
+	m.clusterNodesMutex.Lock()
+	defer m.clusterNodesMutex.Unlock()
+
+	current := make(map[string]struct{}, len(cluster.Nodes))
+	added := []string{}
+	removed := []string{}
+
+	for _, node := range cluster.Nodes {
+		current[node] = struct{}{}
+		if _, ok := m.clusterNodes.Load(node); !ok {
+			added = append(added, node)
+		}
+	}
+
+	m.clusterNodes.Range(func(k, _ interface{}) bool {
+		if _, ok := current[k.(string)]; !ok {
+			removed = append(removed, k.(string))
+			m.clusterNodes.Delete(k)
+		}
+		return true
+	})
+
+	if len(added) == 0 && len(removed) == 0 {
+		return false
+	}
+
+	for _, node := range added {
+		m.clusterNodes.Store(node, true)
+	}
+
+	sort.Strings(added)
+	sort.Strings(removed)
+
+	cluster.ClusterChange = ClusterChange{Added: added, Removed: removed}
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", funcName).Strs("added", added).Strs("removed", removed).Msg("cluster node configuration changed")
+	}
+
+	m.signalClusterChanged(cluster)
+
+	return true
+}
@@ -0,0 +1,242 @@
+package election
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the cluster changes detected watching the slave nodes
+//
+
+// startClusterWatcher - starts the manager delivering the cluster changes to the returned channel
+func startClusterWatcher(t *testing.T, m *Manager) <-chan *Cluster {
+
+	clusters := make(chan *Cluster, 10)
+	m.OnClusterChanged(func(cluster *Cluster) { clusters <- cluster })
+
+	_, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return nil
+	}
+
+	return clusters
+}
+
+// nextCluster - returns the next cluster change (null on timeout)
+func nextCluster(clusters <-chan *Cluster, timeout time.Duration) *Cluster {
+
+	select {
+	case cluster := <-clusters:
+		return cluster
+	case <-time.After(timeout):
+		return nil
+	}
+}
+
+// TestNodeWatch - tests if the added and removed nodes are delivered as soon as the slave nodes change
+func TestNodeWatch(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.NodeName = "node-a"
+		c.ClusterChangeCheckTime = "1h"
+	})
+
+	clusters := startClusterWatcher(t, m)
+	if clusters == nil {
+		return
+	}
+
+	defer m.Disconnect()
+
+	other, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	for _, name := range []string{"node-b", "node-c"} {
+		_, err = other.Create("/slaves/"+name, []byte(name), int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+		if !assert.NoError(t, err, "expected no error creating node: %s", name) {
+			return
+		}
+
+		cluster := nextCluster(clusters, time.Second)
+		if assert.NotNil(t, cluster, "expected the cluster change adding node: %s", name) {
+			assert.Equal(t, []string{name}, cluster.Added, "expected the added node")
+			assert.Empty(t, cluster.Removed, "expected no removed nodes")
+		}
+	}
+
+	if !assert.NoError(t, other.Delete("/slaves/node-b", -1), "expected no error deleting the node") {
+		return
+	}
+
+	cluster := nextCluster(clusters, time.Second)
+	if assert.NotNil(t, cluster, "expected the cluster change removing the node") {
+		assert.Empty(t, cluster.Added, "expected no added nodes")
+		assert.Equal(t, []string{"node-b"}, cluster.Removed, "expected the removed node")
+		assert.Equal(t, 2, cluster.NumNodes, "expected the cluster size")
+	}
+}
+
+// TestNodePollingDiff - tests if the polled cluster changes also have the added and removed nodes
+func TestNodePollingDiff(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.NodeName = "node-a"
+		c.UsePollingForNodeEvents = true
+	})
+
+	clusters := startClusterWatcher(t, m)
+	if clusters == nil {
+		return
+	}
+
+	defer m.Disconnect()
+
+	other, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	_, err = other.Create("/slaves/node-b", []byte("node-b"), int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the node") {
+		return
+	}
+
+	cluster := nextCluster(clusters, time.Second)
+	if assert.NotNil(t, cluster, "expected the polled cluster change") {
+		assert.Equal(t, []string{"node-b"}, cluster.Added, "expected the added node")
+		assert.Empty(t, cluster.Removed, "expected no removed nodes")
+	}
+}
+
+// TestNodePollingFallback - tests if the cluster is polled when the slave nodes watch can not be set
+func TestNodePollingFallback(t *testing.T) {
+
+	fake := newFakeZK()
+	fake.watchError = zk.ErrNoServer
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	clusters := startClusterWatcher(t, m)
+	if clusters == nil {
+		return
+	}
+
+	defer m.Disconnect()
+
+	other, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the other node session") {
+		return
+	}
+
+	_, err = other.Create("/slaves/node-b", []byte("node-b"), int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the node") {
+		return
+	}
+
+	cluster := nextCluster(clusters, time.Second)
+	if assert.NotNil(t, cluster, "expected the polled cluster change") {
+		assert.Equal(t, []string{"node-b"}, cluster.Added, "expected the added node")
+	}
+}
+
+// TestClusterChangeRemoved - tests if only the nodes that left are delivered as removed
+func TestClusterChangeRemoved(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) {
+		c.NodeName = "node-a"
+	})
+
+	clusters := startClusterWatcher(t, m)
+	if clusters == nil {
+		return
+	}
+
+	defer m.Disconnect()
+
+	others := map[string]ZKConnection{}
+	for _, name := range []string{"node-b", "node-c", "node-d"} {
+		conn, _, err := fake.connect(nil, 0)
+		if !assert.NoError(t, err, "expected no error connecting: %s", name) {
+			return
+		}
+
+		_, err = conn.Create("/slaves/"+name, []byte(name), int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+		if !assert.NoError(t, err, "expected no error creating node: %s", name) {
+			return
+		}
+
+		if !assert.NotNil(t, nextCluster(clusters, time.Second), "expected the cluster change adding node: %s", name) {
+			return
+		}
+
+		others[name] = conn
+	}
+
+	others["node-b"].Close()
+
+	cluster := nextCluster(clusters, time.Second)
+	if assert.NotNil(t, cluster, "expected the cluster change") {
+		assert.Equal(t, ClusterChange{Added: []string{}, Removed: []string{"node-b"}}, cluster.ClusterChange, "expected only the node that left")
+		assert.Equal(t, []string{"node-c", "node-d"}, sortedSlaves(cluster), "expected the remaining slaves")
+	}
+}
+
+// TestConcurrentClusterChange - tests if a change detected by two loops at the same time is signaled once
+func TestConcurrentClusterChange(t *testing.T) {
+
+	m := newFakeZK().newManager()
+
+	var changes int32
+	m.OnClusterChanged(func(cluster *Cluster) { atomic.AddInt32(&changes, 1) })
+
+	const numChanges int = 50
+
+	for i := 0; i < numChanges; i++ {
+
+		nodes := []string{}
+		for j := 0; j < 20; j++ {
+			nodes = append(nodes, fmt.Sprintf("node-%d-%d", i, j))
+		}
+
+		wg := sync.WaitGroup{}
+		wg.Add(4)
+
+		for j := 0; j < 4; j++ {
+			go func() {
+				defer wg.Done()
+				m.notifyClusterChange("TestConcurrentClusterChange", &Cluster{Nodes: nodes, NumNodes: len(nodes)})
+			}()
+		}
+
+		wg.Wait()
+	}
+
+	ok := waitFor(time.Second, func() bool { return atomic.LoadInt32(&changes) >= int32(numChanges) })
+	assert.True(t, ok, "expected every change signaled")
+
+	<-time.After(50 * time.Millisecond)
+
+	assert.Equal(t, int32(numChanges), atomic.LoadInt32(&changes), "expected each change signaled once")
+}
+
+// sortedSlaves - returns the sorted cluster slaves
+func sortedSlaves(cluster *Cluster) []string {
+
+	slaves := append([]string{}, cluster.Slaves...)
+	sort.Strings(slaves)
+
+	return slaves
+}
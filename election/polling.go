@@ -0,0 +1,50 @@
+package election
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+//
+// The jittered cluster change polling interval
+//
+
+// parsePollingConfig - parses the cluster check jitter and max time (the max time defaults to no limit)
+func parsePollingConfig(config *Config, checkTime time.Duration) (time.Duration, time.Duration, error) {
+
+	var jitter, maxTime time.Duration
+	var err error
+
+	if len(config.ClusterChangeCheckJitter) > 0 {
+		jitter, err = time.ParseDuration(config.ClusterChangeCheckJitter)
+		if err != nil || jitter < 0 {
+			return 0, 0, fmt.Errorf("invalid cluster change check jitter duration: %s", config.ClusterChangeCheckJitter)
+		}
+	}
+
+	if len(config.ClusterChangeCheckMaxTime) > 0 {
+		maxTime, err = time.ParseDuration(config.ClusterChangeCheckMaxTime)
+		if err != nil || maxTime < checkTime {
+			return 0, 0, fmt.Errorf("invalid cluster change check max time duration: %s", config.ClusterChangeCheckMaxTime)
+		}
+	}
+
+	return jitter, maxTime, nil
+}
+
+// nextPollInterval - returns the cluster check interval added by a random jitter and limited by the max time
+func (m *Manager) nextPollInterval() time.Duration {
+
+	interval := m.clusterChangeCheckTimeDuration
+
+	if m.clusterChangeCheckJitter > 0 {
+		interval += time.Duration(rand.Int63n(int64(m.clusterChangeCheckJitter) + 1))
+	}
+
+	if m.clusterChangeCheckMaxTime > 0 && interval > m.clusterChangeCheckMaxTime {
+		interval = m.clusterChangeCheckMaxTime
+	}
+
+	return interval
+}
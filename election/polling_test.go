@@ -0,0 +1,88 @@
+package election
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the jittered cluster change polling interval
+//
+
+// newPollingManager - creates a manager with the specified polling configuration
+func newPollingManager(jitter, maxTime string) (*Manager, error) {
+
+	return New(&Config{
+		ReconnectionTimeout:       "10ms",
+		SessionTimeout:            "1s",
+		ClusterChangeCheckTime:    "100ms",
+		ClusterChangeWaitTime:     "10ms",
+		ClusterChangeCheckJitter:  jitter,
+		ClusterChangeCheckMaxTime: maxTime,
+	})
+}
+
+// TestPollIntervalJitter - tests if the successive poll intervals vary within the jitter bounds
+func TestPollIntervalJitter(t *testing.T) {
+
+	m, err := newPollingManager("50ms", "")
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	intervals := map[time.Duration]struct{}{}
+
+	for i := 0; i < 100; i++ {
+		interval := m.nextPollInterval()
+		intervals[interval] = struct{}{}
+
+		if !assert.True(t, interval >= 100*time.Millisecond && interval <= 150*time.Millisecond, "interval out of the jitter bounds: %s", interval) {
+			return
+		}
+	}
+
+	assert.Greater(t, len(intervals), 1, "expected the poll intervals to vary")
+}
+
+// TestPollIntervalMaxTime - tests if the poll intervals are limited by the max time
+func TestPollIntervalMaxTime(t *testing.T) {
+
+	m, err := newPollingManager("1s", "120ms")
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	for i := 0; i < 100; i++ {
+		interval := m.nextPollInterval()
+
+		if !assert.True(t, interval >= 100*time.Millisecond && interval <= 120*time.Millisecond, "interval out of the max time bounds: %s", interval) {
+			return
+		}
+	}
+}
+
+// TestPollIntervalNoJitter - tests if the poll interval is fixed without jitter
+func TestPollIntervalNoJitter(t *testing.T) {
+
+	m, err := newPollingManager("", "")
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	assert.Equal(t, 100*time.Millisecond, m.nextPollInterval(), "expected the cluster change check time")
+}
+
+// TestInvalidPollingConfiguration - tests the polling configuration validation
+func TestInvalidPollingConfiguration(t *testing.T) {
+
+	_, err := newPollingManager("-1s", "")
+	assert.Error(t, err, "expected an error with a negative jitter")
+
+	_, err = newPollingManager("x", "")
+	assert.Error(t, err, "expected an error with an invalid jitter")
+
+	_, err = newPollingManager("", "50ms")
+	assert.Error(t, err, "expected an error with a max time lower than the check time")
+}
@@ -0,0 +1,66 @@
+package election
+
+import (
+	"net/http"
+
+	"github.com/uol/gobol/logh"
+	"github.com/uol/gobol/util"
+)
+
+//
+// Exposes the election metrics in the prometheus text format
+//
+
+// PrometheusHandler - returns a http handler exposing the election metrics in the prometheus text format
+// (election_is_master, election_cluster_size, election_reconnect_total and election_leadership_transitions_total)
+func (m *Manager) PrometheusHandler() http.Handler {
+
+	return util.PrometheusHandler(m.collectPrometheusMetrics)
+}
+
+// collectPrometheusMetrics - collects the election metrics (the cluster size is omitted if it can not be retrieved)
+func (m *Manager) collectPrometheusMetrics(r *http.Request) []util.PrometheusMetric {
+
+	isMaster := 0.0
+	if m.IsMaster() {
+		isMaster = 1.0
+	}
+
+	metrics := []util.PrometheusMetric{
+		{
+			Name:  "election_is_master",
+			Help:  "If this node is the master (1) or not (0).",
+			Type:  util.PrometheusGauge,
+			Value: isMaster,
+		},
+	}
+
+	cluster, err := m.GetClusterInfoCtx(r.Context())
+	if err != nil {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "collectPrometheusMetrics").Err(err).Msg("error retrieving the cluster info")
+		}
+	} else if cluster != nil {
+		metrics = append(metrics, util.PrometheusMetric{
+			Name:  "election_cluster_size",
+			Help:  "The number of nodes in the cluster.",
+			Type:  util.PrometheusGauge,
+			Value: float64(cluster.NumNodes),
+		})
+	}
+
+	return append(metrics,
+		util.PrometheusMetric{
+			Name:  "election_reconnect_total",
+			Help:  "The number of reconnections to the zookeeper.",
+			Type:  util.PrometheusCounter,
+			Value: float64(m.ReconnectCount()),
+		},
+		util.PrometheusMetric{
+			Name:  "election_leadership_transitions_total",
+			Help:  "The number of master/slave transitions of this node.",
+			Type:  util.PrometheusCounter,
+			Value: float64(m.transitions.totalTransitions()),
+		},
+	)
+}
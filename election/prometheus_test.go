@@ -0,0 +1,59 @@
+package election
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the election prometheus metrics
+//
+
+// scrape - returns the handler exposition
+func scrape(t *testing.T, handler http.Handler) string {
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	assert.NoError(t, err, "no error expected reading the body")
+
+	return string(body)
+}
+
+// TestPrometheusHandler - tests the election metrics exposition
+func TestPrometheusHandler(t *testing.T) {
+
+	fake := newFakeZK()
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	for _, m := range []*Manager{master, slave} {
+		feedback, err := m.Start()
+		if !assert.NoError(t, err, "expected no error starting") {
+			return
+		}
+
+		drain(feedback)
+	}
+
+	master.setRole(Slave)
+	master.setRole(Master)
+
+	exposition := scrape(t, master.PrometheusHandler())
+
+	for _, line := range []string{
+		"# TYPE election_is_master gauge\nelection_is_master 1\n",
+		"# TYPE election_cluster_size gauge\nelection_cluster_size 2\n",
+		"# TYPE election_reconnect_total counter\nelection_reconnect_total 0\n",
+		"# TYPE election_leadership_transitions_total counter\nelection_leadership_transitions_total 2\n",
+	} {
+		assert.Contains(t, exposition, line, "expected the metric")
+	}
+
+	assert.Contains(t, scrape(t, slave.PrometheusHandler()), "election_is_master 0\n", "expected the slave metric")
+}
@@ -0,0 +1,60 @@
+package election
+
+import (
+	"fmt"
+)
+
+//
+// The reconnection running the application callback before the election
+//
+
+// OnReconnect - sets a callback invoked after the zookeeper session is reestablished and before the election
+// (call it before starting), returning an error closes the session deferring the election to the next reconnection attempt
+func (m *Manager) OnReconnect(callback func() error) {
+
+	m.reconnectCallback = callback
+}
+
+// runReconnectCallback - runs the reconnection callback, if any
+func (m *Manager) runReconnectCallback() error {
+
+	if m.reconnectCallback == nil {
+		return nil
+	}
+
+	return m.reconnectCallback()
+}
+
+// reconnect - establishes a new session, then runs the reconnection callback and the election using it
+// (the new session is closed if the callback fails, deferring the election to the next reconnection attempt)
+func (m *Manager) reconnect() error {
+
+	if err := m.connect(); err != nil {
+		return err
+	}
+
+	if err := m.runReconnectCallback(); err != nil {
+		m.closeSession()
+		return fmt.Errorf("reconnection callback failed, deferring the election: %w", err)
+	}
+
+	if err := m.startElection(); err != nil {
+		m.closeSession()
+		return err
+	}
+
+	if err := m.startGroups(); err != nil {
+		m.closeSession()
+		return err
+	}
+
+	return nil
+}
+
+// closeSession - closes the session of a failed reconnection attempt, ending its event loops
+func (m *Manager) closeSession() {
+
+	m.setTerminating(true)
+	m.terminateGroups()
+	m.conn().Close()
+}
@@ -0,0 +1,65 @@
+package election
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the application callback run before the election on reconnections
+//
+
+// TestOnReconnect - tests if the callback runs before the election and its errors defer the election
+func TestOnReconnect(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.ReconnectionTimeout = "50ms" })
+
+	var calls, electedBefore, otherSession int32
+
+	m.OnReconnect(func() error {
+		if m.ReconnectCount() > 0 {
+			atomic.AddInt32(&electedBefore, 1)
+		}
+
+		if m.Connection() != fake.lastConnection() {
+			atomic.AddInt32(&otherSession, 1)
+		}
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return fmt.Errorf("lease not acquired")
+		}
+
+		return nil
+	})
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls), "expected no callback when starting")
+
+	start := time.Now()
+
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return m.ReconnectCount() == 1 })
+	if !assert.True(t, ok, "expected the reconnection") {
+		return
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls), "expected the election only after the callback succeeded")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&electedBefore), "expected the callback before the election")
+	assert.Equal(t, int32(0), atomic.LoadInt32(&otherSession), "expected the callback using the new session")
+	assert.Equal(t, 4, fake.numConnections(), "expected a single connection per attempt")
+	assert.Equal(t, fake.lastConnection(), m.Connection(), "expected the election using the last session")
+	assert.True(t, time.Since(start) >= 150*time.Millisecond, "expected the election deferred by the callback errors")
+}
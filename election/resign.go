@@ -0,0 +1,90 @@
+package election
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Lets the master voluntarily step down
+//
+
+// Resign - steps down from the master role deleting the election node and registering this node as a slave,
+// the other nodes are notified by the election node deletion and elect the new master (this node does not
+// run for the election triggered by its own resignation); does nothing if this node is not the master
+// (the election node is deleted only if it still has this node name and was not changed since it was read)
+func (m *Manager) Resign() error {
+
+	if !m.IsMaster() {
+		return nil
+	}
+
+	if m.config.Standalone {
+		return fmt.Errorf("a standalone node can not resign")
+	}
+
+	conn := m.conn()
+	if conn == nil {
+		return fmt.Errorf("not connected to zookeeper")
+	}
+
+	name, err := m.getNodeName()
+	if err != nil {
+		return err
+	}
+
+	data, stat, err := conn.Get(m.config.ZKElectionNodeURI)
+	if err == zk.ErrNoNode || (err == nil && string(data) != name) {
+		m.logNotMaster()
+		return nil
+	} else if err != nil {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "Resign").Err(err).Msg("error reading the election node")
+		}
+
+		return err
+	}
+
+	// the lowest contender left running would keep the other nodes from being elected
+	m.withdrawContender(name)
+
+	atomic.StoreInt32(&m.resigned, 1)
+
+	if err := m.delete(m.config.ZKElectionNodeURI, stat.Version); err != nil {
+		atomic.StoreInt32(&m.resigned, 0)
+
+		if err == zk.ErrBadVersion || err == zk.ErrNoNode {
+			m.logNotMaster()
+			return nil
+		}
+
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "Resign").Err(err).Msg("error deleting the election node")
+		}
+
+		return err
+	}
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", "Resign").Msg("this node has resigned the master role")
+	}
+
+	return m.registerAsSlave(name)
+}
+
+// logNotMaster - logs the resignation of a node that is no longer the master (the election events change its role)
+func (m *Manager) logNotMaster() {
+
+	if logh.WarnEnabled {
+		m.withState(m.logger.Warn()).Str("func", "Resign").Msg("the election node is not held by this node anymore, nothing to resign")
+	}
+}
+
+// hasResigned - checks if the election node deletion was caused by this node resignation (consuming it)
+func (m *Manager) hasResigned() bool {
+
+	return atomic.CompareAndSwapInt32(&m.resigned, 1, 0)
+}
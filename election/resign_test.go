@@ -0,0 +1,146 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the voluntary master resignation
+//
+
+// TestResign - tests if the master hands off the master role to the other node
+func TestResign(t *testing.T) {
+
+	fake := newFakeZK()
+
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	feedback, err := master.Start()
+	if !assert.NoError(t, err, "expected no error starting the master") {
+		return
+	}
+
+	signals := record(feedback)
+
+	feedback, err = slave.Start()
+	if !assert.NoError(t, err, "expected no error starting the slave") {
+		return
+	}
+
+	drain(feedback)
+
+	if !assert.True(t, master.IsMaster(), "expected node-a as master") {
+		return
+	}
+
+	if !assert.NoError(t, master.Resign(), "expected no error resigning") {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, slave.WaitForRole(ctx, Master), "expected node-b as the new master")
+	assert.NoError(t, master.WaitForRole(ctx, Slave), "expected node-a as slave")
+	assert.False(t, master.IsMaster(), "expected node-a not master")
+
+	ok := waitFor(time.Second, func() bool { return signals.contains(Slave) })
+	assert.True(t, ok, "expected the slave signal")
+
+	node, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node") {
+		assert.Equal(t, "node-b", string(node.data), "expected node-b in the election node")
+	}
+
+	_, ok = fake.node("/slaves/node-a")
+	assert.True(t, ok, "expected node-a slave node")
+
+	_, ok = fake.node("/slaves/node-b")
+	assert.False(t, ok, "expected no node-b slave node")
+}
+
+// TestResignSlave - tests if the resignation does nothing when this node is a slave
+func TestResignSlave(t *testing.T) {
+
+	fake := newFakeZK()
+
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	for _, m := range []*Manager{master, slave} {
+		feedback, err := m.Start()
+		if !assert.NoError(t, err, "expected no error starting") {
+			return
+		}
+
+		drain(feedback)
+	}
+
+	assert.NoError(t, slave.Resign(), "expected no error resigning a slave")
+	assert.True(t, master.IsMaster(), "expected node-a still master")
+
+	node, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node") {
+		assert.Equal(t, "node-a", string(node.data), "expected node-a in the election node")
+	}
+}
+
+// TestResignReplacedNode - tests if the resignation does not delete an election node held by another node
+func TestResignReplacedNode(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	drain(feedback)
+
+	if !assert.True(t, fake.setData("/master", []byte("node-b")), "expected the election node") {
+		return
+	}
+
+	assert.NoError(t, m.Resign(), "expected no error resigning")
+
+	node, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node kept") {
+		assert.Equal(t, "node-b", string(node.data), "expected the other node in the election node")
+	}
+}
+
+// TestResignChangedNode - tests if the resignation does not delete an election node changed after it was read
+func TestResignChangedNode(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	drain(feedback)
+
+	fake.failDelete("/master", zk.ErrBadVersion)
+
+	assert.NoError(t, m.Resign(), "expected no error resigning")
+	assert.False(t, m.hasResigned(), "expected the resignation undone")
+
+	_, ok := fake.node("/master")
+	assert.True(t, ok, "expected the election node kept")
+
+	_, ok = fake.node("/slaves/node-a")
+	assert.False(t, ok, "expected no slave node")
+}
@@ -0,0 +1,59 @@
+package election
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+//
+// Tracks the role reported by the election manager
+//
+
+// noRole - the role before any election or after a disconnection
+const noRole int = 0
+
+// setRole - sets the current role and wakes up the role waiters
+func (m *Manager) setRole(role int) {
+
+	m.roleMutex.Lock()
+	defer m.roleMutex.Unlock()
+
+	m.role = role
+	if role != noRole {
+		m.transitions.observe(role, time.Now())
+	}
+
+	close(m.roleChanged)
+	m.roleChanged = make(chan struct{})
+}
+
+// currentRole - returns the current role and a channel closed on the next role change
+func (m *Manager) currentRole() (int, <-chan struct{}) {
+
+	m.roleMutex.Lock()
+	defer m.roleMutex.Unlock()
+
+	return m.role, m.roleChanged
+}
+
+// WaitForRole - blocks until this node has the specified role (Master or Slave) or the context is done
+func (m *Manager) WaitForRole(ctx context.Context, role int) error {
+
+	if role != Master && role != Slave {
+		return fmt.Errorf("invalid role: %d", role)
+	}
+
+	for {
+		current, changed := m.currentRole()
+		if current == role {
+			return nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the role waiting
+//
+
+// TestWaitForRole - tests waiting for the master and slave roles
+func TestWaitForRole(t *testing.T) {
+
+	fake := newFakeZK()
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	masterResult := make(chan error, 1)
+	go func() {
+		masterResult <- master.WaitForRole(ctx, Master)
+	}()
+
+	feedback, err := master.Start()
+	if !assert.NoError(t, err, "expected no error starting the master") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.NoError(t, <-masterResult, "expected the master role")
+
+	feedback, err = slave.Start()
+	if !assert.NoError(t, err, "expected no error starting the slave") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.NoError(t, slave.WaitForRole(ctx, Slave), "expected the slave role")
+}
+
+// TestWaitForRoleContextDone - tests if the waiting ends when the context is done
+func TestWaitForRoleContextDone(t *testing.T) {
+
+	fake := newFakeZK()
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	slave := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	feedback, err := master.Start()
+	if !assert.NoError(t, err, "expected no error starting the master") {
+		return
+	}
+
+	drain(feedback)
+
+	feedback, err = slave.Start()
+	if !assert.NoError(t, err, "expected no error starting the slave") {
+		return
+	}
+
+	drain(feedback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, slave.WaitForRole(ctx, Master), "expected the context error")
+}
+
+// TestWaitForInvalidRole - tests waiting for an invalid role
+func TestWaitForInvalidRole(t *testing.T) {
+
+	m := newFakeZK().newManager()
+
+	assert.Error(t, m.WaitForRole(context.Background(), ClusterChanged), "expected an invalid role error")
+}
@@ -0,0 +1,74 @@
+package election
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+//
+// The manager state shared by the event loops, the reconnection and the public methods
+//
+
+// conn - returns the current zookeeper connection (null if never connected)
+func (m *Manager) conn() ZKConnection {
+
+	m.connectionMutex.RLock()
+	defer m.connectionMutex.RUnlock()
+
+	return m.zkConnection
+}
+
+// setConn - replaces the current zookeeper connection
+func (m *Manager) setConn(conn ZKConnection) {
+
+	m.connectionMutex.Lock()
+	defer m.connectionMutex.Unlock()
+
+	m.zkConnection = conn
+}
+
+// getContext - returns the context ending the event loops
+func (m *Manager) getContext() context.Context {
+
+	m.contextMutex.RLock()
+	defer m.contextMutex.RUnlock()
+
+	return m.ctx
+}
+
+// setContext - replaces the context ending the event loops (the loops of a previous start may still be reading it)
+func (m *Manager) setContext(ctx context.Context) {
+
+	m.contextMutex.Lock()
+	defer m.contextMutex.Unlock()
+
+	m.ctx = ctx
+}
+
+// terminating - checks if the election was disconnected (the event loops must end)
+func (m *Manager) terminating() bool {
+
+	return atomic.LoadInt32(&m.terminate) == 1
+}
+
+// setTerminating - sets if the election was disconnected
+func (m *Manager) setTerminating(terminate bool) {
+
+	atomic.StoreInt32(&m.terminate, boolToInt32(terminate))
+}
+
+// setMaster - sets if this node is the master
+func (m *Manager) setMaster(master bool) {
+
+	atomic.StoreInt32(&m.isMaster, boolToInt32(master))
+}
+
+// boolToInt32 - converts the flag to be stored atomically
+func boolToInt32(flag bool) int32 {
+
+	if flag {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,86 @@
+package election
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uol/gobol/logh"
+)
+
+//
+// Removes the slave node left by this node when it becomes the master
+//
+
+const (
+	// defaultStaleSlaveDeleteRetries - the default number of retries deleting a stale slave node
+	defaultStaleSlaveDeleteRetries int = 3
+
+	// defaultStaleSlaveRetryInterval - the default interval between the stale slave node deletion retries
+	defaultStaleSlaveRetryInterval time.Duration = 100 * time.Millisecond
+)
+
+// parseStaleSlaveConfig - parses the stale slave node deletion retries and interval
+func parseStaleSlaveConfig(config *Config) (int, time.Duration, error) {
+
+	retries := defaultStaleSlaveDeleteRetries
+	if config.StaleSlaveDeleteRetries < 0 {
+		return 0, 0, fmt.Errorf("invalid stale slave delete retries: %d", config.StaleSlaveDeleteRetries)
+	} else if config.StaleSlaveDeleteRetries > 0 {
+		retries = config.StaleSlaveDeleteRetries
+	}
+
+	interval := defaultStaleSlaveRetryInterval
+	if len(config.StaleSlaveRetryInterval) > 0 {
+		var err error
+		interval, err = time.ParseDuration(config.StaleSlaveRetryInterval)
+		if err != nil || interval < 0 {
+			return 0, 0, fmt.Errorf("invalid stale slave retry interval duration: %s", config.StaleSlaveRetryInterval)
+		}
+	}
+
+	return retries, interval, nil
+}
+
+// removeStaleSlave - deletes the slave node of this node (if any), retrying on errors
+func (m *Manager) removeStaleSlave(funcName, name string) error {
+
+	slaveNode := m.config.ZKSlaveNodesURI + "/" + name
+
+	var err error
+
+	for attempt := 0; attempt <= m.staleSlaveDeleteRetries; attempt++ {
+
+		if attempt > 0 {
+			<-time.After(m.staleSlaveRetryInterval)
+		}
+
+		var slave *string
+		slave, err = m.getNodeData(slaveNode)
+		if err != nil {
+			if logh.ErrorEnabled {
+				m.logger.Error().Str("func", funcName).Err(err).Msgf("error retrieving a slave node data '%s'", slaveNode)
+			}
+			continue
+		}
+
+		if slave == nil {
+			return nil
+		}
+
+		err = m.delete(slaveNode, -1)
+		if err != nil {
+			if logh.ErrorEnabled {
+				m.logger.Error().Str("func", funcName).Err(err).Msgf("error deleting slave node '%s' (attempt %d)", slaveNode, attempt+1)
+			}
+			continue
+		}
+
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", funcName).Msg("slave node deleted: " + slaveNode)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("error removing the stale slave node '%s': %w", slaveNode, err)
+}
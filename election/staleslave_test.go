@@ -0,0 +1,145 @@
+package election
+
+import (
+	"testing"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the stale slave node removal when becoming the master
+//
+
+// createStaleSlave - creates the slave node of the node name using another session
+func createStaleSlave(t *testing.T, fake *fakeZK, name string) bool {
+
+	conn, _, err := fake.connect(nil, 0)
+	if !assert.NoError(t, err, "expected no error connecting the old session") {
+		return false
+	}
+
+	_, err = conn.Create("/slaves", nil, 0, zk.WorldACL(zk.PermAll))
+	if !assert.NoError(t, err, "expected no error creating the slave dir") {
+		return false
+	}
+
+	_, err = conn.Create("/slaves/"+name, nil, int32(zk.FlagEphemeral), zk.WorldACL(zk.PermAll))
+
+	return assert.NoError(t, err, "expected no error creating the stale slave node")
+}
+
+// assertConsistentMaster - asserts the cluster view has this node only as the master
+func assertConsistentMaster(t *testing.T, m *Manager, name string) {
+
+	cluster, err := m.GetClusterInfo()
+	if !assert.NoError(t, err, "expected no error getting the cluster info") {
+		return
+	}
+
+	assert.True(t, cluster.IsMaster, "expected this node as master")
+	assert.Equal(t, name, cluster.Master, "expected this node name as master")
+	assert.NotContains(t, cluster.Slaves, name, "expected this node not listed as slave")
+	assert.Equal(t, 1, cluster.NumNodes, "expected a single node")
+}
+
+// TestStaleSlaveRemoved - tests if the stale slave node is removed when this node becomes the master
+func TestStaleSlaveRemoved(t *testing.T) {
+
+	fake := newFakeZK()
+	if !createStaleSlave(t, fake, "node-a") {
+		return
+	}
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	_, ok := fake.node("/slaves/node-a")
+	assert.False(t, ok, "expected the stale slave node removed")
+
+	assertConsistentMaster(t, m, "node-a")
+}
+
+// TestStaleSlaveDeleteRetry - tests if the stale slave node deletion is retried
+func TestStaleSlaveDeleteRetry(t *testing.T) {
+
+	fake := newFakeZK()
+	if !createStaleSlave(t, fake, "node-a") {
+		return
+	}
+
+	fake.failDelete("/slaves/node-a", zk.ErrConnectionClosed, zk.ErrConnectionClosed)
+
+	m := fake.newManager(func(c *Config) {
+		c.NodeName = "node-a"
+		c.StaleSlaveDeleteRetries = 2
+		c.StaleSlaveRetryInterval = "10ms"
+		c.RequireStaleSlaveRemoval = true
+	})
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	_, ok := fake.node("/slaves/node-a")
+	assert.False(t, ok, "expected the stale slave node removed")
+
+	assertConsistentMaster(t, m, "node-a")
+}
+
+// TestStaleSlaveRequiredRemoval - tests if this node refuses the master role when the stale slave node is not removed
+func TestStaleSlaveRequiredRemoval(t *testing.T) {
+
+	fake := newFakeZK()
+	if !createStaleSlave(t, fake, "node-a") {
+		return
+	}
+
+	fake.failDelete("/slaves/node-a", zk.ErrConnectionClosed, zk.ErrConnectionClosed)
+
+	m := fake.newManager(func(c *Config) {
+		c.NodeName = "node-a"
+		c.StaleSlaveDeleteRetries = 1
+		c.StaleSlaveRetryInterval = "10ms"
+		c.RequireStaleSlaveRemoval = true
+	})
+
+	_, err := m.Start()
+	assert.Error(t, err, "expected an error starting with a stale slave node")
+	assert.False(t, m.IsMaster(), "expected this node not to be the master")
+
+	_, ok := fake.node("/master")
+	assert.False(t, ok, "expected the election node released")
+
+	_, ok = fake.node("/slaves/node-a")
+	assert.True(t, ok, "expected the stale slave node kept")
+}
+
+// TestInvalidStaleSlaveConfig - tests the stale slave configuration validation
+func TestInvalidStaleSlaveConfig(t *testing.T) {
+
+	config := &Config{StaleSlaveDeleteRetries: -1}
+
+	_, _, err := parseStaleSlaveConfig(config)
+	assert.Error(t, err, "expected an error with negative retries")
+
+	config = &Config{StaleSlaveRetryInterval: "x"}
+
+	_, _, err = parseStaleSlaveConfig(config)
+	assert.Error(t, err, "expected an error with an invalid interval")
+
+	retries, interval, err := parseStaleSlaveConfig(&Config{})
+	if assert.NoError(t, err, "expected no error with the defaults") {
+		assert.Equal(t, defaultStaleSlaveDeleteRetries, retries, "expected the default retries")
+		assert.Equal(t, defaultStaleSlaveRetryInterval, interval, "expected the default interval")
+	}
+}
@@ -0,0 +1,39 @@
+package election
+
+import "github.com/uol/gobol/logh"
+
+//
+// The local only election used without a zookeeper
+//
+
+// startStandalone - declares this node as master without connecting to the zookeeper
+func (m *Manager) startStandalone() (*chan int, error) {
+
+	name, err := m.getNodeName()
+	if err != nil {
+		return nil, err
+	}
+
+	m.nodeName = name
+	m.setMaster(true)
+	m.setRole(Master)
+	m.signal(Master)
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", "startStandalone").Msg("standalone mode, this node is the master: " + name)
+	}
+
+	return &m.feedbackChannel, nil
+}
+
+// standaloneClusterInfo - returns the single node cluster
+func (m *Manager) standaloneClusterInfo() *Cluster {
+
+	return &Cluster{
+		IsMaster: m.IsMaster(),
+		Master:   m.nodeName,
+		Slaves:   []string{},
+		Nodes:    []string{m.nodeName},
+		NumNodes: 1,
+	}
+}
@@ -0,0 +1,67 @@
+package election
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the standalone mode
+//
+
+// TestStandalone - tests if the standalone mode reports master without the zookeeper
+func TestStandalone(t *testing.T) {
+
+	m, err := New(&Config{
+		NodeName:               "local",
+		ReconnectionTimeout:    "10ms",
+		SessionTimeout:         "1s",
+		ClusterChangeCheckTime: "50ms",
+		ClusterChangeWaitTime:  "10ms",
+		Standalone:             true,
+	})
+	if !assert.NoError(t, err, "expected no error creating the manager") {
+		return
+	}
+
+	m.connector = nil
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	select {
+	case signal := <-*feedback:
+		assert.Equal(t, Master, signal, "expected the master signal")
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the master signal")
+		return
+	}
+
+	assert.True(t, m.IsMaster(), "expected master")
+	assert.Nil(t, m.Connection(), "expected no zookeeper connection")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, m.WaitForRole(ctx, Master), "expected the master role")
+
+	cluster, err := m.GetClusterInfo()
+	if !assert.NoError(t, err, "expected no error getting the cluster info") {
+		return
+	}
+
+	assert.Equal(t, &Cluster{
+		IsMaster: true,
+		Master:   "local",
+		Slaves:   []string{},
+		Nodes:    []string{"local"},
+		NumNodes: 1,
+	}, cluster, "expected a single node cluster")
+
+	m.Disconnect()
+}
@@ -0,0 +1,74 @@
+package election
+
+import (
+	"sync/atomic"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The zookeeper session state change notifications
+//
+
+const stateChangeChannelSize int = 100
+
+// stateTransition - a zookeeper session state transition
+type stateTransition struct {
+	old zk.State
+	new zk.State
+}
+
+// OnStateChange - sets a callback invoked with every zookeeper session state transition (call it before starting),
+// the callback runs in its own goroutine, so a slow callback never blocks the session event loop
+// (transitions are discarded if too many are pending)
+func (m *Manager) OnStateChange(callback func(old, new zk.State)) {
+
+	transitions := make(chan stateTransition, stateChangeChannelSize)
+
+	m.stateMutex.Lock()
+	m.stateChangeChannel = transitions
+	m.stateMutex.Unlock()
+
+	go func() {
+		for t := range transitions {
+			callback(t.old, t.new)
+		}
+	}()
+}
+
+// notifyStateChange - stores the new session state (and the read only flag) and notifies the transition to the callback (if any),
+// the session event loop of a replaced connection may still be notifying, so the transitions are serialized
+func (m *Manager) notifyStateChange(state zk.State) {
+
+	m.stateMutex.Lock()
+	defer m.stateMutex.Unlock()
+
+	old := m.sessionState
+	m.sessionState = state
+
+	switch state {
+	case zk.StateConnectedReadOnly:
+		atomic.StoreInt32(&m.readOnly, 1)
+	case zk.StateConnected, zk.StateDisconnected, zk.StateExpired, zk.StateAuthFailed:
+		atomic.StoreInt32(&m.readOnly, 0)
+	}
+
+	if m.stateChangeChannel == nil {
+		return
+	}
+
+	select {
+	case m.stateChangeChannel <- stateTransition{old: old, new: state}:
+	default:
+		if logh.WarnEnabled {
+			m.logger.Warn().Str("func", "notifyStateChange").Msgf("state change callback is too slow, discarding transition: %s -> %s", old, state)
+		}
+	}
+}
+
+// IsReadOnly - checks if the session is connected to a read only server (no writes and no elections are possible)
+func (m *Manager) IsReadOnly() bool {
+
+	return atomic.LoadInt32(&m.readOnly) == 1
+}
@@ -0,0 +1,160 @@
+package election
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the zookeeper session state change notifications
+//
+
+// stateRecorder - records the state transitions received by the callback
+type stateRecorder struct {
+	transitions []stateTransition
+	mutex       sync.Mutex
+}
+
+// record - the state change callback
+func (r *stateRecorder) record(old, new zk.State) {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.transitions = append(r.transitions, stateTransition{old: old, new: new})
+}
+
+// get - returns a copy of the recorded transitions
+func (r *stateRecorder) get() []stateTransition {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]stateTransition{}, r.transitions...)
+}
+
+// TestOnStateChange - tests if the callback receives the session state transitions in order
+func TestOnStateChange(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	recorder := &stateRecorder{}
+	m.OnStateChange(recorder.record)
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	conn := fake.lastConnection()
+	conn.sendState(zk.StateConnecting)
+	conn.sendState(zk.StateConnected)
+	conn.sendState(zk.StateHasSession)
+
+	expected := []stateTransition{
+		{old: zk.StateUnknown, new: zk.StateConnecting},
+		{old: zk.StateConnecting, new: zk.StateConnected},
+		{old: zk.StateConnected, new: zk.StateHasSession},
+	}
+
+	ok := waitFor(time.Second, func() bool { return len(recorder.get()) == len(expected) })
+	assert.True(t, ok, "expected all transitions")
+	assert.Equal(t, expected, recorder.get(), "expected the same transitions")
+}
+
+// TestOnStateChangeSlowCallback - tests if a blocked callback does not block the session event handling
+func TestOnStateChangeSlowCallback(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	m.OnStateChange(func(old, new zk.State) {
+		<-release
+	})
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	fake.lastConnection().sendState(zk.StateConnected)
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok := waitFor(2*time.Second, func() bool { return m.ReconnectCount() == 1 })
+	assert.True(t, ok, "expected the reconnection while the callback is blocked")
+}
+
+// TestIsReadOnly - tests if the read only flag follows the session state
+func TestIsReadOnly(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.False(t, m.IsReadOnly(), "expected a writable session")
+
+	conn := fake.lastConnection()
+	conn.sendState(zk.StateConnectedReadOnly)
+	conn.sendState(zk.StateHasSession)
+
+	assert.True(t, waitFor(time.Second, m.IsReadOnly), "expected a read only session")
+
+	conn.sendState(zk.StateConnected)
+
+	assert.True(t, waitFor(time.Second, func() bool { return !m.IsReadOnly() }), "expected a writable session")
+}
+
+// TestConcurrentStateChange - tests if the transitions notified by concurrent session event loops are chained
+func TestConcurrentStateChange(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	recorder := &stateRecorder{}
+	m.OnStateChange(recorder.record)
+
+	states := []zk.State{zk.StateConnecting, zk.StateConnected, zk.StateHasSession, zk.StateDisconnected}
+
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				m.notifyStateChange(states[j%len(states)])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	ok := waitFor(time.Second, func() bool { return len(recorder.get()) == 80 })
+	if !assert.True(t, ok, "expected all transitions") {
+		return
+	}
+
+	previous := zk.StateUnknown
+	for _, transition := range recorder.get() {
+		assert.Equal(t, previous, transition.old, "expected the previous state")
+		previous = transition.new
+	}
+}
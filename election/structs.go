@@ -14,24 +14,104 @@ const ClusterChanged = 3
 // Disconnected - int signal for disconnection
 const Disconnected = 4
 
+// Failed - signals an unrecoverable failure (no reconnection is tried)
+const Failed = 5
+
+// Terminated - signals the end of the election started with a context, the feedback channel is closed next
+const Terminated = 6
+
 // Config - configures the election
 type Config struct {
-	ZKURL                  []string
-	ZKElectionNodeURI      string
-	ZKSlaveNodesURI        string
-	ReconnectionTimeout    string
+	ZKURL []string
+
+	// NodeName - identifies this node in the election and slave nodes (the hostname if empty)
+	NodeName string
+
+	// ZKElectionNodeURI - the node holding the master name (the nodes running for the election are registered
+	// under the "<ZKElectionNodeURI>-contenders" node, the lowest node name is elected)
+	ZKElectionNodeURI string
+	ZKSlaveNodesURI   string
+
+	// ReconnectionTimeout - the first reconnection wait
+	ReconnectionTimeout string
+
+	// ReconnectionMaxTimeout - the longest reconnection wait (1 minute by default)
+	ReconnectionMaxTimeout string
+
+	// ReconnectionBackoffFactor - multiplies the reconnection wait on each failed attempt (2 by default, 1 keeps it fixed),
+	// every wait has a +/-20% jitter
+	ReconnectionBackoffFactor float64
+
 	SessionTimeout         string
 	ClusterChangeCheckTime string
 	ClusterChangeWaitTime  string
+
+	// ClusterChangeCheckJitter - a random amount up to this value is added to each cluster check
+	ClusterChangeCheckJitter string
+
+	// ClusterChangeCheckMaxTime - limits the cluster check time with the jitter
+	ClusterChangeCheckMaxTime string
+
+	// FlappingWindow - the sliding window counting the master/slave transitions (1 minute by default)
+	FlappingWindow string
+
+	// FlappingThreshold - the transitions in the window reported as flapping (5 by default)
+	FlappingThreshold int
+
+	// StaleSlaveDeleteRetries - the attempts to delete this node's slave node when becoming master
+	StaleSlaveDeleteRetries int
+	StaleSlaveRetryInterval string
+
+	// RequireStaleSlaveRemoval - releases the election node if the slave node can not be deleted
+	RequireStaleSlaveRemoval bool
+
+	// DisconnectWaitTime - limits the wait for the closed connection to report the disconnection (2s by default)
+	DisconnectWaitTime string
+
+	// UsePollingForNodeEvents - polls the cluster every ClusterChangeCheckTime instead of watching the slave nodes
+	// (the polling is also used when the watch can not be set)
+	UsePollingForNodeEvents bool
+
+	// AuthScheme - authenticates each session with the AuthCredential (if set)
+	AuthScheme     string
+	AuthCredential string
+
+	// ACL - replaces the WorldACL(PermAll) of all created nodes
+	ACL []zk.ACL
+
+	// ElectionACL - replaces the ACL of the election node
+	ElectionACL []zk.ACL
+
+	// SlaveACL - replaces the ACL of the slave nodes
+	SlaveACL []zk.ACL
+
+	// Standalone - declares this node as master without any zookeeper connection
+	Standalone bool
+
+	// Groups - named groups elected independently using the same connection
+	Groups map[string]GroupConfig
+}
+
+// GroupConfig - the election and slave nodes of a named election group
+type GroupConfig struct {
+	ZKElectionNodeURI string
+	ZKSlaveNodesURI   string
 }
 
-// Cluster - has cluster info
+// Cluster - has cluster info (the change is only set in the cluster delivered by the OnClusterChanged callback)
 type Cluster struct {
 	IsMaster bool
 	Master   string
 	Slaves   []string
 	Nodes    []string
 	NumNodes int
+	ClusterChange
+}
+
+// ClusterChange - the nodes added and removed since the previous cluster check
+type ClusterChange struct {
+	Added   []string
+	Removed []string
 }
 
 const (
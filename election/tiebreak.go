@@ -0,0 +1,202 @@
+package election
+
+import (
+	"sort"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The deterministic election race resolution
+//
+
+// contendersSuffix - the suffix of the election node URI naming the election contenders directory
+const contendersSuffix string = "-contenders"
+
+// getNodeName - returns the configured node name or this node hostname
+func (m *Manager) getNodeName() (string, error) {
+
+	if len(m.config.NodeName) > 0 {
+		return m.config.NodeName, nil
+	}
+
+	return m.GetHostname()
+}
+
+// contenderNode - returns the contender node of the node name
+func (m *Manager) contenderNode(name string) string {
+
+	return m.config.ZKElectionNodeURI + contendersSuffix + "/" + name
+}
+
+// registerContender - registers this node as an election contender (an ephemeral node named after this node
+// kept until the session ends or this node resigns)
+func (m *Manager) registerContender(name string) error {
+
+	node := m.contenderNode(name)
+
+	err := m.createAncestors(node)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.create(node, []byte(name), int32(zk.FlagEphemeral), m.defaultACL)
+	if err != nil && err != zk.ErrNodeExists {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "registerContender").Err(err).Msg("error creating the contender node")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// withdrawContender - removes this node from the election contenders (the next lowest contender can be elected)
+func (m *Manager) withdrawContender(name string) {
+
+	err := m.delete(m.contenderNode(name), -1)
+	if err != nil && err != zk.ErrNoNode {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "withdrawContender").Err(err).Msg("error deleting the contender node")
+		}
+	}
+}
+
+// lowestContender - returns the lowest election contender name
+func (m *Manager) lowestContender() (string, error) {
+
+	contenders, _, err := m.conn().Children(m.config.ZKElectionNodeURI + contendersSuffix)
+	if err != nil {
+		return "", err
+	}
+
+	if len(contenders) == 0 {
+		return "", zk.ErrNoNode
+	}
+
+	sort.Strings(contenders)
+
+	return contenders[0], nil
+}
+
+// isContender - checks if the node name is an election contender
+func (m *Manager) isContender(name string) (bool, error) {
+
+	data, err := m.getNodeData(m.contenderNode(name))
+	if err != nil {
+		return false, err
+	}
+
+	return data != nil, nil
+}
+
+// breakTie - resolves a lost election race: only the lowest contender creates the election node, so the race is
+// lost to a higher contender that created it before this node was registered, and this node claims it; the
+// election node created by a node not running for the election (an announced master) is never claimed
+func (m *Manager) breakTie(name string) error {
+
+	data, stat, err := m.conn().Get(m.config.ZKElectionNodeURI)
+	if err == zk.ErrNoNode {
+		return m.electForMaster()
+	} else if err != nil {
+		return err
+	}
+
+	holder := string(data)
+
+	if holder == name {
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "breakTie").Msg("the election node already holds this node: " + name)
+		}
+
+		return m.becomeMaster("breakTie", m.config.ZKElectionNodeURI, name)
+	}
+
+	contender, err := m.isContender(holder)
+	if err != nil {
+		return err
+	}
+
+	if !contender || holder < name {
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "breakTie").Msg("the election race was lost to: " + holder)
+		}
+
+		return m.registerAsSlave(name)
+	}
+
+	if logh.InfoEnabled {
+		m.logger.Info().Str("func", "breakTie").Msg("claiming the election node created by the higher contender: " + holder)
+	}
+
+	err = m.delete(m.config.ZKElectionNodeURI, stat.Version)
+	if err == zk.ErrNoNode || err == zk.ErrBadVersion {
+		return m.electForMaster()
+	} else if err != nil {
+		return err
+	}
+
+	path, err := m.create(m.config.ZKElectionNodeURI, []byte(name), int32(zk.FlagEphemeral), m.electionACL)
+	if err == zk.ErrNodeExists {
+		return m.registerAsSlave(name)
+	} else if err != nil {
+		return err
+	}
+
+	return m.becomeMaster("breakTie", path, name)
+}
+
+// watchContender - runs for the election again if the lower contender leaves before a master is elected
+// (the watch ends when the election ends or the connection is replaced by a reconnection)
+func (m *Manager) watchContender(contender string) {
+
+	conn := m.conn()
+
+	exists, _, events, err := conn.ExistsW(m.contenderNode(contender))
+	if err != nil {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "watchContender").Err(err).Msg("error watching the contender node: " + contender)
+		}
+		return
+	}
+
+	m.goLoop(func() {
+
+		if exists {
+			var event zk.Event
+
+			select {
+			case event = <-events:
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("watchContender") {
+				return
+			}
+
+			if event.Type != zk.EventNodeDeleted {
+				return
+			}
+		}
+
+		if m.terminating() || m.conn() != conn {
+			return
+		}
+
+		master, err := m.getZKMasterNode()
+		if err != nil || master != nil {
+			return
+		}
+
+		if logh.InfoEnabled {
+			m.logger.Info().Str("func", "watchContender").Msg("the lower contender has left before being elected: " + contender)
+		}
+
+		if err := m.electForMaster(); err != nil {
+			if logh.ErrorEnabled {
+				m.logger.Error().Str("func", "watchContender").Err(err).Msg("error trying to elect this node for master")
+			}
+		}
+	})
+}
@@ -0,0 +1,197 @@
+package election
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the deterministic election race resolution
+//
+
+// TestTieBreak - tests if the lowest node name wins an election race no matter which node creates the election node first
+func TestTieBreak(t *testing.T) {
+
+	for _, first := range []string{"node-a", "node-b"} {
+
+		fake := newFakeZK()
+
+		managers := map[string]*Manager{}
+		for _, name := range []string{"node-a", "node-b"} {
+			nodeName := name
+			managers[name] = fake.newManager(func(c *Config) { c.NodeName = nodeName })
+		}
+
+		winner := managers["node-a"]
+		loser := managers["node-b"]
+
+		racing := managers[first]
+		last := "node-a"
+		if first == "node-a" {
+			last = "node-b"
+		}
+
+		var racingSignals *signalRecorder
+
+		// both nodes see no master, the first one is elected before the other one is registered as a contender
+		var raced int32
+		fake.mutex.Lock()
+		fake.beforeCreate = func(path string) {
+			if path == "/master-contenders/"+last && atomic.CompareAndSwapInt32(&raced, 0, 1) {
+				feedback, err := racing.Start()
+				if assert.NoError(t, err, "expected no error starting the racing node") {
+					racingSignals = record(feedback)
+				}
+			}
+		}
+		fake.mutex.Unlock()
+
+		feedback, err := managers[last].Start()
+		if !assert.NoError(t, err, "expected no error starting the node registered last") {
+			return
+		}
+
+		lastSignals := record(feedback)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+
+		assert.NoError(t, winner.WaitForRole(ctx, Master), "expected the lowest node as master when %s creates first", first)
+		assert.NoError(t, loser.WaitForRole(ctx, Slave), "expected the highest node as slave when %s creates first", first)
+
+		cancel()
+
+		<-time.After(50 * time.Millisecond)
+
+		assert.True(t, winner.IsMaster(), "expected node-a still master when %s creates first", first)
+		assert.False(t, loser.IsMaster(), "expected a single master when %s creates first", first)
+
+		winnerSignals := lastSignals
+		if first == "node-a" {
+			winnerSignals = racingSignals
+		}
+
+		if assert.NotNil(t, winnerSignals, "expected the racing node started") {
+			assert.False(t, winnerSignals.contains(Slave), "expected the lowest node never slave when %s creates first", first)
+		}
+
+		node, ok := fake.node("/master")
+		if assert.True(t, ok, "expected the election node") {
+			assert.Equal(t, "node-a", string(node.data), "expected the lowest node in the election node")
+		}
+
+		_, ok = fake.node("/slaves/node-a")
+		assert.False(t, ok, "expected no slave node of the lowest node")
+
+		_, ok = fake.node("/slaves/node-b")
+		assert.True(t, ok, "expected the slave node of the highest node")
+	}
+}
+
+// TestTieBreakAnnouncedMaster - tests if the master elected before a lower node joins is not replaced
+func TestTieBreakAnnouncedMaster(t *testing.T) {
+
+	fake := newFakeZK()
+
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	feedback, err := master.Start()
+	if !assert.NoError(t, err, "expected no error starting the master") {
+		return
+	}
+
+	masterSignals := record(feedback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if !assert.NoError(t, master.WaitForRole(ctx, Master), "expected the first node as master") {
+		return
+	}
+
+	lower := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err = lower.Start()
+	if !assert.NoError(t, err, "expected no error starting the lower node") {
+		return
+	}
+
+	drain(feedback)
+
+	assert.NoError(t, lower.WaitForRole(ctx, Slave), "expected the lower node joining later as slave")
+
+	<-time.After(50 * time.Millisecond)
+
+	assert.True(t, master.IsMaster(), "expected the announced master not replaced")
+	assert.False(t, masterSignals.contains(Slave), "expected no slave signal to the announced master")
+}
+
+// TestTieBreakContenderLeft - tests if the higher contender is elected when the lowest one leaves before being elected
+func TestTieBreakContenderLeft(t *testing.T) {
+
+	fake := newFakeZK()
+
+	// the lowest contender registered by a node that has not created the election node yet
+	fake.mutex.Lock()
+	fake.nodes["/master-contenders"] = &fakeNode{}
+	fake.nodes["/master-contenders/node-a"] = &fakeNode{data: []byte("node-a")}
+	fake.mutex.Unlock()
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	drain(feedback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if !assert.NoError(t, m.WaitForRole(ctx, Slave), "expected the higher contender waiting as slave") {
+		return
+	}
+
+	fake.mutex.Lock()
+	delete(fake.nodes, "/master-contenders/node-a")
+	fake.fire("/master-contenders/node-a", zk.EventNodeDeleted)
+	fake.mutex.Unlock()
+
+	assert.NoError(t, m.WaitForRole(ctx, Master), "expected the higher contender elected after the lowest one left")
+}
+
+// TestTieBreakOwnNode - tests if the node finding its own name in the election node stays the master
+func TestTieBreakOwnNode(t *testing.T) {
+
+	fake := newFakeZK()
+
+	// the election node created by this node in a previous session
+	fake.mutex.Lock()
+	fake.nodes["/master"] = &fakeNode{data: []byte("node-a")}
+	fake.mutex.Unlock()
+
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	feedback, err := m.Start()
+	if !assert.NoError(t, err, "expected no error starting") {
+		return
+	}
+
+	signals := record(feedback)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	assert.NoError(t, m.WaitForRole(ctx, Master), "expected the node holding the election node as master")
+	assert.True(t, m.IsMaster(), "expected the master flag")
+	assert.True(t, waitFor(time.Second, func() bool { return signals.contains(Master) }), "expected the master signal")
+	assert.False(t, signals.contains(Slave), "expected no slave signal")
+
+	_, ok := fake.node("/slaves/node-a")
+	assert.False(t, ok, "expected no slave node")
+}
@@ -0,0 +1,117 @@
+package election
+
+import (
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/uol/gobol/logh"
+)
+
+//
+// The single non-blocking master election attempt
+//
+
+// TryBecomeMaster - makes a single attempt to create the election node returning if this node is the master
+// (connects to the zookeeper if not connected without starting the election, no slave node is registered
+// and no new attempt is done when it fails)
+func (m *Manager) TryBecomeMaster() (bool, error) {
+
+	if m.config.Standalone {
+		return true, nil
+	}
+
+	name, err := m.getNodeName()
+	if err != nil {
+		return false, err
+	}
+
+	if m.conn() == nil {
+		if err := m.connectBare(); err != nil {
+			return false, err
+		}
+	}
+
+	err = m.createAncestors(m.config.ZKElectionNodeURI)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = m.create(m.config.ZKElectionNodeURI, []byte(name), int32(zk.FlagEphemeral), m.electionACL)
+	if err == zk.ErrNodeExists {
+		master, err := m.getZKMasterNode()
+		if err != nil {
+			return false, err
+		}
+
+		if master == nil || *master != name {
+			m.setMaster(false)
+			return false, nil
+		}
+	} else if err != nil {
+		if logh.ErrorEnabled {
+			m.logger.Error().Str("func", "TryBecomeMaster").Err(err).Msg("error creating the election node")
+		}
+
+		return false, err
+	}
+
+	m.setMaster(true)
+	m.setRole(Master)
+
+	if logh.InfoEnabled {
+		m.withState(m.logger.Info()).Str("func", "TryBecomeMaster").Msg("this node is the master: " + name)
+	}
+
+	m.signal(Master)
+
+	return true, nil
+}
+
+// connectBare - connects to the zookeeper only consuming the session events (no election is started
+// and the lost connection is not reconnected, the session recovery is left to the zookeeper client)
+func (m *Manager) connectBare() error {
+
+	if logh.InfoEnabled {
+		m.logger.Info().Str("func", "connectBare").Msg("connecting to zookeeper without election...")
+	}
+
+	conn, events, err := m.dial()
+	if err != nil {
+		return err
+	}
+
+	m.setConn(conn)
+	m.setTerminating(false)
+
+	m.goLoop(func() {
+		for {
+
+			if m.conn() != conn || m.terminating() {
+				return
+			}
+
+			var event zk.Event
+			var open bool
+
+			select {
+			case event, open = <-events:
+				if !open {
+					return
+				}
+			case <-m.getContext().Done():
+			}
+
+			if m.cancelled("connectBare") {
+				return
+			}
+
+			if m.terminating() || m.conn() != conn {
+				continue
+			}
+
+			if event.Type == zk.EventSession {
+				m.notifyStateChange(event.State)
+			}
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package election
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+	"github.com/stretchr/testify/assert"
+)
+
+//
+// Tests the single non-blocking master election attempt
+//
+
+// TestTryBecomeMasterRace - tests if exactly one of the racing nodes becomes the master
+func TestTryBecomeMasterRace(t *testing.T) {
+
+	fake := newFakeZK()
+
+	names := []string{"node-a", "node-b", "node-c"}
+	results := make([]bool, len(names))
+	errs := make([]error, len(names))
+
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	wg.Add(len(names))
+
+	for i, name := range names {
+		nodeName := name
+		m := fake.newManager(func(c *Config) { c.NodeName = nodeName })
+
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = m.TryBecomeMaster()
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	winners := []string{}
+	for i, name := range names {
+		assert.NoError(t, errs[i], "expected no error trying to become master: %s", name)
+		if results[i] {
+			winners = append(winners, name)
+		}
+	}
+
+	if !assert.Len(t, winners, 1, "expected exactly one master") {
+		return
+	}
+
+	node, ok := fake.node("/master")
+	if assert.True(t, ok, "expected the election node") {
+		assert.Equal(t, winners[0], string(node.data), "expected the winner in the election node")
+	}
+
+	_, ok = fake.node("/slaves")
+	assert.False(t, ok, "expected no slave registration")
+}
+
+// TestTryBecomeMasterAgain - tests if the master keeps succeeding and the other nodes keep failing
+func TestTryBecomeMasterAgain(t *testing.T) {
+
+	fake := newFakeZK()
+
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	other := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	ok, err := master.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error on the first attempt") || !assert.True(t, ok, "expected the first attempt to succeed") {
+		return
+	}
+
+	ok, err = master.TryBecomeMaster()
+	assert.NoError(t, err, "expected no error on the second attempt")
+	assert.True(t, ok, "expected the master to keep succeeding")
+
+	ok, err = other.TryBecomeMaster()
+	assert.NoError(t, err, "expected no error on the other node attempt")
+	assert.False(t, ok, "expected the other node to fail")
+
+	role, _ := master.currentRole()
+	assert.Equal(t, Master, role, "expected the master role")
+
+	role, _ = other.currentRole()
+	assert.Equal(t, noRole, role, "expected no role on the other node")
+	assert.False(t, other.IsMaster(), "expected the other node not as master")
+}
+
+// TestTryBecomeMasterHolder - tests if a node already holding the election node is set as master
+func TestTryBecomeMasterHolder(t *testing.T) {
+
+	fake := newFakeZK()
+
+	first := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	second := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	ok, err := first.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error on the first attempt") || !assert.True(t, ok, "expected the first attempt to succeed") {
+		return
+	}
+
+	ok, err = second.TryBecomeMaster()
+	assert.NoError(t, err, "expected no error holding the election node")
+	assert.True(t, ok, "expected the holder to succeed")
+	assert.True(t, second.IsMaster(), "expected the holder as master")
+
+	role, _ := second.currentRole()
+	assert.Equal(t, Master, role, "expected the master role")
+}
+
+// TestTryBecomeMasterSessionEvents - tests if the session events of the connection are consumed
+func TestTryBecomeMasterSessionEvents(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager()
+
+	drain(&m.feedbackChannel)
+
+	ok, err := m.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error") || !assert.True(t, ok, "expected the attempt to succeed") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	fake.lastConnection().sendState(zk.StateConnectedReadOnly)
+
+	ok = waitFor(2*time.Second, m.IsReadOnly)
+	assert.True(t, ok, "expected the session state change")
+}
+
+// TestTryBecomeMasterNoElection - tests if a lost connection of a failed attempt neither reconnects nor registers a slave
+func TestTryBecomeMasterNoElection(t *testing.T) {
+
+	fake := newFakeZK()
+
+	master := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+	other := fake.newManager(func(c *Config) { c.NodeName = "node-b" })
+
+	ok, err := master.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error on the master attempt") || !assert.True(t, ok, "expected the master attempt to succeed") {
+		return
+	}
+
+	ok, err = other.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error on the other node attempt") || !assert.False(t, ok, "expected the other node to fail") {
+		return
+	}
+
+	defer other.Disconnect()
+
+	connections := fake.numConnections()
+	fake.lastConnection().sendState(zk.StateDisconnected)
+
+	ok = waitFor(time.Second, func() bool {
+		other.stateMutex.Lock()
+		defer other.stateMutex.Unlock()
+		return other.sessionState == zk.StateDisconnected
+	})
+	assert.True(t, ok, "expected the session state change")
+
+	<-time.After(100 * time.Millisecond)
+
+	assert.Equal(t, connections, fake.numConnections(), "expected no reconnection")
+
+	_, ok = fake.node("/slaves/node-b")
+	assert.False(t, ok, "expected no slave registration")
+
+	role, _ := other.currentRole()
+	assert.Equal(t, noRole, role, "expected no role on the other node")
+}
+
+// TestTryBecomeMasterSignal - tests if a successful attempt sends the master signal
+func TestTryBecomeMasterSignal(t *testing.T) {
+
+	fake := newFakeZK()
+	m := fake.newManager(func(c *Config) { c.NodeName = "node-a" })
+
+	signals := record(&m.feedbackChannel)
+
+	ok, err := m.TryBecomeMaster()
+	if !assert.NoError(t, err, "expected no error") || !assert.True(t, ok, "expected the attempt to succeed") {
+		return
+	}
+
+	defer m.Disconnect()
+
+	assert.True(t, waitFor(time.Second, func() bool { return signals.contains(Master) }), "expected the master signal")
+}
@@ -116,6 +116,34 @@ func (el *ContextualLogger) Panic() *zerolog.Event {
 // ConfigureGlobalLogger - configures the logger globally
 func ConfigureGlobalLogger(lvl Level, fmt Format) {
 
+	setGlobalLevel(lvl)
+
+	var out io.Writer
+	var err io.Writer
+
+	if fmt == CONSOLE {
+		out = zerolog.ConsoleWriter{Out: os.Stdout}
+		err = zerolog.ConsoleWriter{Out: os.Stderr}
+	} else {
+		out = os.Stdout
+		err = os.Stderr
+	}
+
+	configureOutput(out, err)
+}
+
+// ConfigureGlobalLoggerOutput - configures the logger globally writing the json events to the specified writers
+// (useful to observe the logged fields, like ConfigureGlobalLogger it must be called before logging, the global
+// loggers are not synchronized)
+func ConfigureGlobalLoggerOutput(lvl Level, out, err io.Writer) {
+
+	setGlobalLevel(lvl)
+	configureOutput(out, err)
+}
+
+// setGlobalLevel - sets the global log level
+func setGlobalLevel(lvl Level) {
+
 	switch lvl {
 	case INFO:
 		zerolog.SetGlobalLevel(zerolog.InfoLevel)
@@ -134,17 +162,10 @@ func ConfigureGlobalLogger(lvl Level, fmt Format) {
 	case SILENT:
 		zerolog.SetGlobalLevel(zerolog.Disabled)
 	}
+}
 
-	var out io.Writer
-	var err io.Writer
-
-	if fmt == CONSOLE {
-		out = zerolog.ConsoleWriter{Out: os.Stdout}
-		err = zerolog.ConsoleWriter{Out: os.Stderr}
-	} else {
-		out = os.Stdout
-		err = os.Stderr
-	}
+// configureOutput - creates the loggers using the writers and updates the enabled levels
+func configureOutput(out, err io.Writer) {
 
 	stdout = zerolog.New(out).With().Timestamp().Logger()
 	stderr = zerolog.New(err).With().Timestamp().Logger()
@@ -0,0 +1,110 @@
+package structs
+
+/**
+* Copy and comparison helpers of the points.
+**/
+
+// cloneTags - returns a copy of the tag map (nil if the map is nil)
+func cloneTags(tags map[string]string) map[string]string {
+
+	if tags == nil {
+		return nil
+	}
+
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = v
+	}
+
+	return result
+}
+
+// equalTags - compares the tag maps (a nil map equals an empty one)
+func equalTags(a, b map[string]string) bool {
+
+	if len(a) != len(b) {
+		return false
+	}
+
+	for k, v := range a {
+		if other, ok := b[k]; !ok || other != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clone - returns a deep copy of the point (including the tags)
+func (p *Point) Clone() *Point {
+
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	clone.Tags = cloneTags(p.Tags)
+
+	return &clone
+}
+
+// Equal - compares the metric, tags and timestamp of both points
+func (p *Point) Equal(other *Point) bool {
+
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	return p.Metric == other.Metric &&
+		p.Timestamp == other.Timestamp &&
+		equalTags(p.Tags, other.Tags)
+}
+
+// Clone - returns a deep copy of the number point (including the tags)
+func (p *NumberPoint) Clone() *NumberPoint {
+
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	clone.Tags = cloneTags(p.Tags)
+
+	return &clone
+}
+
+// Equal - compares all properties of both number points (including the tags)
+func (p *NumberPoint) Equal(other *NumberPoint) bool {
+
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	return p.Point.Equal(&other.Point) &&
+		p.Value == other.Value &&
+		p.Aggregator == other.Aggregator &&
+		p.Interval == other.Interval
+}
+
+// Clone - returns a deep copy of the text point (including the tags)
+func (p *TextPoint) Clone() *TextPoint {
+
+	if p == nil {
+		return nil
+	}
+
+	clone := *p
+	clone.Tags = cloneTags(p.Tags)
+
+	return &clone
+}
+
+// Equal - compares all properties of both text points (including the tags)
+func (p *TextPoint) Equal(other *TextPoint) bool {
+
+	if p == nil || other == nil {
+		return p == other
+	}
+
+	return p.Point.Equal(&other.Point) && p.Text == other.Text
+}
@@ -1,10 +1,52 @@
 package structs
 
+import "time"
+
 /**
 * All common structs used by the timeline library.
 * @author rnojiri
 **/
 
+// TimestampPrecision - the resolution of a point timestamp
+type TimestampPrecision uint8
+
+const (
+	// Seconds - timestamps in seconds since the epoch (default)
+	Seconds TimestampPrecision = 0
+
+	// Milliseconds - timestamps in milliseconds since the epoch
+	Milliseconds TimestampPrecision = 1
+
+	// Nanoseconds - timestamps in nanoseconds since the epoch
+	Nanoseconds TimestampPrecision = 2
+)
+
+// Timestamp - converts the time to a timestamp using this precision
+func (p TimestampPrecision) Timestamp(t time.Time) int64 {
+
+	switch p {
+	case Milliseconds:
+		return t.UnixNano() / int64(time.Millisecond)
+	case Nanoseconds:
+		return t.UnixNano()
+	default:
+		return t.Unix()
+	}
+}
+
+// Milliseconds - converts a timestamp in this precision to milliseconds since the epoch
+func (p TimestampPrecision) Milliseconds(timestamp int64) int64 {
+
+	switch p {
+	case Milliseconds:
+		return timestamp
+	case Nanoseconds:
+		return timestamp / int64(time.Millisecond)
+	default:
+		return timestamp * 1000
+	}
+}
+
 // Point - the base point
 type Point struct {
 	Metric    string            `json:"metric"`
@@ -12,10 +54,12 @@ type Point struct {
 	Timestamp int64             `json:"timestamp"`
 }
 
-// NumberPoint - a point with number type value
+// NumberPoint - a point with number type value (the aggregator and interval are only set on rollup points)
 type NumberPoint struct {
 	Point
-	Value float64 `json:"value"`
+	Value      float64 `json:"value"`
+	Aggregator string  `json:"aggregator,omitempty"`
+	Interval   string  `json:"interval,omitempty"`
 }
 
 // TextPoint - a point with text type value
@@ -23,3 +67,60 @@ type TextPoint struct {
 	Point
 	Text string `json:"text"`
 }
+
+// ValueType - the generic point value type discriminator
+type ValueType string
+
+const (
+	// NumberValue - a floating point value
+	NumberValue ValueType = "number"
+
+	// IntegerValue - an integer value
+	IntegerValue ValueType = "integer"
+
+	// TextValue - a string value
+	TextValue ValueType = "text"
+
+	// BoolValue - a boolean value
+	BoolValue ValueType = "bool"
+)
+
+// GenericPoint - a point with a value of any supported type (float, integer, string or bool)
+type GenericPoint struct {
+	Point
+	Value interface{} `json:"value"`
+	Type  ValueType   `json:"type"`
+}
+
+// newNumberPoint - creates a new number point timestamped now using the precision
+func newNumberPoint(precision TimestampPrecision, metric string, value float64, tags map[string]string) *NumberPoint {
+
+	return &NumberPoint{
+		Point: Point{
+			Metric:    metric,
+			Tags:      tags,
+			Timestamp: precision.Timestamp(time.Now()),
+		},
+		Value: value,
+	}
+}
+
+// NewNumberPoint - creates a new number point timestamped now in seconds
+func NewNumberPoint(metric string, value float64, tags map[string]string) *NumberPoint {
+
+	return newNumberPoint(Seconds, metric, value, tags)
+}
+
+// NewNumberPointMillis - creates a new number point timestamped now in milliseconds
+// (the timestamp is sent as is, configure the transport TimestampPrecision to match it)
+func NewNumberPointMillis(metric string, value float64, tags map[string]string) *NumberPoint {
+
+	return newNumberPoint(Milliseconds, metric, value, tags)
+}
+
+// NewNumberPointNanos - creates a new number point timestamped now in nanoseconds
+// (the timestamp is sent as is, configure the transport TimestampPrecision to match it)
+func NewNumberPointNanos(metric string, value float64, tags map[string]string) *NumberPoint {
+
+	return newNumberPoint(Nanoseconds, metric, value, tags)
+}
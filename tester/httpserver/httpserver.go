@@ -3,32 +3,72 @@ package httpserver
 import (
 	"bytes"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
 )
 
 // RequestData - the request data sent to the server
+// (the raw body keeps the exact bytes for binary payloads)
+// (the form keeps the parsed values of the form encoded and multipart bodies)
+// (the remote address identifies the client connection)
+// (the query keeps the request query parameters, in a response it has the parameters required to match it)
 type RequestData struct {
-	URI     string
-	Body    string
-	Method  string
-	Headers http.Header
+	URI        string
+	Body       string
+	RawBody    []byte
+	Form       url.Values
+	Method     string
+	Headers    http.Header
+	RemoteAddr string
+	Query      url.Values
 }
 
-// ResponseData - the expected response data for each configured URI
+// ResponseData - the expected response data for each configured URI and method
+// (the chunks are written after the body, flushing each one to stream the response)
+// (the drop connection flag closes the connection without a response, simulating a network reset)
+// (the echo body flag writes the received request body instead of the configured one)
+// (the validator rejects the request body with status 400 when it returns an error)
 type ResponseData struct {
 	RequestData
-	Status int
+	Status         int
+	Chunks         [][]byte
+	DropConnection bool
+	EchoBody       bool
+	Validator      BodyValidator
 }
 
 // HTTPServer - the server listening for HTTP requests
 type HTTPServer struct {
 	server         *httptest.Server
 	requestChannel chan *RequestData
-	responseMap    map[string]ResponseData
+	requests       []*RequestData
+	oldestRequest  int
+	maxRequests    int
+	bytesReceived  int64
+	responseMap    map[string]map[string][]ResponseData
+	onRequest      func(t *testing.T, r *RequestData)
+	onRequestT     *testing.T
+	mutex          sync.RWMutex
+	authUser       string
+	authPass       string
+	requireAuth    bool
+}
+
+// Timeouts - the underlying http server timeouts (zero means no timeout)
+type Timeouts struct {
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
 }
 
 var multipleBarRegexp = regexp.MustCompile("[/]+")
@@ -36,6 +76,19 @@ var multipleBarRegexp = regexp.MustCompile("[/]+")
 // NewHTTPServer - creates a new HTTP listener server
 func NewHTTPServer(host string, port, channelSize int, responses []ResponseData) (*HTTPServer, error) {
 
+	return NewHTTPServerOn(fmt.Sprintf("%s:%d", host, port), channelSize, responses)
+}
+
+// NewHTTPServerOn - creates a new HTTP listener server bound to the address (use port 0 for a random one)
+func NewHTTPServerOn(addr string, channelSize int, responses []ResponseData) (*HTTPServer, error) {
+
+	return NewHTTPServerWithTimeouts(addr, channelSize, responses, Timeouts{})
+}
+
+// NewHTTPServerWithTimeouts - creates a new HTTP listener server bound to the address using the timeouts
+// (use a short idle timeout to test the client connection reuse)
+func NewHTTPServerWithTimeouts(addr string, channelSize int, responses []ResponseData, timeouts Timeouts) (*HTTPServer, error) {
+
 	if len(responses) == 0 {
 		return nil, fmt.Errorf("expected at least one response")
 	}
@@ -44,15 +97,32 @@ func NewHTTPServer(host string, port, channelSize int, responses []ResponseData)
 		requestChannel: make(chan *RequestData, channelSize),
 	}
 
-	hs.responseMap = map[string]ResponseData{}
+	hs.responseMap = map[string]map[string][]ResponseData{}
 	for _, response := range responses {
+		response.URI, response.Query = splitQuery(response.URI, response.Query)
 		response.URI = CleanURI(response.URI)
-		hs.responseMap[response.URI] = response
+
+		methodMap, ok := hs.responseMap[response.URI]
+		if !ok {
+			methodMap = map[string][]ResponseData{}
+			hs.responseMap[response.URI] = methodMap
+		}
+
+		for _, configured := range methodMap[response.Method] {
+			if configured.Query.Encode() == response.Query.Encode() {
+				return nil, fmt.Errorf("duplicated response for method %s and URI %s", response.Method, response.URI)
+			}
+		}
+
+		methodMap[response.Method] = addByQuerySpecificity(methodMap[response.Method], response)
 	}
 
 	hs.server = httptest.NewUnstartedServer(http.HandlerFunc(hs.handler))
+	hs.server.Config.ReadTimeout = timeouts.ReadTimeout
+	hs.server.Config.WriteTimeout = timeouts.WriteTimeout
+	hs.server.Config.IdleTimeout = timeouts.IdleTimeout
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", host, port))
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return nil, err
 	}
@@ -90,34 +160,262 @@ func (hl *HTTPServer) handler(res http.ResponseWriter, req *http.Request) {
 
 	cleanURI := CleanURI(req.RequestURI)
 
-	responseData, ok := hl.responseMap[cleanURI]
-	if !ok || responseData.Method != req.Method {
+	if !hl.authorized(req) {
+		res.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	responseData, ok := matchQuery(hl.responseMap[CleanURI(req.URL.Path)][req.Method], req.URL.Query())
+	if !ok {
 		res.WriteHeader(http.StatusNotFound)
 		return
 	}
 
+	request := hl.readRequest(req, cleanURI)
+
+	hl.runOnRequest(request)
+
+	if responseData.Validator != nil {
+		if err := responseData.Validator(request.RawBody); err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			res.Write([]byte(err.Error()))
+			hl.storeRequest(request)
+			return
+		}
+	}
+
+	if responseData.DropConnection {
+		hl.storeRequest(request)
+		hl.dropConnection(res)
+		return
+	}
+
 	combinedHeaders := res.Header()
 
 	CopyHeaders(responseData.Headers, combinedHeaders)
 	CopyHeaders(req.Header, combinedHeaders)
 
+	// the request content length does not match the response body
+	combinedHeaders.Del("Content-Length")
+
 	res.WriteHeader(responseData.Status)
 
-	if len(responseData.Body) > 0 {
-		_, err := res.Write([]byte(responseData.Body))
+	body := []byte(responseData.Body)
+	if responseData.EchoBody {
+		body = request.RawBody
+	}
+
+	if len(body) > 0 {
+		_, err := res.Write(body)
 		if err != nil {
 			fmt.Println(fmt.Errorf("error writing response body: %s", err.Error()))
 		}
 	}
 
+	if len(responseData.Chunks) > 0 {
+		hl.writeChunks(res, responseData.Chunks)
+	}
+
+	hl.storeRequest(request)
+}
+
+// RequireBasicAuth - requires the basic authentication credentials in all requests
+func (hl *HTTPServer) RequireBasicAuth(user, pass string) {
+
+	hl.mutex.Lock()
+	defer hl.mutex.Unlock()
+
+	hl.authUser = user
+	hl.authPass = pass
+	hl.requireAuth = true
+}
+
+// authorized - checks the request basic authentication credentials, if required
+func (hl *HTTPServer) authorized(req *http.Request) bool {
+
+	hl.mutex.RLock()
+	defer hl.mutex.RUnlock()
+
+	if !hl.requireAuth {
+		return true
+	}
+
+	user, pass, ok := req.BasicAuth()
+
+	return ok && user == hl.authUser && pass == hl.authPass
+}
+
+// storeRequest - records the request and sends it to the request channel (if the channel is full, the request is only recorded)
+func (hl *HTTPServer) storeRequest(request *RequestData) {
+
+	hl.mutex.Lock()
+	if hl.maxRequests > 0 && len(hl.requests) == hl.maxRequests {
+		hl.requests[hl.oldestRequest] = request
+		hl.oldestRequest = (hl.oldestRequest + 1) % hl.maxRequests
+	} else {
+		hl.requests = append(hl.requests, request)
+	}
+	hl.mutex.Unlock()
+
+	select {
+	case hl.requestChannel <- request:
+	default:
+		fmt.Println(fmt.Errorf("request channel is full, the request was only recorded: %s %s", request.Method, request.URI))
+	}
+}
+
+// Requests - returns a copy of all the retained requests in the arrival order
+func (hl *HTTPServer) Requests() []*RequestData {
+
+	hl.mutex.RLock()
+	defer hl.mutex.RUnlock()
+
+	return hl.orderedRequests()
+}
+
+// orderedRequests - returns a copy of the retained requests from the oldest to the newest (must be called locked)
+func (hl *HTTPServer) orderedRequests() []*RequestData {
+
+	requests := make([]*RequestData, 0, len(hl.requests))
+	requests = append(requests, hl.requests[hl.oldestRequest:]...)
+
+	return append(requests, hl.requests[:hl.oldestRequest]...)
+}
+
+// BytesReceived - returns the number of request body bytes received (as sent, without decompressing)
+func (hl *HTTPServer) BytesReceived() int64 {
+
+	return atomic.LoadInt64(&hl.bytesReceived)
+}
+
+// SetMaxCapturedRequests - limits the number of retained requests evicting the oldest ones
+// (zero keeps all requests, the already retained ones exceeding the limit are evicted)
+func (hl *HTTPServer) SetMaxCapturedRequests(max int) {
+
+	hl.mutex.Lock()
+	defer hl.mutex.Unlock()
+
+	requests := hl.orderedRequests()
+	if max > 0 && len(requests) > max {
+		requests = requests[len(requests)-max:]
+	}
+
+	hl.requests = requests
+	hl.oldestRequest = 0
+	hl.maxRequests = max
+}
+
+// readRequest - reads the request data
+func (hl *HTTPServer) readRequest(req *http.Request, cleanURI string) *RequestData {
+
 	bufferReqBody := new(bytes.Buffer)
-	bufferReqBody.ReadFrom(req.Body)
+	n, _ := bufferReqBody.ReadFrom(req.Body)
+	atomic.AddInt64(&hl.bytesReceived, n)
+
+	form, err := parseForm(req.Header.Get("Content-Type"), bufferReqBody.Bytes())
+	if err != nil {
+		fmt.Println(fmt.Errorf("error parsing the request form: %s", err.Error()))
+	}
+
+	return &RequestData{
+		URI:        cleanURI,
+		Body:       bufferReqBody.String(),
+		RawBody:    bufferReqBody.Bytes(),
+		Form:       form,
+		Headers:    req.Header,
+		Method:     req.Method,
+		RemoteAddr: req.RemoteAddr,
+		Query:      req.URL.Query(),
+	}
+}
+
+// parseForm - parses the form values when the content type indicates a form (returns null otherwise)
+func parseForm(contentType string, body []byte) (url.Values, error) {
 
-	hl.requestChannel <- &RequestData{
-		URI:     cleanURI,
-		Body:    bufferReqBody.String(),
-		Headers: req.Header,
-		Method:  req.Method,
+	if len(contentType) == 0 {
+		return nil, nil
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		return url.ParseQuery(string(body))
+	case "multipart/form-data":
+		form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)))
+		if err != nil {
+			return nil, err
+		}
+
+		defer form.RemoveAll()
+
+		return url.Values(form.Value), nil
+	default:
+		return nil, nil
+	}
+}
+
+// OnRequest - registers a callback running synchronously in the handler for each received request
+// (a null callback removes the current one)
+func (hl *HTTPServer) OnRequest(t *testing.T, callback func(t *testing.T, r *RequestData)) {
+
+	hl.mutex.Lock()
+	defer hl.mutex.Unlock()
+
+	hl.onRequestT = t
+	hl.onRequest = callback
+}
+
+// runOnRequest - runs the registered request callback, if any
+func (hl *HTTPServer) runOnRequest(request *RequestData) {
+
+	hl.mutex.RLock()
+	callback := hl.onRequest
+	t := hl.onRequestT
+	hl.mutex.RUnlock()
+
+	if callback != nil {
+		callback(t, request)
+	}
+}
+
+// dropConnection - closes the connection without writing any response
+func (hl *HTTPServer) dropConnection(res http.ResponseWriter) {
+
+	hijacker, ok := res.(http.Hijacker)
+	if !ok {
+		fmt.Println(fmt.Errorf("error dropping the connection: hijacking is not supported"))
+		return
+	}
+
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		fmt.Println(fmt.Errorf("error hijacking the connection: %s", err.Error()))
+		return
+	}
+
+	conn.Close()
+}
+
+// writeChunks - writes each chunk flushing it to the client
+func (hl *HTTPServer) writeChunks(res http.ResponseWriter, chunks [][]byte) {
+
+	flusher, ok := res.(http.Flusher)
+
+	for _, chunk := range chunks {
+		_, err := res.Write(chunk)
+		if err != nil {
+			fmt.Println(fmt.Errorf("error writing response chunk: %s", err.Error()))
+			return
+		}
+
+		if ok {
+			flusher.Flush()
+		}
 	}
 }
 
@@ -129,6 +427,18 @@ func (hl *HTTPServer) Close() {
 	}
 }
 
+// Address - returns the resolved address the server is listening
+func (hl *HTTPServer) Address() string {
+
+	return hl.server.Listener.Addr().String()
+}
+
+// URL - returns the server base URL
+func (hl *HTTPServer) URL() string {
+
+	return hl.server.URL
+}
+
 // RequestChannel - reads from the request channel
 func (hl *HTTPServer) RequestChannel() <-chan *RequestData {
 
@@ -148,6 +458,7 @@ func ParseResponse(res *http.Response) (*ResponseData, error) {
 		RequestData: RequestData{
 			URI:     res.Request.RequestURI,
 			Body:    bufferReqBody.String(),
+			RawBody: bufferReqBody.Bytes(),
 			Headers: res.Header,
 			Method:  res.Request.Method,
 		},
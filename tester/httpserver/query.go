@@ -0,0 +1,86 @@
+package httpserver
+
+import (
+	"net/url"
+	"strings"
+)
+
+// splitQuery - splits the query from the URI merging it with the configured query parameters
+func splitQuery(uri string, query url.Values) (string, url.Values) {
+
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return uri, query
+	}
+
+	parsed, err := url.ParseQuery(uri[i+1:])
+	if err != nil {
+		return uri, query
+	}
+
+	for k, values := range query {
+		parsed[k] = append(parsed[k], values...)
+	}
+
+	return uri[:i], parsed
+}
+
+// addByQuerySpecificity - adds the response keeping the ones requiring more query parameters first
+func addByQuerySpecificity(responses []ResponseData, response ResponseData) []ResponseData {
+
+	i := 0
+	for i < len(responses) && len(responses[i].Query) >= len(response.Query) {
+		i++
+	}
+
+	responses = append(responses, ResponseData{})
+	copy(responses[i+1:], responses[i:])
+	responses[i] = response
+
+	return responses
+}
+
+// matchQuery - returns the first response having all its query parameters in the request query
+// (a parameter with an empty value only requires the parameter presence)
+func matchQuery(responses []ResponseData, query url.Values) (ResponseData, bool) {
+
+	for _, response := range responses {
+		if hasQuery(query, response.Query) {
+			return response, true
+		}
+	}
+
+	return ResponseData{}, false
+}
+
+// hasQuery - checks if the query has all the required parameters and values
+func hasQuery(query, required url.Values) bool {
+
+	for k, values := range required {
+
+		actual, ok := query[k]
+		if !ok {
+			return false
+		}
+
+		for _, v := range values {
+			if len(v) > 0 && !contains(actual, v) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// contains - checks if the value is in the list
+func contains(list []string, value string) bool {
+
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -30,12 +30,28 @@ func CreateNewTestHTTPServer(responses []ResponseData) *HTTPServer {
 	return s
 }
 
+// CreateNewTestHTTPServerOn - creates a new server bound to the address (use port 0 for a random one)
+func CreateNewTestHTTPServerOn(addr string, responses []ResponseData) *HTTPServer {
+
+	s, err := NewHTTPServerOn(addr, 5, responses)
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
+
 // DoRequest - does a request
 func DoRequest(request *RequestData) *ResponseData {
 
 	client := util.CreateHTTPClient(time.Second, true)
 
-	req, err := http.NewRequest(request.Method, fmt.Sprintf("http://%s:%d/%s", TestServerHost, TestServerPort, request.URI), bytes.NewBuffer([]byte(request.Body)))
+	body := request.RawBody
+	if len(body) == 0 {
+		body = []byte(request.Body)
+	}
+
+	req, err := http.NewRequest(request.Method, fmt.Sprintf("http://%s:%d/%s", TestServerHost, TestServerPort, request.URI), bytes.NewBuffer(body))
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,53 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// BodyValidator - validates the request body (the server responds 400 when an error is returned)
+type BodyValidator func(body []byte) error
+
+// jsonPoint - the expected point structure (pointers to detect the missing fields)
+type jsonPoint struct {
+	Metric    *string           `json:"metric"`
+	Tags      map[string]string `json:"tags"`
+	Timestamp *int64            `json:"timestamp"`
+	Value     *float64          `json:"value"`
+}
+
+// NumberPointsValidator - validates a JSON array of number points
+func NumberPointsValidator(body []byte) error {
+
+	var points []jsonPoint
+
+	err := json.Unmarshal(body, &points)
+	if err != nil {
+		return fmt.Errorf("malformed point array: %s", err.Error())
+	}
+
+	if len(points) == 0 {
+		return fmt.Errorf("no points found")
+	}
+
+	for i, p := range points {
+
+		if p.Metric == nil || len(*p.Metric) == 0 {
+			return fmt.Errorf("point %d has no metric", i)
+		}
+
+		if len(p.Tags) == 0 {
+			return fmt.Errorf("point %d has no tags", i)
+		}
+
+		if p.Timestamp == nil {
+			return fmt.Errorf("point %d has no timestamp", i)
+		}
+
+		if p.Value == nil {
+			return fmt.Errorf("point %d has no value", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,267 @@
+package timelinetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/uol/gobol/timeline"
+	jsonSerializer "github.com/uol/serializer/json"
+	openTSDBSerializer "github.com/uol/serializer/opentsdb"
+)
+
+// defaultBufferSize - the default number of points buffered before being recorded
+const defaultBufferSize int = 1024
+
+// waitCheckInterval - the interval checking the number of recorded points
+const waitCheckInterval time.Duration = 5 * time.Millisecond
+
+// Point - a point recorded by the fake backend
+// (the parameters keep all http point parameters, the value is the number value or the text of the point)
+type Point struct {
+	Metric     string
+	Tags       map[string]string
+	Timestamp  int64
+	Value      interface{}
+	Parameters map[string]interface{}
+}
+
+// FakeBackend - an in memory transport recording all points sent by a timeline manager
+// (the wrapped transport is only used to serialize and flatten the points, it is never started)
+type FakeBackend struct {
+	timeline.Transport
+	dataChannel chan interface{}
+	points      []Point
+	done        chan struct{}
+	mutex       sync.RWMutex
+}
+
+// NewFakeBackend - creates a new fake backend wrapping the transport (the http transport must have the json
+// mappings of the sent points, the timestamp and value properties are read from the "timestamp" and "value" parameters)
+func NewFakeBackend(transport timeline.Transport) (*FakeBackend, error) {
+
+	if transport == nil {
+		return nil, fmt.Errorf("transport implementation is required")
+	}
+
+	return &FakeBackend{
+		Transport:   transport,
+		dataChannel: make(chan interface{}, defaultBufferSize),
+		points:      []Point{},
+	}, nil
+}
+
+// NewManager - creates a timeline manager sending to this fake backend
+func (b *FakeBackend) NewManager() (*timeline.Manager, error) {
+
+	return timeline.NewManager(b, &timeline.Backend{})
+}
+
+// DataChannel - returns the channel receiving the points
+func (b *FakeBackend) DataChannel() chan<- interface{} {
+
+	return b.dataChannel
+}
+
+// ConfigureBackend - does nothing (there is no backend)
+func (b *FakeBackend) ConfigureBackend(backend *timeline.Backend) error {
+
+	return nil
+}
+
+// TransferData - records the points
+func (b *FakeBackend) TransferData(ctx context.Context, dataList []interface{}) error {
+
+	points := make([]Point, 0, len(dataList))
+
+	for _, item := range dataList {
+
+		point, err := toPoint(item)
+		if err != nil {
+			return err
+		}
+
+		points = append(points, point)
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.points = append(b.points, points...)
+
+	return nil
+}
+
+// Start - starts recording the points sent to the data channel
+func (b *FakeBackend) Start() error {
+
+	b.done = make(chan struct{})
+
+	go func() {
+		defer close(b.done)
+
+		for item := range b.dataChannel {
+			b.TransferData(context.Background(), []interface{}{item})
+		}
+	}()
+
+	return nil
+}
+
+// Close - stops receiving points, waiting the buffered ones to be recorded
+func (b *FakeBackend) Close() error {
+
+	close(b.dataChannel)
+
+	if b.done != nil {
+		<-b.done
+	}
+
+	return nil
+}
+
+// Name - returns the transport name
+func (b *FakeBackend) Name() string {
+
+	return "fake"
+}
+
+// Points - returns all recorded points
+func (b *FakeBackend) Points() []Point {
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return append([]Point{}, b.points...)
+}
+
+// PointsForMetric - returns the recorded points of the metric
+func (b *FakeBackend) PointsForMetric(metric string) []Point {
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	points := []Point{}
+	for _, p := range b.points {
+		if p.Metric == metric {
+			points = append(points, p)
+		}
+	}
+
+	return points
+}
+
+// Len - returns the number of recorded points
+func (b *FakeBackend) Len() int {
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return len(b.points)
+}
+
+// Reset - removes all recorded points
+func (b *FakeBackend) Reset() {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.points = []Point{}
+}
+
+// WaitForPoints - waits until the number of recorded points reaches the expected one (returns false on timeout)
+func (b *FakeBackend) WaitForPoints(expected int, timeout time.Duration) bool {
+
+	deadline := time.Now().Add(timeout)
+
+	for b.Len() < expected {
+		if time.Now().After(deadline) {
+			return false
+		}
+
+		<-time.After(waitCheckInterval)
+	}
+
+	return true
+}
+
+// toPoint - converts the data channel item to the recorded point
+func toPoint(item interface{}) (Point, error) {
+
+	switch v := item.(type) {
+	case jsonSerializer.ArrayItem:
+		return fromHTTPItem(v), nil
+	case openTSDBSerializer.ArrayItem:
+		return fromOpenTSDBItem(v), nil
+	default:
+		return Point{}, fmt.Errorf("unsupported point type: %T", item)
+	}
+}
+
+// fromHTTPItem - converts the http item parameters to the recorded point
+func fromHTTPItem(item jsonSerializer.ArrayItem) Point {
+
+	point := Point{
+		Parameters: map[string]interface{}{},
+	}
+
+	for i := 0; i+1 < len(item.Parameters); i += 2 {
+
+		key, ok := item.Parameters[i].(string)
+		if !ok {
+			continue
+		}
+
+		value := item.Parameters[i+1]
+		point.Parameters[key] = value
+
+		switch key {
+		case "metric":
+			point.Metric, _ = value.(string)
+		case "tags":
+			point.Tags, _ = value.(map[string]string)
+		case "timestamp":
+			point.Timestamp = toInt64(value)
+		case "value", "text":
+			point.Value = value
+		}
+	}
+
+	return point
+}
+
+// fromOpenTSDBItem - converts the opentsdb item to the recorded point
+func fromOpenTSDBItem(item openTSDBSerializer.ArrayItem) Point {
+
+	tags := make(map[string]string, len(item.Tags)/2)
+	for i := 0; i+1 < len(item.Tags); i += 2 {
+		tags[fmt.Sprint(item.Tags[i])] = fmt.Sprint(item.Tags[i+1])
+	}
+
+	return Point{
+		Metric:    item.Metric,
+		Tags:      tags,
+		Timestamp: item.Timestamp,
+		Value:     item.Value,
+	}
+}
+
+// toInt64 - converts the integer types to int64
+func toInt64(value interface{}) int64 {
+
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint64:
+		return int64(v)
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,80 @@
+package structs_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+)
+
+/**
+* The point copy and comparison tests.
+**/
+
+// TestNumberPointClone - tests if the number point clone is a deep copy
+func TestNumberPointClone(t *testing.T) {
+
+	original := structs.NewNumberPoint("metric", 1.5, map[string]string{"host": "a", "ttl": "1"})
+	original.Aggregator = "sum"
+	original.Interval = "1m"
+
+	clone := original.Clone()
+
+	if !assert.True(t, original.Equal(clone), "expected the clone to be equal") {
+		return
+	}
+
+	clone.Tags["host"] = "b"
+	clone.Tags["new"] = "tag"
+	clone.Value = 2
+
+	assert.Equal(t, map[string]string{"host": "a", "ttl": "1"}, original.Tags, "expected the original tags unchanged")
+	assert.Equal(t, 1.5, original.Value, "expected the original value unchanged")
+	assert.False(t, original.Equal(clone), "expected the modified clone to differ")
+}
+
+// TestTextPointClone - tests if the text point clone is a deep copy
+func TestTextPointClone(t *testing.T) {
+
+	original := &structs.TextPoint{
+		Point: structs.Point{
+			Metric:    "metric",
+			Tags:      map[string]string{"host": "a"},
+			Timestamp: 1,
+		},
+		Text: "text",
+	}
+
+	clone := original.Clone()
+
+	if !assert.True(t, original.Equal(clone), "expected the clone to be equal") {
+		return
+	}
+
+	delete(clone.Tags, "host")
+
+	assert.Equal(t, map[string]string{"host": "a"}, original.Tags, "expected the original tags unchanged")
+	assert.False(t, original.Equal(clone), "expected the modified clone to differ")
+}
+
+// TestPointEqual - tests the point comparison
+func TestPointEqual(t *testing.T) {
+
+	a := &structs.NumberPoint{Point: structs.Point{Metric: "m", Timestamp: 1}, Value: 1}
+	b := &structs.NumberPoint{Point: structs.Point{Metric: "m", Tags: map[string]string{}, Timestamp: 1}, Value: 1}
+
+	assert.True(t, a.Equal(b), "expected a nil and an empty tag map to be equal")
+
+	b.Tags["k"] = "v"
+	assert.False(t, a.Equal(b), "expected different tags to differ")
+
+	b = a.Clone()
+	b.Timestamp = 2
+	assert.False(t, a.Equal(b), "expected different timestamps to differ")
+
+	var nilPoint *structs.NumberPoint
+	assert.True(t, nilPoint.Equal(nil), "expected nil points to be equal")
+	assert.False(t, a.Equal(nil), "expected a nil point to differ")
+	assert.Nil(t, nilPoint.Clone(), "expected a nil clone")
+}
@@ -1,10 +1,22 @@
 package testerhttpserver_test
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"mime/multipart"
+	"net"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -198,3 +210,620 @@ func containsHeaders(t *testing.T, mustExist, fullSet http.Header) bool {
 
 	return true
 }
+
+// TestChunks - tests if the chunks are streamed to the client in order
+func TestChunks(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Body = ""
+	configuredResponse.Chunks = [][]byte{[]byte("first,"), []byte("second,"), []byte("third")}
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	res, err := http.Get(fmt.Sprintf("http://%s:%d/test", httpserver.TestServerHost, httpserver.TestServerPort))
+	if !assert.NoError(t, err, "expected no error doing the request") {
+		return
+	}
+
+	defer res.Body.Close()
+
+	assert.Equal(t, []string{"chunked"}, res.TransferEncoding, "expected a chunked response")
+
+	body, err := ioutil.ReadAll(res.Body)
+	if !assert.NoError(t, err, "expected no error reading the body") {
+		return
+	}
+
+	assert.Equal(t, "first,second,third", string(body), "expected all chunks in order")
+}
+
+// TestDropConnection - tests if the dropped connection is a retryable failure for the client
+func TestDropConnection(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+	configuredResponse.DropConnection = true
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	res, err := http.Post(fmt.Sprintf("http://%s:%d/test", httpserver.TestServerHost, httpserver.TestServerPort), "text/plain", strings.NewReader("dropped body"))
+	if res != nil {
+		res.Body.Close()
+	}
+
+	if !assert.Error(t, err, "expected a connection error") {
+		return
+	}
+
+	assert.True(t, timeline.DefaultRetryClassifier(0, err), "expected a retryable error")
+
+	serverRequest := httpserver.WaitForHTTPServerRequest(server)
+	if assert.NotNil(t, serverRequest, "expected the request to be received") {
+		assert.Equal(t, "dropped body", serverRequest.Body, "expected the same body")
+	}
+}
+
+// TestRawBody - tests if the binary request body is captured exactly
+func TestRawBody(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	b := bytes.Buffer{}
+	writer := gzip.NewWriter(&b)
+	writer.Write([]byte(`[{"metric": "test-metric", "value": 1.0}]`))
+	if !assert.NoError(t, writer.Close(), "expected no error compressing") {
+		return
+	}
+
+	clientRequest := &httpserver.RequestData{
+		URI:     "/test",
+		RawBody: b.Bytes(),
+		Method:  "POST",
+	}
+
+	serverResponse := httpserver.DoRequest(clientRequest)
+	if !compareResponses(t, &configuredResponse, serverResponse) {
+		return
+	}
+
+	serverRequest := httpserver.WaitForHTTPServerRequest(server)
+	if !assert.NotNil(t, serverRequest, "expected the request to be received") {
+		return
+	}
+
+	assert.Equal(t, b.Bytes(), serverRequest.RawBody, "expected the exact same bytes")
+
+	reader, err := gzip.NewReader(bytes.NewReader(serverRequest.RawBody))
+	if !assert.NoError(t, err, "expected no error creating the gzip reader") {
+		return
+	}
+
+	uncompressed, err := ioutil.ReadAll(reader)
+	if !assert.NoError(t, err, "expected no error decompressing") {
+		return
+	}
+
+	assert.Equal(t, `[{"metric": "test-metric", "value": 1.0}]`, string(uncompressed), "expected the same payload")
+}
+
+// TestResponsesByMethod - tests different responses registered to the same URI with different methods
+func TestResponsesByMethod(t *testing.T) {
+
+	getResponse := createDummyResponse()
+	getResponse.URI = "/api/put"
+	getResponse.Body = "healthy"
+
+	putResponse := createDummyResponse()
+	putResponse.URI = "/api/put"
+	putResponse.Method = "PUT"
+	putResponse.Status = http.StatusCreated
+	putResponse.Body = ""
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{getResponse, putResponse})
+	defer server.Close()
+
+	serverResponse := httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/api/put",
+		Method: "GET",
+	})
+
+	if !compareResponses(t, &getResponse, serverResponse) {
+		return
+	}
+
+	httpserver.WaitForHTTPServerRequest(server)
+
+	serverResponse = httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/api/put",
+		Body:   `{"metric": "test-metric", "value": 1.0}`,
+		Method: "PUT",
+	})
+
+	if !compareResponses(t, &putResponse, serverResponse) {
+		return
+	}
+
+	serverRequest := httpserver.WaitForHTTPServerRequest(server)
+	compareRequests(t, &httpserver.RequestData{URI: "/api/put", Body: `{"metric": "test-metric", "value": 1.0}`, Method: "PUT"}, serverRequest)
+
+	serverResponse = httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/api/put",
+		Method: "POST",
+	})
+
+	assert.Equal(t, http.StatusNotFound, serverResponse.Status, "expected 404 status")
+}
+
+// TestDuplicatedResponse - tests when the same method and URI are registered twice
+func TestDuplicatedResponse(t *testing.T) {
+
+	_, err := httpserver.NewHTTPServer("localhost", 18080, 5, []httpserver.ResponseData{createDummyResponse(), createDummyResponse()})
+
+	assert.Error(t, err, "expected an error")
+}
+
+// TestOnRequest - tests the request callback running inside the handler
+func TestOnRequest(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	called := make(chan struct{}, 1)
+
+	server.OnRequest(t, func(t *testing.T, r *httpserver.RequestData) {
+		assert.Equal(t, "application/json", r.Headers.Get("Content-Type"), "expected the json content type")
+		assert.Equal(t, "/test", r.URI, "expected the same URI")
+		called <- struct{}{}
+	})
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/json")
+
+	httpserver.DoRequest(&httpserver.RequestData{
+		URI:     "/test",
+		Body:    `{"metric": "test-metric", "value": 1.0}`,
+		Method:  "POST",
+		Headers: headers,
+	})
+
+	select {
+	case <-called:
+	default:
+		assert.Fail(t, "expected the callback to run before the response")
+	}
+
+	httpserver.WaitForHTTPServerRequest(server)
+}
+
+// TestRequireBasicAuth - tests the basic authentication verification
+func TestRequireBasicAuth(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "PUT"
+	configuredResponse.Status = http.StatusCreated
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	server.RequireBasicAuth("user", "pass")
+
+	response := httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/test",
+		Method: "PUT",
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, response.Status, "expected 401 status without credentials")
+
+	wrongHeaders := http.Header{}
+	wrongHeaders.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("user:wrong")))
+
+	response = httpserver.DoRequest(&httpserver.RequestData{
+		URI:     "/test",
+		Method:  "PUT",
+		Headers: wrongHeaders,
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, response.Status, "expected 401 status with the wrong credentials")
+
+	headers := http.Header{}
+	headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("user:pass")))
+
+	response = httpserver.DoRequest(&httpserver.RequestData{
+		URI:     "/test",
+		Method:  "PUT",
+		Headers: headers,
+	})
+
+	assert.Equal(t, http.StatusCreated, response.Status, "expected 201 status with the credentials")
+
+	httpserver.WaitForHTTPServerRequest(server)
+}
+
+// TestForm - tests the form encoded body parsing
+func TestForm(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	values := url.Values{}
+	values.Set("metric", "test-metric")
+	values.Add("tag", "host=localhost")
+	values.Add("tag", "ttl=1")
+
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	httpserver.DoRequest(&httpserver.RequestData{
+		URI:     "/test",
+		Body:    values.Encode(),
+		Method:  "POST",
+		Headers: headers,
+	})
+
+	serverRequest := httpserver.WaitForHTTPServerRequest(server)
+	if !assert.NotNil(t, serverRequest, "expected the request to be received") {
+		return
+	}
+
+	assert.Equal(t, values, serverRequest.Form, "expected the same form values")
+	assert.Equal(t, values.Encode(), serverRequest.Body, "expected the raw body")
+}
+
+// TestMultipartForm - tests the multipart body parsing
+func TestMultipartForm(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	b := bytes.Buffer{}
+	writer := multipart.NewWriter(&b)
+	writer.WriteField("metric", "test-metric")
+	writer.WriteField("value", "1.0")
+	if !assert.NoError(t, writer.Close(), "expected no error writing the multipart body") {
+		return
+	}
+
+	headers := http.Header{}
+	headers.Set("Content-Type", writer.FormDataContentType())
+
+	httpserver.DoRequest(&httpserver.RequestData{
+		URI:     "/test",
+		RawBody: b.Bytes(),
+		Method:  "POST",
+		Headers: headers,
+	})
+
+	serverRequest := httpserver.WaitForHTTPServerRequest(server)
+	if !assert.NotNil(t, serverRequest, "expected the request to be received") {
+		return
+	}
+
+	assert.Equal(t, url.Values{"metric": {"test-metric"}, "value": {"1.0"}}, serverRequest.Form, "expected the same form values")
+}
+
+// TestEchoBody - tests if the request body is echoed in the response
+func TestEchoBody(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+	configuredResponse.EchoBody = true
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	payload := `{"metric": "test-metric", "value": 1.0}`
+
+	serverResponse := httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/test",
+		Body:   payload,
+		Method: "POST",
+	})
+
+	assert.Equal(t, http.StatusOK, serverResponse.Status, "expected 200 status")
+	assert.Equal(t, payload, serverResponse.Body, "expected the request body echoed")
+
+	httpserver.WaitForHTTPServerRequest(server)
+}
+
+// TestValidator - tests the request body validation
+func TestValidator(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "PUT"
+	configuredResponse.Status = http.StatusCreated
+	configuredResponse.Body = ""
+	configuredResponse.Validator = httpserver.NumberPointsValidator
+
+	server := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{configuredResponse})
+	defer server.Close()
+
+	bodies := []string{
+		`{"metric": "test-metric"`,
+		`[{"tags": {"host": "localhost"}, "timestamp": 1, "value": 1.0}]`,
+		`[{"metric": "test-metric", "timestamp": 1, "value": 1.0}]`,
+		`[{"metric": "test-metric", "tags": {"host": "localhost"}, "value": 1.0}]`,
+		`[{"metric": "test-metric", "tags": {"host": "localhost"}, "timestamp": 1}]`,
+		`[]`,
+	}
+
+	for _, body := range bodies {
+		response := httpserver.DoRequest(&httpserver.RequestData{
+			URI:    "/test",
+			Body:   body,
+			Method: "PUT",
+		})
+
+		assert.Equalf(t, http.StatusBadRequest, response.Status, "expected 400 status for the body: %s", body)
+		assert.NotEmpty(t, response.Body, "expected the validation error")
+
+		httpserver.WaitForHTTPServerRequest(server)
+	}
+
+	response := httpserver.DoRequest(&httpserver.RequestData{
+		URI:    "/test",
+		Body:   `[{"metric": "test-metric", "tags": {"host": "localhost"}, "timestamp": 1, "value": 1.0}]`,
+		Method: "PUT",
+	})
+
+	assert.Equal(t, http.StatusCreated, response.Status, "expected 201 status")
+
+	httpserver.WaitForHTTPServerRequest(server)
+}
+
+// TestServerOnAddress - tests the server bound to a custom address
+func TestServerOnAddress(t *testing.T) {
+
+	server := httpserver.CreateNewTestHTTPServerOn("127.0.0.1:0", []httpserver.ResponseData{createDummyResponse()})
+	defer server.Close()
+
+	host, port, err := net.SplitHostPort(server.Address())
+	if !assert.NoError(t, err, "expected a valid address") {
+		return
+	}
+
+	assert.Equal(t, "127.0.0.1", host, "expected the same host")
+	assert.NotEqual(t, "0", port, "expected a resolved port")
+	assert.Equal(t, "http://"+server.Address(), server.URL(), "expected the address in the url")
+
+	res, err := http.Get(server.URL() + "/test")
+	if !assert.NoError(t, err, "expected no error doing the request") {
+		return
+	}
+
+	defer res.Body.Close()
+
+	serverResponse, err := httpserver.ParseResponse(res)
+	if !assert.NoError(t, err, "expected no error parsing the response") {
+		return
+	}
+
+	assert.Equal(t, http.StatusOK, serverResponse.Status, "expected 200 status")
+	assert.Equal(t, "test body", serverResponse.Body, "expected the same body")
+
+	httpserver.WaitForHTTPServerRequest(server)
+}
+
+// TestConcurrentRequests - tests if all concurrent requests are captured (run with -race)
+func TestConcurrentRequests(t *testing.T) {
+
+	numRequests := 50
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server, err := httpserver.NewHTTPServerOn("127.0.0.1:0", numRequests, []httpserver.ResponseData{configuredResponse})
+	if !assert.NoError(t, err, "expected no error creating the server") {
+		return
+	}
+
+	defer server.Close()
+
+	wg := sync.WaitGroup{}
+	wg.Add(numRequests)
+
+	for i := 0; i < numRequests; i++ {
+		go func(i int) {
+			defer wg.Done()
+
+			res, err := http.Post(server.URL()+"/test", "text/plain", strings.NewReader(strconv.Itoa(i)))
+			if assert.NoError(t, err, "expected no error doing the request") {
+				res.Body.Close()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	requests := server.Requests()
+	if !assert.Len(t, requests, numRequests, "expected all requests captured") {
+		return
+	}
+
+	bodies := map[string]bool{}
+	for _, r := range requests {
+		bodies[r.Body] = true
+	}
+
+	assert.Len(t, bodies, numRequests, "expected all request bodies")
+	assert.Len(t, server.RequestChannel(), numRequests, "expected all requests in the channel")
+}
+
+// TestMaxCapturedRequests - tests if the oldest requests are evicted past the max captured requests
+func TestMaxCapturedRequests(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server, err := httpserver.NewHTTPServerOn("127.0.0.1:0", 10, []httpserver.ResponseData{configuredResponse})
+	if !assert.NoError(t, err, "expected no error creating the server") {
+		return
+	}
+
+	defer server.Close()
+
+	post := func(i int) {
+		res, err := http.Post(server.URL()+"/test", "text/plain", strings.NewReader(strconv.Itoa(i)))
+		if assert.NoError(t, err, "expected no error doing the request") {
+			res.Body.Close()
+		}
+	}
+
+	bodies := func() []string {
+		result := []string{}
+		for _, r := range server.Requests() {
+			result = append(result, r.Body)
+		}
+		return result
+	}
+
+	for i := 0; i < 4; i++ {
+		post(i)
+	}
+
+	server.SetMaxCapturedRequests(3)
+
+	assert.Equal(t, []string{"1", "2", "3"}, bodies(), "expected the oldest request evicted when the limit is set")
+
+	for i := 4; i < 9; i++ {
+		post(i)
+	}
+
+	assert.Equal(t, []string{"6", "7", "8"}, bodies(), "expected only the newest requests retained")
+
+	server.SetMaxCapturedRequests(0)
+	post(9)
+
+	assert.Equal(t, []string{"6", "7", "8", "9"}, bodies(), "expected no eviction without the limit")
+}
+
+// TestBytesReceived - tests if the received bytes are the request body sizes as sent (compressed or not)
+func TestBytesReceived(t *testing.T) {
+
+	configuredResponse := createDummyResponse()
+	configuredResponse.Method = "POST"
+
+	server, err := httpserver.NewHTTPServerOn("127.0.0.1:0", 10, []httpserver.ResponseData{configuredResponse})
+	if !assert.NoError(t, err, "expected no error creating the server") {
+		return
+	}
+
+	defer server.Close()
+
+	payload := []byte(strings.Repeat(`{"metric": "test-metric", "value": 1.0}`, 100))
+
+	post := func(body []byte, encoding string) {
+		req, err := http.NewRequest("POST", server.URL()+"/test", bytes.NewReader(body))
+		if !assert.NoError(t, err, "expected no error creating the request") {
+			return
+		}
+
+		if len(encoding) > 0 {
+			req.Header.Set("Content-Encoding", encoding)
+		}
+
+		res, err := http.DefaultClient.Do(req)
+		if assert.NoError(t, err, "expected no error doing the request") {
+			res.Body.Close()
+		}
+	}
+
+	assert.Equal(t, int64(0), server.BytesReceived(), "expected no bytes before any request")
+
+	post(payload, "")
+
+	plain := server.BytesReceived()
+	assert.Equal(t, int64(len(payload)), plain, "expected the plain body size")
+
+	compressed := bytes.Buffer{}
+	gw := gzip.NewWriter(&compressed)
+	gw.Write(payload)
+	gw.Close()
+
+	post(compressed.Bytes(), "gzip")
+
+	gzipped := server.BytesReceived() - plain
+	assert.Equal(t, int64(compressed.Len()), gzipped, "expected the compressed body size")
+	assert.Less(t, gzipped, plain, "expected less bytes received with gzip")
+}
+
+// TestQueryMatching - tests if the responses requiring query parameters only match the requests having them
+func TestQueryMatching(t *testing.T) {
+
+	plain := createDummyResponse()
+	plain.URI = "/api/put"
+	plain.Method = "POST"
+	plain.Status = http.StatusNoContent
+	plain.Body = ""
+
+	details := createDummyResponse()
+	details.URI = "/api/put?details"
+	details.Method = "POST"
+	details.Status = http.StatusOK
+	details.Body = `{"success": 1, "failed": 0, "errors": []}`
+
+	tenant := createDummyResponse()
+	tenant.URI = "/api/put"
+	tenant.Query = url.Values{"tenant": []string{"x"}}
+	tenant.Method = "POST"
+	tenant.Status = http.StatusAccepted
+	tenant.Body = ""
+
+	server, err := httpserver.NewHTTPServerOn("127.0.0.1:0", 10, []httpserver.ResponseData{plain, details, tenant})
+	if !assert.NoError(t, err, "expected no error creating the server") {
+		return
+	}
+
+	defer server.Close()
+
+	post := func(uri string) (int, string) {
+		res, err := http.Post(server.URL()+uri, "application/json", strings.NewReader("[]"))
+		if !assert.NoError(t, err, "expected no error doing the request") {
+			return 0, ""
+		}
+
+		defer res.Body.Close()
+
+		body, err := ioutil.ReadAll(res.Body)
+		assert.NoError(t, err, "expected no error reading the body")
+
+		return res.StatusCode, string(body)
+	}
+
+	status, body := post("/api/put?details")
+	assert.Equal(t, http.StatusOK, status, "expected the details response")
+	assert.Equal(t, details.Body, body, "expected the details body")
+
+	status, _ = post("/api/put")
+	assert.Equal(t, http.StatusNoContent, status, "expected the plain response")
+
+	status, _ = post("/api/put?tenant=x")
+	assert.Equal(t, http.StatusAccepted, status, "expected the tenant response")
+
+	status, _ = post("/api/put?tenant=y")
+	assert.Equal(t, http.StatusNoContent, status, "expected the plain response with another tenant")
+
+	requests := server.Requests()
+	if assert.Len(t, requests, 4, "expected all requests captured") {
+		_, ok := requests[0].Query["details"]
+		assert.True(t, ok, "expected the captured query")
+		assert.Equal(t, "x", requests[2].Query.Get("tenant"), "expected the captured tenant")
+	}
+
+	_, err = httpserver.NewHTTPServerOn("127.0.0.1:0", 10, []httpserver.ResponseData{details, details})
+	assert.Error(t, err, "expected an error with duplicated query responses")
+}
@@ -0,0 +1,213 @@
+package timelinetest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/hashing"
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/timelinetest"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline fake backend tests.
+**/
+
+const numberPoint = "numberJSON"
+
+// defaultTransportConfig - creates a valid default transport configuration
+func defaultTransportConfig() timeline.DefaultTransportConfiguration {
+
+	return timeline.DefaultTransportConfiguration{
+		RequestTimeout:       time.Second,
+		BatchSendInterval:    time.Second,
+		TransportBufferSize:  1024,
+		SerializerBufferSize: 5,
+	}
+}
+
+// createHTTPFakeBackend - creates a fake backend wrapping a http transport with the number point mapping
+func createHTTPFakeBackend(t *testing.T) *timelinetest.FakeBackend {
+
+	transport, err := timeline.NewHTTPTransport(&timeline.HTTPTransportConfig{
+		DefaultTransportConfiguration: defaultTransportConfig(),
+		ServiceEndpoint:               "/api/put",
+		Method:                        "PUT",
+		ExpectedResponseStatus:        201,
+		TimestampProperty:             "timestamp",
+		ValueProperty:                 "value",
+	})
+	if !assert.NoError(t, err, "no error expected creating the transport") {
+		return nil
+	}
+
+	err = transport.AddJSONMapping(numberPoint, structs.NumberPoint{}, "metric", "value", "timestamp", "tags")
+	if !assert.NoError(t, err, "no error expected adding the mapping") {
+		return nil
+	}
+
+	backend, err := timelinetest.NewFakeBackend(transport)
+	if !assert.NoError(t, err, "no error expected creating the fake backend") {
+		return nil
+	}
+
+	return backend
+}
+
+// numberParameters - returns the http parameters of a number point
+func numberParameters(metric string, value float64, tags map[string]string) []interface{} {
+
+	return []interface{}{"metric", metric, "value", value, "timestamp", int64(1000), "tags", tags}
+}
+
+// TestFakeBackendHTTP - tests asserting the points sent using the http transport
+func TestFakeBackendHTTP(t *testing.T) {
+
+	backend := createHTTPFakeBackend(t)
+	if backend == nil {
+		return
+	}
+
+	m, err := backend.NewManager()
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	assert.NoError(t, m.SendHTTP(numberPoint, numberParameters("requests", 1, map[string]string{"host": "a"})...))
+	assert.NoError(t, m.SendHTTP(numberPoint, numberParameters("requests", 2, map[string]string{"host": "b"})...))
+	assert.NoError(t, m.SendHTTP(numberPoint, numberParameters("errors", 3, map[string]string{"host": "a"})...))
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	assert.Equal(t, 3, backend.Len(), "expected all points recorded")
+
+	requests := backend.PointsForMetric("requests")
+	if assert.Len(t, requests, 2, "expected two request points") {
+		assert.Equal(t, 1.0, requests[0].Value, "expected the first value")
+		assert.Equal(t, map[string]string{"host": "b"}, requests[1].Tags, "expected the second point tags")
+		assert.Equal(t, int64(1000), requests[1].Timestamp, "expected the point timestamp")
+	}
+
+	assert.Len(t, backend.PointsForMetric("unknown"), 0, "expected no points of an unknown metric")
+}
+
+// TestFakeBackendReset - tests waiting the points and resetting the recorded ones
+func TestFakeBackendReset(t *testing.T) {
+
+	backend := createHTTPFakeBackend(t)
+	if backend == nil {
+		return
+	}
+
+	m, err := backend.NewManager()
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	assert.NoError(t, m.SendHTTP(numberPoint, numberParameters("requests", 1, nil)...))
+	assert.NoError(t, m.SendHTTPSync(context.Background(), numberPoint, numberParameters("requests", 2, nil)...))
+
+	if !assert.True(t, backend.WaitForPoints(2, time.Second), "expected two points recorded") {
+		return
+	}
+
+	backend.Reset()
+	assert.Equal(t, 0, backend.Len(), "expected no points after the reset")
+
+	assert.NoError(t, m.SendHTTP(numberPoint, numberParameters("requests", 3, nil)...))
+	assert.True(t, backend.WaitForPoints(1, time.Second), "expected the point sent after the reset")
+}
+
+// TestFakeBackendOpenTSDB - tests asserting the points sent using the opentsdb transport
+func TestFakeBackendOpenTSDB(t *testing.T) {
+
+	transport, err := timeline.NewOpenTSDBTransport(&timeline.OpenTSDBTransportConfig{
+		DefaultTransportConfiguration: defaultTransportConfig(),
+		MaxReadTimeout:                time.Second,
+		ReconnectionTimeout:           time.Second,
+	})
+	if !assert.NoError(t, err, "no error expected creating the transport") {
+		return
+	}
+
+	backend, err := timelinetest.NewFakeBackend(transport)
+	if !assert.NoError(t, err, "no error expected creating the fake backend") {
+		return
+	}
+
+	m, err := backend.NewManager()
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	assert.NoError(t, m.SendOpenTSDB(5, 1000, "cpu", "host", "a"))
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	points := backend.PointsForMetric("cpu")
+	if assert.Len(t, points, 1, "expected the cpu point") {
+		assert.Equal(t, 5.0, points[0].Value, "expected the point value")
+		assert.Equal(t, map[string]string{"host": "a"}, points[0].Tags, "expected the point tags")
+	}
+}
+
+// TestFakeBackendFlattener - tests asserting the flattened points
+func TestFakeBackendFlattener(t *testing.T) {
+
+	backend := createHTTPFakeBackend(t)
+	if backend == nil {
+		return
+	}
+
+	flattener, err := timeline.NewFlattener(backend, &timeline.FlattenerConfig{
+		CycleDuration:    time.Minute,
+		HashingAlgorithm: hashing.SHA256,
+	})
+	if !assert.NoError(t, err, "no error expected creating the flattener") {
+		return
+	}
+
+	m, err := timeline.NewManagerF(flattener, &timeline.Backend{})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, m.FlattenHTTP(timeline.Sum, numberPoint, numberParameters("requests", float64(i), nil)...))
+	}
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	points := backend.PointsForMetric("requests")
+	if assert.Len(t, points, 1, "expected a single flattened point") {
+		assert.Equal(t, 6.0, points[0].Value, "expected the sum of the values")
+	}
+}
@@ -0,0 +1,75 @@
+package timeline_http_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline weighted multiple backends tests.
+**/
+
+// TestWeightedBackends - tests if the batches are distributed proportionally to the backend weights
+func TestWeightedBackends(t *testing.T) {
+
+	heavy := newOutageBackend()
+	defer heavy.server.Close()
+	atomic.StoreInt32(&heavy.down, 0)
+
+	light := newOutageBackend()
+	defer light.server.Close()
+	atomic.StoreInt32(&light.down, 0)
+
+	heavyBackend := serverBackend(t, heavy.server)
+	lightBackend := serverBackend(t, light.server)
+	if heavyBackend == nil || lightBackend == nil {
+		return
+	}
+
+	heavyBackend.Weight = 3
+	lightBackend.Weight = 1
+
+	m, err := timeline.NewManagerMultiBackend(createHTTPTransport(), heavyBackend, lightBackend)
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	numBatches := 400
+
+	for i := 0; i < numBatches; i++ {
+		err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		if !assert.NoError(t, err, "no error expected sending the batch") {
+			return
+		}
+	}
+
+	numHeavy := len(heavy.received())
+	numLight := len(light.received())
+
+	assert.Equal(t, numBatches, numHeavy+numLight, "expected all batches received")
+	assert.InDelta(t, 0.75, float64(numHeavy)/float64(numBatches), 0.05, "expected 3/4 of the batches in the heavier backend")
+}
+
+// TestInvalidBackends - tests the multiple backends validation
+func TestInvalidBackends(t *testing.T) {
+
+	_, err := timeline.NewManagerMultiBackend(createHTTPTransport())
+	assert.Error(t, err, "expected an error without backends")
+
+	_, err = timeline.NewManagerMultiBackend(createHTTPTransport(), &timeline.Backend{Host: "localhost", Port: 1, Weight: -1})
+	assert.Error(t, err, "expected an error with a negative weight")
+
+	_, err = timeline.NewManagerMultiBackend(createHTTPTransport(), &timeline.Backend{Host: "localhost", Port: 1}, nil)
+	assert.Error(t, err, "expected an error with a nil backend")
+}
@@ -0,0 +1,124 @@
+package timeline_http_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline batch id header tests.
+**/
+
+// uuidPattern - the generated batch id format
+var uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+// receivedHeaders - returns the values of the header received by the capture backend
+func receivedHeaders(b *captureBackend, name string) []string {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	values := []string{}
+	for _, h := range b.headers {
+		values = append(values, h.Get(name))
+	}
+
+	return values
+}
+
+// TestGeneratedBatchID - tests if a unique batch id is generated for each batch
+func TestGeneratedBatchID(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 50 * time.Millisecond
+	conf.GenerateBatchID = true
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		if !assert.NoError(t, err, "no error expected sending synchronously") {
+			return
+		}
+	}
+
+	sendValues(t, m, 3)
+
+	delivered := assert.Eventually(t, func() bool {
+		return len(receivedHeaders(b, timeline.DefaultBatchIDHeader)) == 4
+	}, 2*time.Second, 10*time.Millisecond, "expected four batches")
+
+	if !delivered {
+		return
+	}
+
+	unique := map[string]struct{}{}
+	for _, id := range receivedHeaders(b, timeline.DefaultBatchIDHeader) {
+		assert.Regexp(t, uuidPattern, id, "expected an uuid as batch id")
+		unique[id] = struct{}{}
+	}
+
+	assert.Len(t, unique, 4, "expected a different batch id per batch")
+}
+
+// TestCallerBatchID - tests if the batch id supplied by the caller is sent in the configured header
+func TestCallerBatchID(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchIDHeader = "X-Trace-Batch"
+	conf.GenerateBatchID = true
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	ctx := timeline.WithBatchID(context.Background(), "batch-1")
+
+	err := m.SendHTTPSync(ctx, numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	if !assert.NoError(t, err, "no error expected sending synchronously") {
+		return
+	}
+
+	assert.Equal(t, []string{"batch-1"}, receivedHeaders(b, "X-Trace-Batch"), "expected the caller batch id")
+}
+
+// TestNoBatchID - tests if no batch id header is sent by default
+func TestNoBatchID(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := createServerManager(t, b.server, createHTTPTransportConfig())
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	if !assert.NoError(t, err, "no error expected sending synchronously") {
+		return
+	}
+
+	assert.Equal(t, []string{""}, receivedHeaders(b, timeline.DefaultBatchIDHeader), "expected no batch id header")
+}
@@ -0,0 +1,118 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline request body format tests.
+**/
+
+// sendNumbers - sends the numbers using the specified body format and returns the request data
+func sendNumbers(t *testing.T, format timeline.BodyFormat, numbers []*structs.NumberPoint) *httpserver.RequestData {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BodyFormat = format
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	for _, number := range numbers {
+		err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+		if !assert.NoError(t, err, "no error expected when sending number") {
+			return nil
+		}
+	}
+
+	<-time.After(2 * time.Second)
+
+	return httpserver.WaitForHTTPServerRequest(s)
+}
+
+// TestJSONArrayBody - tests the json array body format
+func TestJSONArrayBody(t *testing.T) {
+
+	numbers := []*structs.NumberPoint{newNumberPoint(1), newNumberPoint(2)}
+
+	requestData := sendNumbers(t, timeline.JSONArray, numbers)
+
+	testRequestData(t, requestData, numbers, true)
+}
+
+// TestNDJSONBody - tests the newline delimited json body format
+func TestNDJSONBody(t *testing.T) {
+
+	numbers := []*structs.NumberPoint{newNumberPoint(1), newNumberPoint(2)}
+
+	requestData := sendNumbers(t, timeline.NDJSON, numbers)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	assert.Equal(t, "application/x-ndjson", requestData.Headers.Get("Content-type"), "expected application/x-ndjson as content-type header")
+	assert.False(t, strings.HasPrefix(requestData.Body, "["), "expected no json array")
+	assert.True(t, strings.HasSuffix(requestData.Body, "\n"), "expected a trailing newline")
+
+	lines := strings.Split(strings.TrimSuffix(requestData.Body, "\n"), "\n")
+	if !assert.Len(t, lines, len(numbers), "expected one line per point") {
+		return
+	}
+
+	actual := make([]structs.NumberPoint, len(lines))
+	for i, line := range lines {
+		err := json.Unmarshal([]byte(line), &actual[i])
+		if !assert.NoError(t, err, "error unmarshalling the line to number point") {
+			return
+		}
+	}
+
+	testNumberPoint(t, numbers, actual)
+}
+
+// TestInvalidBodyFormat - tests the body format validation
+func TestInvalidBodyFormat(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+	conf.BodyFormat = timeline.BodyFormat(10)
+
+	_, err := timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an error with an invalid body format")
+}
+
+// TestCustomContentType - tests the content type override
+func TestCustomContentType(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BodyFormat = timeline.NDJSON
+	conf.ContentType = "application/vnd.timeline+json"
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	if !assert.NoError(t, err, "no error expected when sending number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if assert.NotNil(t, requestData, "request data cannot be null") {
+		assert.Equal(t, "application/vnd.timeline+json", requestData.Headers.Get("Content-type"), "expected the configured content-type header")
+	}
+}
@@ -1,9 +1,17 @@
 package timeline_http_test
 
 import (
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/uol/gobol/structs"
 	"github.com/uol/gobol/tester/httpserver"
 	"github.com/uol/gobol/timeline"
@@ -37,10 +45,10 @@ const (
 	textPoint   = "textJSON"
 )
 
-// createHTTPTransport - creates the http transport
-func createHTTPTransport() *timeline.HTTPTransport {
+// createHTTPTransportConfig - creates a valid http transport configuration
+func createHTTPTransportConfig() *timeline.HTTPTransportConfig {
 
-	transportConf := timeline.HTTPTransportConfig{
+	return &timeline.HTTPTransportConfig{
 		DefaultTransportConfiguration: timeline.DefaultTransportConfiguration{
 			RequestTimeout:       time.Second,
 			BatchSendInterval:    time.Second,
@@ -53,8 +61,18 @@ func createHTTPTransport() *timeline.HTTPTransport {
 		TimestampProperty:      "timestamp",
 		ValueProperty:          "value",
 	}
+}
+
+// createHTTPTransport - creates the http transport
+func createHTTPTransport() *timeline.HTTPTransport {
 
-	transport, err := timeline.NewHTTPTransport(&transportConf)
+	return createHTTPTransportWithConfig(createHTTPTransportConfig())
+}
+
+// createHTTPTransportWithConfig - creates the http transport using the specified configuration
+func createHTTPTransportWithConfig(transportConf *timeline.HTTPTransportConfig) *timeline.HTTPTransport {
+
+	transport, err := timeline.NewHTTPTransport(transportConf)
 	if err != nil {
 		panic(err)
 	}
@@ -111,3 +129,108 @@ func newTextPoint(text string) *structs.TextPoint {
 		Text: text,
 	}
 }
+
+// captureBackend - a backend storing the received request paths, bodies and headers
+// (the request numbered failRequest, starting at one, is answered with an unavailable status and not stored)
+type captureBackend struct {
+	server      *httptest.Server
+	paths       []string
+	bodies      [][]byte
+	headers     []http.Header
+	requests    int
+	failRequest int
+	mutex       sync.Mutex
+}
+
+// newCaptureBackend - creates a new capture backend
+func newCaptureBackend() *captureBackend {
+
+	b := &captureBackend{}
+
+	b.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b.mutex.Lock()
+		b.requests++
+		if b.requests == b.failRequest {
+			b.mutex.Unlock()
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		b.paths = append(b.paths, req.URL.Path)
+		b.bodies = append(b.bodies, body)
+		b.headers = append(b.headers, req.Header.Clone())
+		b.mutex.Unlock()
+
+		res.WriteHeader(http.StatusCreated)
+	}))
+
+	return b
+}
+
+// receivedPaths - returns the paths received by the backend
+func (b *captureBackend) receivedPaths() []string {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]string{}, b.paths...)
+}
+
+// serverBackend - returns the backend pointing to the test server
+func serverBackend(t *testing.T, server *httptest.Server) *timeline.Backend {
+
+	serverURL, err := url.Parse(server.URL)
+	if !assert.NoError(t, err, "no error expected parsing the server url") {
+		return nil
+	}
+
+	port, err := strconv.Atoi(serverURL.Port())
+	if !assert.NoError(t, err, "no error expected parsing the server port") {
+		return nil
+	}
+
+	return &timeline.Backend{Host: serverURL.Hostname(), Port: port}
+}
+
+// newServerManager - creates a manager (not started) sending to the test server using the transport
+func newServerManager(t *testing.T, server *httptest.Server, transport *timeline.HTTPTransport) *timeline.Manager {
+
+	backend := serverBackend(t, server)
+	if backend == nil {
+		return nil
+	}
+
+	m, err := timeline.NewManager(transport, backend)
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return nil
+	}
+
+	return m
+}
+
+// createServerManager - creates a started manager sending to the test server using the transport configuration
+func createServerManager(t *testing.T, server *httptest.Server, conf *timeline.HTTPTransportConfig) *timeline.Manager {
+
+	return startManager(t, newServerManager(t, server, createHTTPTransportWithConfig(conf)))
+}
+
+// startManager - starts the manager (returns nil if it is nil or fails to start)
+func startManager(t *testing.T, m *timeline.Manager) *timeline.Manager {
+
+	if m == nil {
+		return nil
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return nil
+	}
+
+	return m
+}
@@ -0,0 +1,271 @@
+package timeline_http_test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/hashing"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline manager concurrency tests (run them with -race).
+**/
+
+// TestConcurrentSend - tests if all points sent by many goroutines sharing the manager arrive
+func TestConcurrentSend(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	atomic.StoreInt32(&b.down, 0)
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 20 * time.Millisecond
+	conf.TransportBufferSize = 64
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	m.SetDefaultTags(map[string]string{"service": "concurrency"})
+
+	numGoroutines := 20
+	numPoints := 50
+
+	wg := sync.WaitGroup{}
+	wg.Add(numGoroutines)
+
+	for g := 0; g < numGoroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < numPoints; i++ {
+				point := newNumberPoint(float64(g*numPoints + i))
+				assert.NoError(t, m.SendHTTP(numberPoint, toGenericParametersN(point)...), "expected no error sending")
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !assert.NoError(t, m.Drain(ctx), "expected all points drained") {
+		return
+	}
+
+	received := b.received()
+	sort.Float64s(received)
+
+	expected := make([]float64, numGoroutines*numPoints)
+	for i := range expected {
+		expected[i] = float64(i)
+	}
+
+	assert.Equal(t, expected, received, "expected all points to arrive once")
+}
+
+// TestConcurrentSendShutdown - tests if every point accepted by the goroutines sending during the shutdown arrives
+func TestConcurrentSendShutdown(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	atomic.StoreInt32(&b.down, 0)
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 10 * time.Millisecond
+	conf.TransportBufferSize = 8
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	numGoroutines := 20
+
+	accepted := make(chan float64, 100000)
+	wg := sync.WaitGroup{}
+	wg.Add(numGoroutines)
+
+	for g := 0; g < numGoroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; ; i++ {
+				value := float64(g*1000000 + i)
+				err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(value))...)
+				if err != nil {
+					assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected only the closed transport error")
+					return
+				}
+
+				accepted <- value
+			}
+		}(g)
+	}
+
+	<-time.After(50 * time.Millisecond)
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	wg.Wait()
+	close(accepted)
+
+	expected := []float64{}
+	for value := range accepted {
+		expected = append(expected, value)
+	}
+
+	sort.Float64s(expected)
+
+	received := b.received()
+	sort.Float64s(received)
+
+	assert.Equal(t, expected, received, "expected every accepted point to arrive once")
+}
+
+// testConcurrentSendDrain - tests if the drain only returns after every point sent before it arrives, while the
+// transfer loop is taking the points from the buffer
+func testConcurrentSendDrain(t *testing.T, disableBatching bool) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	atomic.StoreInt32(&b.down, 0)
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Millisecond
+	conf.DisableBatching = disableBatching
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	numGoroutines := 10
+	numPoints := 20
+
+	wg := sync.WaitGroup{}
+	wg.Add(numGoroutines)
+
+	for g := 0; g < numGoroutines; g++ {
+		go func(g int) {
+			defer wg.Done()
+
+			for i := 0; i < numPoints; i++ {
+				value := float64(g*numPoints + i)
+				if !assert.NoError(t, m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(value))...), "expected no error sending") {
+					return
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				err := m.Drain(ctx)
+				cancel()
+
+				if !assert.NoError(t, err, "expected the point drained") {
+					return
+				}
+
+				assert.Contains(t, b.received(), value, "expected the point to arrive before the drain returns")
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	assert.Len(t, b.received(), numGoroutines*numPoints, "expected all points to arrive once")
+}
+
+// TestConcurrentSendDrain - tests if the drain waits for the points taken from the buffer by the batch loop
+func TestConcurrentSendDrain(t *testing.T) {
+
+	testConcurrentSendDrain(t, false)
+}
+
+// TestConcurrentSendDrainNoBatching - tests if the drain waits for the point taken from the buffer by the point loop
+func TestConcurrentSendDrainNoBatching(t *testing.T) {
+
+	testConcurrentSendDrain(t, true)
+}
+
+// TestConcurrentFlatten - tests if all values flattened by many goroutines sharing the manager are aggregated
+func TestConcurrentFlatten(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	atomic.StoreInt32(&b.down, 0)
+
+	backend := serverBackend(t, b.server)
+	if backend == nil {
+		return
+	}
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 20 * time.Millisecond
+
+	flattener, err := timeline.NewFlattener(createHTTPTransportWithConfig(conf), &timeline.FlattenerConfig{
+		CycleDuration:    10 * time.Millisecond,
+		HashingAlgorithm: hashing.SHA256,
+	})
+	if !assert.NoError(t, err, "no error expected creating the flattener") {
+		return
+	}
+
+	m, err := timeline.NewManagerF(flattener, backend)
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	numGoroutines := 20
+	numPoints := 50
+
+	point := newNumberPoint(1)
+
+	wg := sync.WaitGroup{}
+	wg.Add(numGoroutines)
+
+	for g := 0; g < numGoroutines; g++ {
+		go func() {
+			defer wg.Done()
+
+			for i := 0; i < numPoints; i++ {
+				assert.NoError(t, m.FlattenHTTP(timeline.Sum, numberPoint, toGenericParameters(point)...), "expected no error flattening")
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	sum := 0.0
+	for _, v := range b.received() {
+		sum += v
+	}
+
+	assert.Equal(t, float64(numGoroutines*numPoints), sum, "expected all flattened values aggregated")
+}
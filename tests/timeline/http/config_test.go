@@ -0,0 +1,168 @@
+package timeline_http_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline configuration tests.
+**/
+
+// TestDurationConfiguration - tests the typed duration fields validation
+func TestDurationConfiguration(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+
+	_, err := timeline.NewHTTPTransport(conf)
+	if !assert.NoError(t, err, "no error expected with valid durations") {
+		return
+	}
+
+	conf = createHTTPTransportConfig()
+	conf.RequestTimeout = 0
+
+	_, err = timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an error with no request timeout")
+
+	conf = createHTTPTransportConfig()
+	conf.BatchSendInterval = -time.Second
+
+	_, err = timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an error with a negative batch send interval")
+
+	conf = createHTTPTransportConfig()
+	conf.RequestTimeout = 500 * time.Millisecond
+	conf.BatchSendInterval = 100 * time.Millisecond
+
+	_, err = timeline.NewHTTPTransport(conf)
+	assert.NoError(t, err, "no error expected with sub-second durations")
+}
+
+// TestMethodConfiguration - tests the http method validation
+func TestMethodConfiguration(t *testing.T) {
+
+	for _, method := range []string{"POST", "PUT", "PATCH"} {
+		conf := createHTTPTransportConfig()
+		conf.Method = method
+
+		_, err := timeline.NewHTTPTransport(conf)
+		assert.NoError(t, err, "no error expected with method: %s", method)
+	}
+
+	for _, method := range []string{"", "GET", "DELETE", "put"} {
+		conf := createHTTPTransportConfig()
+		conf.Method = method
+
+		_, err := timeline.NewHTTPTransport(conf)
+		assert.Error(t, err, "expected an error with method: %s", method)
+	}
+}
+
+// TestSendPOST - tests sending the points using the post method
+func TestSendPOST(t *testing.T) {
+
+	headers := http.Header{}
+	headers.Add("Content-type", "application/json")
+
+	s := httpserver.CreateNewTestHTTPServer([]httpserver.ResponseData{
+		{
+			RequestData: httpserver.RequestData{
+				URI:     "/api/put",
+				Method:  "POST",
+				Headers: headers,
+			},
+			Status: 201,
+		},
+	})
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.Method = "POST"
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	number := newNumberPoint(1)
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if assert.NotNil(t, requestData, "request data cannot be null") {
+		assert.Equal(t, "POST", requestData.Method, "expected POST as method")
+		testSerializeCompareNumber(t, requestData.Body, []*structs.NumberPoint{number})
+	}
+
+	assert.Equal(t, uint64(0), m.Stats().SendErrors, "expected no send errors")
+}
+
+// TestFloatPrecision - tests if the float values are rounded to the configured decimal places
+func TestFloatPrecision(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+	conf.FloatPrecision = 3
+
+	m := createTimelineManagerWithConfig(conf, false)
+
+	number := newNumberPoint(1.23456)
+
+	serialized, err := m.SerializeHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected serializing") {
+		return
+	}
+
+	assert.Contains(t, serialized, "\"value\":1.235", "expected the value rounded to 3 decimals")
+
+	conf = createHTTPTransportConfig()
+	m = createTimelineManagerWithConfig(conf, false)
+
+	serialized, err = m.SerializeHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected serializing") {
+		return
+	}
+
+	assert.Contains(t, serialized, "\"value\":1.23456", "expected the full precision by default")
+
+	conf.FloatPrecision = -1
+	_, err = timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an invalid float precision error")
+}
+
+// TestSendFloatPrecision - tests if the sent float values are rounded to the configured decimal places
+func TestSendFloatPrecision(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.FloatPrecision = 3
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1.23456))...)
+	if !assert.NoError(t, err, "no error expected sending") {
+		return
+	}
+
+	m.Shutdown()
+
+	if assert.Len(t, b.bodies, 1, "expected one request") {
+		assert.Contains(t, string(b.bodies[0]), "\"value\":1.235", "expected the value rounded to 3 decimals")
+	}
+}
@@ -0,0 +1,117 @@
+package timeline_http_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+	serializer "github.com/uol/serializer/json"
+)
+
+/**
+* The timeline batch deadline tests.
+**/
+
+const hungBackendPort = 18082
+
+// createHungBackend - creates a backend accepting connections and never responding
+func createHungBackend() net.Listener {
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", httpserver.TestServerHost, hungBackendPort))
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				buffer := make([]byte, 1024)
+				for {
+					if _, err := conn.Read(buffer); err != nil {
+						return
+					}
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+// TestTransferDataDeadline - tests a batch sent to a backend that never responds
+func TestTransferDataDeadline(t *testing.T) {
+
+	listener := createHungBackend()
+	defer listener.Close()
+
+	transport := createHTTPTransport()
+
+	err := transport.ConfigureBackend(&timeline.Backend{Host: httpserver.TestServerHost, Port: hungBackendPort})
+	if !assert.NoError(t, err, "no error expected configuring the backend") {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err = transport.TransferData(ctx, []interface{}{
+		serializer.ArrayItem{
+			Name:       numberPoint,
+			Parameters: toGenericParametersN(newNumberPoint(1)),
+		},
+	})
+
+	elapsed := time.Since(start)
+
+	assert.Error(t, err, "expected a deadline error")
+	assert.True(t, elapsed >= 300*time.Millisecond, "expected the send to wait until the context deadline")
+	assert.True(t, elapsed < time.Second, "expected the send to stop at the context deadline")
+}
+
+// TestBatchDeadline - tests if the batch loop keeps working when the backend never responds
+func TestBatchDeadline(t *testing.T) {
+
+	listener := createHungBackend()
+	defer listener.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.RequestTimeout = 300 * time.Millisecond
+	conf.BatchSendInterval = 100 * time.Millisecond
+
+	transport := createHTTPTransportWithConfig(conf)
+
+	m, err := timeline.NewManager(transport, &timeline.Backend{Host: httpserver.TestServerHost, Port: hungBackendPort})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	err = m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	for i := 0; i < 2; i++ {
+		err = m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected sending a point")
+
+		<-time.After(time.Second)
+	}
+
+	assert.Equal(t, uint64(2), m.Stats().SendErrors, "expected both batches to fail at the deadline")
+}
@@ -0,0 +1,126 @@
+package timeline_http_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline number and text endpoints tests.
+**/
+
+// createSegregatedBackend - creates a new test server simulating a timeseries backend with one endpoint per point type
+func createSegregatedBackend() *httpserver.HTTPServer {
+
+	headers := http.Header{}
+	headers.Add("Content-type", "application/json")
+
+	responses := []httpserver.ResponseData{}
+
+	for _, uri := range []string{"/api/number", "/api/text"} {
+		responses = append(responses, httpserver.ResponseData{
+			RequestData: httpserver.RequestData{
+				URI:     uri,
+				Method:  "PUT",
+				Headers: headers,
+			},
+			Status: 201,
+		})
+	}
+
+	return httpserver.CreateNewTestHTTPServer(responses)
+}
+
+// TestSegregatedEndpoints - tests if the number and text points are sent to their own endpoints
+func TestSegregatedEndpoints(t *testing.T) {
+
+	s := createSegregatedBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.ServiceEndpoint = "/api/unused"
+	conf.NumberServiceEndpoint = "/api/number"
+	conf.TextServiceEndpoint = "/api/text"
+
+	m, err := timeline.NewManager(createHTTPTransportWithConfig(conf), &timeline.Backend{Host: httpserver.TestServerHost, Port: httpserver.TestServerPort})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	err = m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	number := newNumberPoint(1)
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending the number") {
+		return
+	}
+
+	text := newTextPoint("segregated")
+
+	err = m.SendHTTP(textPoint, toGenericParametersT(text)...)
+	if !assert.NoError(t, err, "no error expected when sending the text") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requests := map[string]*httpserver.RequestData{}
+	for i := 0; i < 2; i++ {
+		requestData := httpserver.WaitForHTTPServerRequest(s)
+		if !assert.NotNil(t, requestData, "request data cannot be null") {
+			return
+		}
+
+		requests[requestData.URI] = requestData
+	}
+
+	if assert.Contains(t, requests, "/api/number", "expected a number request") {
+		testSerializeCompareNumber(t, requests["/api/number"].Body, []*structs.NumberPoint{number})
+	}
+
+	if assert.Contains(t, requests, "/api/text", "expected a text request") {
+		testSerializeCompareText(t, requests["/api/text"].Body, []*structs.TextPoint{text})
+	}
+}
+
+// TestSingleEndpointDefault - tests if the number and text points share the service endpoint by default
+func TestSingleEndpointDefault(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	if !assert.NoError(t, err, "no error expected when sending the number") {
+		return
+	}
+
+	err = m.SendHTTP(textPoint, toGenericParametersT(newTextPoint("single"))...)
+	if !assert.NoError(t, err, "no error expected when sending the text") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if assert.NotNil(t, requestData, "request data cannot be null") {
+		assert.Equal(t, "/api/put", requestData.URI, "expected the service endpoint")
+		assert.Contains(t, requestData.Body, "\"text\":\"single\"", "expected the text point in the batch")
+		assert.Contains(t, requestData.Body, "\"value\":1", "expected the number point in the batch")
+	}
+}
@@ -0,0 +1,101 @@
+package timeline_http_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+	serializer "github.com/uol/serializer/json"
+)
+
+/**
+* The timeline fallback transport tests.
+**/
+
+// recordingTransport - a transport recording all transferred data
+type recordingTransport struct {
+	*timeline.HTTPTransport
+	points []interface{}
+	mutex  sync.Mutex
+}
+
+// TransferData - records the transferred data
+func (r *recordingTransport) TransferData(ctx context.Context, dataList []interface{}) error {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.points = append(r.points, dataList...)
+
+	return nil
+}
+
+// recorded - returns the recorded data
+func (r *recordingTransport) recorded() []interface{} {
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.points
+}
+
+// TestFallbackTransport - tests if the fallback transport receives the points failed by the primary
+func TestFallbackTransport(t *testing.T) {
+
+	m, err := timeline.NewManager(createHTTPTransport(), &timeline.Backend{Host: httpserver.TestServerHost, Port: hungBackendPort + 1})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	fallback := &recordingTransport{HTTPTransport: createHTTPTransport()}
+
+	err = m.SetFallbackTransport(fallback)
+	if !assert.NoError(t, err, "no error expected setting the fallback transport") {
+		return
+	}
+
+	err = m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	numbers := []*structs.NumberPoint{newNumberPoint(1), newNumberPoint(2)}
+
+	for _, n := range numbers {
+		err = m.SendHTTP(numberPoint, toGenericParametersN(n)...)
+		assert.NoError(t, err, "no error expected when sending number")
+	}
+
+	<-time.After(2 * time.Second)
+
+	recorded := fallback.recorded()
+	if assert.Len(t, recorded, 2, "expected the points in the fallback transport") {
+		for i, r := range recorded {
+			item, ok := r.(serializer.ArrayItem)
+			if assert.True(t, ok, "expected a json array item") {
+				assert.Equal(t, numberPoint, item.Name, "expected the same schema")
+				assert.Equal(t, toGenericParametersN(numbers[i]), item.Parameters, "expected the same parameters")
+			}
+		}
+	}
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(1), stats.SendErrors, "expected the primary transport error")
+	assert.Equal(t, uint64(1), stats.FallbackBatches, "expected one batch sent to the fallback")
+	assert.NoError(t, m.Shutdown(), "expected no error shutting down with a fallback")
+}
+
+// TestFallbackTransportValidation - tests the fallback transport validation
+func TestFallbackTransportValidation(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	assert.Error(t, m.SetFallbackTransport(nil), "expected an error with no fallback")
+	assert.Error(t, m.SetFallbackTransport(m.GetTransport()), "expected an error using the primary as fallback")
+}
@@ -4,6 +4,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+
 	"github.com/uol/gobol/hashing"
 	"github.com/uol/gobol/structs"
 	"github.com/uol/gobol/tester/httpserver"
@@ -115,3 +117,58 @@ func TestCountMin(t *testing.T) {
 
 	testFlatOperation(t, timeline.Count, 5, 1, -200, 10.7, 10.8, 0)
 }
+
+// TestSendLast - tests the last operation
+func TestSendLast(t *testing.T) {
+
+	testFlatOperation(t, timeline.Last, 0.5, 1, -200, 10.7, 0.5)
+}
+
+// testPointKind - tests the flattening of some point kind
+func testPointKind(t *testing.T, kind timeline.PointKind, expectedValue float64, opValues ...float64) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManagerF(true)
+	defer m.Shutdown()
+
+	number := newNumberPoint(expectedValue)
+
+	for _, v := range opValues {
+
+		number.Value = v
+		err := m.FlattenHTTPKind(kind, numberPoint, toGenericParameters(number)...)
+		if !assert.NoError(t, err, "no error expected flattening the point") {
+			return
+		}
+	}
+
+	<-time.After(2 * time.Second)
+
+	number.Value = expectedValue
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	testRequestData(t, requestData, []*structs.NumberPoint{number}, true)
+}
+
+// TestCounterKind - tests if the counter values are summed
+func TestCounterKind(t *testing.T) {
+
+	testPointKind(t, timeline.Counter, 16, 1, 5, 10)
+}
+
+// TestGaugeKind - tests if the last gauge value wins
+func TestGaugeKind(t *testing.T) {
+
+	testPointKind(t, timeline.Gauge, 10, 1, 5, 10)
+}
+
+// TestInvalidKind - tests an unmapped point kind
+func TestInvalidKind(t *testing.T) {
+
+	m := createTimelineManagerF(false)
+
+	err := m.FlattenHTTPKind(timeline.PointKind(10), numberPoint, toGenericParameters(newNumberPoint(1))...)
+	assert.Error(t, err, "expected an error with an unmapped point kind")
+}
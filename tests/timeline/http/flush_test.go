@@ -0,0 +1,153 @@
+package timeline_http_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The manager flush tests.
+**/
+
+// createFlushManager - creates a started manager sending only when flushed (or shut down)
+func createFlushManager(t *testing.T, b *outageBackend) *timeline.Manager {
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+
+	return createServerManager(t, b.server, conf)
+}
+
+// TestFlush - tests if the flush sends the buffered points before the batch send interval
+func TestFlush(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	atomic.StoreInt32(&b.down, 0)
+
+	m := createFlushManager(t, b)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1, 2, 3)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := m.Flush(ctx)
+	if !assert.NoError(t, result.Err, "no error expected flushing") {
+		return
+	}
+
+	assert.Equal(t, []float64{1, 2, 3}, b.received(), "expected all buffered points")
+	assert.Equal(t, 3, result.PointsSent, "expected the flushed points in the result")
+	assert.Equal(t, 1, result.BatchesSent, "expected one batch in the result")
+	assert.True(t, result.Duration > 0, "expected the flush duration")
+
+	result = m.Flush(ctx)
+	assert.NoError(t, result.Err, "no error expected flushing an empty buffer")
+	assert.Equal(t, 0, result.PointsSent, "expected no points flushing an empty buffer")
+	assert.Equal(t, 0, result.BatchesSent, "expected no batches flushing an empty buffer")
+}
+
+// TestFlushFailure - tests if the flushed points are kept after a failed flush and sent by the next one
+func TestFlushFailure(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	m := createFlushManager(t, b)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1, 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result := m.Flush(ctx)
+	if !assert.Error(t, result.Err, "expected the send error flushing") {
+		return
+	}
+
+	assert.Equal(t, 0, result.PointsSent, "expected no points sent in the failed flush result")
+	assert.Equal(t, 2, m.Stats().PendingPoints, "expected the flushed points kept as pending")
+	assert.Empty(t, b.received(), "expected no points during the outage")
+
+	atomic.StoreInt32(&b.down, 0)
+
+	sendValues(t, m, 3)
+
+	result = m.Flush(ctx)
+	if !assert.NoError(t, result.Err, "no error expected flushing after the recovery") {
+		return
+	}
+
+	assert.Equal(t, 3, result.PointsSent, "expected the retained and the new points in the result")
+
+	assert.Equal(t, []float64{1, 2, 3}, b.received(), "expected the retained points before the new one")
+	assert.Equal(t, 0, m.Stats().PendingPoints, "expected no pending points")
+}
+
+// TestFlushFailureShutdown - tests if the points kept by a failed flush are sent by the shutdown
+func TestFlushFailureShutdown(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	m := createFlushManager(t, b)
+	if m == nil {
+		return
+	}
+
+	sendValues(t, m, 1)
+
+	if !assert.Error(t, m.Flush(context.Background()).Err, "expected the send error flushing") {
+		return
+	}
+
+	atomic.StoreInt32(&b.down, 0)
+
+	if assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		assert.Equal(t, []float64{1}, b.received(), "expected the retained point sent by the shutdown")
+	}
+}
+
+// TestFlushNotRunning - tests the flush errors before the start and after the shutdown
+func TestFlushNotRunning(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(createHTTPTransportConfig()))
+	if m == nil {
+		return
+	}
+
+	assert.Error(t, m.Flush(context.Background()).Err, "expected an error flushing before the start")
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	err := m.Flush(context.Background()).Err
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error flushing")
+}
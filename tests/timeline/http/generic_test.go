@@ -0,0 +1,140 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+)
+
+/**
+* The generic point tests.
+**/
+
+// TestSendGenericPoint - tests sending generic points with each supported value type
+func TestSendGenericPoint(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := createServerManager(t, b.server, createHTTPTransportConfig())
+	if m == nil {
+		return
+	}
+
+	values := []interface{}{1.5, 42, "up", true}
+
+	for _, v := range values {
+		err := m.SendGenericPoint(&structs.GenericPoint{
+			Point: structs.Point{
+				Metric:    "generic-metric",
+				Tags:      map[string]string{"host": "localhost"},
+				Timestamp: 1,
+			},
+			Value: v,
+		})
+		assert.NoError(t, err, "no error expected sending the value: %v", v)
+	}
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	var points []map[string]interface{}
+	for _, body := range b.bodies {
+		var batch []map[string]interface{}
+		if assert.NoError(t, json.Unmarshal(body, &batch), "no error expected unmarshalling") {
+			points = append(points, batch...)
+		}
+	}
+
+	if !assert.Len(t, points, len(values), "expected all points") {
+		return
+	}
+
+	expected := []struct {
+		value     interface{}
+		valueType structs.ValueType
+	}{
+		{1.5, structs.NumberValue},
+		{42.0, structs.IntegerValue},
+		{"up", structs.TextValue},
+		{true, structs.BoolValue},
+	}
+
+	for i, e := range expected {
+		assert.Equal(t, e.value, points[i]["value"], "expected the same value")
+		assert.Equal(t, string(e.valueType), points[i]["type"], "expected the value type")
+		assert.Equal(t, "generic-metric", points[i]["metric"], "expected the same metric")
+	}
+}
+
+// TestSendGenericPointProperties - tests if the generic point uses the configured value and timestamp properties
+func TestSendGenericPointProperties(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.ValueProperty = "val"
+	conf.TimestampProperty = "ts"
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	err := m.SendGenericPoint(&structs.GenericPoint{
+		Point: structs.Point{
+			Metric:    "generic-metric",
+			Tags:      map[string]string{"host": "localhost"},
+			Timestamp: 1,
+		},
+		Value: 1.5,
+	})
+	if !assert.NoError(t, err, "no error expected sending the point") {
+		return
+	}
+
+	if !assert.NoError(t, m.Shutdown(), "no error expected shutting down") {
+		return
+	}
+
+	if !assert.Len(t, b.bodies, 1, "expected one batch") {
+		return
+	}
+
+	var points []map[string]interface{}
+	if !assert.NoError(t, json.Unmarshal(b.bodies[0], &points), "no error expected unmarshalling") {
+		return
+	}
+
+	if !assert.Len(t, points, 1, "expected one point") {
+		return
+	}
+
+	assert.Equal(t, 1.5, points[0]["val"], "expected the value in the configured property")
+	assert.Equal(t, 1.0, points[0]["ts"], "expected the timestamp in the configured property")
+	assert.NotContains(t, points[0], "value", "expected no default value property")
+	assert.NotContains(t, points[0], "timestamp", "expected no default timestamp property")
+}
+
+// TestSendGenericPointErrors - tests the unsupported value types and the mismatched discriminator
+func TestSendGenericPointErrors(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SendGenericPoint(&structs.GenericPoint{Value: []int{1}})
+	assert.Error(t, err, "expected an unsupported value type error")
+
+	err = m.SendGenericPoint(&structs.GenericPoint{Value: struct{}{}})
+	assert.Error(t, err, "expected an unsupported value type error")
+
+	err = m.SendGenericPoint(&structs.GenericPoint{Value: 1.0, Type: structs.TextValue})
+	assert.Error(t, err, "expected a type mismatch error")
+
+	err = m.SendGenericPoint(nil)
+	assert.Error(t, err, "expected a null point error")
+}
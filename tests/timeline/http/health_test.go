@@ -0,0 +1,66 @@
+package timeline_http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline health check tests.
+**/
+
+// createVerifiedManager - creates a manager verifying the backend on start
+func createVerifiedManager(t *testing.T, port int) *timeline.Manager {
+
+	conf := createHTTPTransportConfig()
+	conf.VerifyOnStart = true
+
+	m, err := timeline.NewManager(createHTTPTransportWithConfig(conf), &timeline.Backend{Host: httpserver.TestServerHost, Port: port})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return nil
+	}
+
+	return m
+}
+
+// TestVerifyOnStartHealthy - tests the start with a healthy backend
+func TestVerifyOnStartHealthy(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createVerifiedManager(t, httpserver.TestServerPort)
+	if m == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.NoError(t, m.HealthCheck(ctx), "expected a healthy backend")
+
+	if assert.NoError(t, m.Start(), "expected the manager to start") {
+		assert.NoError(t, m.Shutdown(), "no error expected shutting down")
+	}
+}
+
+// TestVerifyOnStartUnhealthy - tests the start with an unhealthy backend
+func TestVerifyOnStartUnhealthy(t *testing.T) {
+
+	m := createVerifiedManager(t, hungBackendPort+1)
+	if m == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.Error(t, m.HealthCheck(ctx), "expected an unhealthy backend")
+	assert.Error(t, m.Start(), "expected an error starting the manager")
+}
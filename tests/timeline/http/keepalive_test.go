@@ -0,0 +1,85 @@
+package timeline_http_test
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline connection reuse tests.
+**/
+
+// TestIdleConnectionClosed - tests if the transport reconnects after the server closes the idle connection
+func TestIdleConnectionClosed(t *testing.T) {
+
+	s, err := httpserver.NewHTTPServerWithTimeouts(
+		"127.0.0.1:0",
+		5,
+		[]httpserver.ResponseData{
+			{
+				RequestData: httpserver.RequestData{
+					URI:    "/api/put",
+					Method: "PUT",
+				},
+				Status: http.StatusCreated,
+			},
+		},
+		httpserver.Timeouts{IdleTimeout: 100 * time.Millisecond},
+	)
+	if !assert.NoError(t, err, "no error expected creating the server") {
+		return
+	}
+
+	defer s.Close()
+
+	host, portText, err := net.SplitHostPort(s.Address())
+	if !assert.NoError(t, err, "no error expected splitting the server address") {
+		return
+	}
+
+	port, err := strconv.Atoi(portText)
+	if !assert.NoError(t, err, "no error expected parsing the server port") {
+		return
+	}
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 50 * time.Millisecond
+
+	m, err := timeline.NewManager(createHTTPTransportWithConfig(conf), &timeline.Backend{Host: host, Port: port})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1)
+
+	first := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, first, "expected the first batch") {
+		return
+	}
+
+	<-time.After(300 * time.Millisecond)
+
+	sendValues(t, m, 2)
+
+	second := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, second, "expected the second batch") {
+		return
+	}
+
+	assert.NotEqual(t, first.RemoteAddr, second.RemoteAddr, "expected a new connection after the idle timeout")
+	assert.Equal(t, uint64(0), m.Stats().SendErrors, "expected no send errors reconnecting")
+}
@@ -0,0 +1,358 @@
+package timeline_http_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The manager start and shutdown tests.
+**/
+
+// TestLazyStart - tests if the first sent point starts the manager
+func TestLazyStart(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 50 * time.Millisecond
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(conf))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	m.SetLazyStart(true)
+
+	sendValues(t, m, 1)
+
+	delivered := assert.Eventually(t, func() bool {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		return len(b.bodies) == 1
+	}, 2*time.Second, 10*time.Millisecond, "expected the point delivered without calling start")
+
+	if !delivered {
+		return
+	}
+
+	assert.NoError(t, m.Start(), "expected no error calling the explicit start after the lazy one")
+	assert.Equal(t, uint64(1), m.Stats().PointsSent, "expected one point sent")
+}
+
+// TestLazyStartConcurrentDrain - tests the lazy start by the first sent point while other goroutines drain and flush
+// the manager (run it with -race)
+func TestLazyStartConcurrentDrain(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 10 * time.Millisecond
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(conf))
+	if m == nil {
+		return
+	}
+
+	m.SetLazyStart(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		sendValues(t, m, 1)
+	}()
+
+	go func() {
+		defer wg.Done()
+		m.Flush(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		m.Drain(ctx)
+	}()
+
+	wg.Wait()
+
+	assert.NoError(t, m.Drain(ctx), "expected the point drained")
+	assert.NoError(t, m.Shutdown(), "expected no error shutting down")
+	assert.Equal(t, uint64(1), m.Stats().PointsSent, "expected the point sent once")
+}
+
+// TestRunning - tests the running status across the start and the shutdown
+func TestRunning(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(createHTTPTransportConfig()))
+	if m == nil {
+		return
+	}
+
+	assert.False(t, m.Running(), "expected a not started manager")
+
+	if !assert.NoError(t, m.Start(), "expected no error starting") {
+		return
+	}
+
+	assert.True(t, m.Running(), "expected a running manager after the start")
+
+	if !assert.NoError(t, m.Shutdown(), "expected no error shutting down") {
+		return
+	}
+
+	assert.False(t, m.Running(), "expected a not running manager after the shutdown")
+}
+
+// TestRunningLazyStart - tests if the lazy start changes the running status
+func TestRunningLazyStart(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(createHTTPTransportConfig()))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	m.SetLazyStart(true)
+
+	assert.False(t, m.Running(), "expected a not started manager")
+
+	sendValues(t, m, 1)
+
+	assert.True(t, m.Running(), "expected a running manager after the first sent point")
+}
+
+// TestSendAfterShutdown - tests if the sends after the shutdown return the closed transport error
+func TestSendAfterShutdown(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := createServerManager(t, b.server, createHTTPTransportConfig())
+	if m == nil {
+		return
+	}
+
+	if !assert.NoError(t, m.Shutdown(), "expected no error shutting down") {
+		return
+	}
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error sending")
+
+	err = m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error sending synchronously")
+
+	err = m.Start()
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error starting")
+
+	err = m.Shutdown()
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error shutting down twice")
+}
+
+// shutdownDuringSends - shuts down the manager while the senders are sending, returning the errors not expected
+// (the sends must succeed or return the closed transport error, never panic sending to the closed buffer)
+func shutdownDuringSends(t *testing.T, m *timeline.Manager, senders int, wait time.Duration) []error {
+
+	var wg sync.WaitGroup
+	errs := make(chan error, senders)
+
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func(v float64) {
+			defer wg.Done()
+			for {
+				err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(v))...)
+				if err == nil {
+					continue
+				}
+
+				if !errors.Is(err, timeline.ErrTransportClosed) {
+					errs <- err
+				}
+
+				return
+			}
+		}(float64(i))
+	}
+
+	<-time.After(wait)
+
+	assert.NoError(t, m.Shutdown(), "expected no error shutting down")
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		return []error{errors.New("the senders did not return after the shutdown")}
+	}
+
+	close(errs)
+
+	unexpected := []error{}
+	for err := range errs {
+		unexpected = append(unexpected, err)
+	}
+
+	return unexpected
+}
+
+// TestShutdownDuringSends - tests if the shutdown is safe while many goroutines are sending
+func TestShutdownDuringSends(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.TransportBufferSize = 2
+	conf.BatchSendInterval = 10 * time.Millisecond
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	assert.Empty(t, shutdownDuringSends(t, m, 20, 50*time.Millisecond), "expected only the closed transport errors")
+}
+
+// TestShutdownBlockedSends - tests if the sends blocked by the full buffer return when the manager is shut down
+func TestShutdownBlockedSends(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.TransportBufferSize = 2
+	conf.BatchSendInterval = time.Minute
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	assert.Empty(t, shutdownDuringSends(t, m, 20, 50*time.Millisecond), "expected only the closed transport errors")
+}
+
+// TestStartContext - tests if the context cancellation flushes the buffered points and stops the manager
+func TestStartContext(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Minute
+
+	m := newServerManager(t, b.server, createHTTPTransportWithConfig(conf))
+	if m == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if !assert.NoError(t, m.StartContext(ctx), "expected no error starting") {
+		return
+	}
+
+	sendValues(t, m, 1, 2)
+
+	cancel()
+
+	flushed := assert.Eventually(t, func() bool {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		return len(b.bodies) == 1
+	}, 2*time.Second, 10*time.Millisecond, "expected the buffered points flushed on the context cancellation")
+
+	if !flushed {
+		return
+	}
+
+	assert.Equal(t, uint64(2), m.Stats().PointsSent, "expected all points sent in the final flush")
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(3))...)
+	assert.True(t, errors.Is(err, timeline.ErrTransportClosed), "expected the closed transport error after the context cancellation")
+}
+
+// TestDrain - tests if the drain blocks until all buffered points are sent
+func TestDrain(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 200 * time.Millisecond
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1, 2, 3, 4, 5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if !assert.NoError(t, m.Drain(ctx), "expected no error draining") {
+		return
+	}
+
+	assert.Equal(t, uint64(5), m.Stats().PointsSent, "expected all points sent")
+	assert.Equal(t, 0, m.BufferLen(), "expected an empty buffer")
+
+	b.mutex.Lock()
+	assert.Len(t, b.bodies, 1, "expected the backend to receive the batch")
+	b.mutex.Unlock()
+}
+
+// TestDrainTimeout - tests if the drain returns the context error when the points can not be sent in time
+func TestDrainTimeout(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Minute
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, m.Drain(ctx), "expected the context error")
+}
@@ -0,0 +1,181 @@
+package timeline_http_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline max request bytes tests.
+**/
+
+// newWideNumberPoint - creates a new number point with a large tag set
+func newWideNumberPoint(value float64) *structs.NumberPoint {
+
+	point := newNumberPoint(value)
+
+	for i := 0; i < 5; i++ {
+		point.Tags[fmt.Sprintf("wide%d", i)] = strings.Repeat("x", 50)
+	}
+
+	return point
+}
+
+// TestMaxRequestBytes - tests if the batch is split in multiple requests with bodies under the limit
+func TestMaxRequestBytes(t *testing.T) {
+
+	const maxRequestBytes = 2048
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+	conf.MaxRequestBytes = maxRequestBytes
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	numPoints := 20
+	for i := 0; i < numPoints; i++ {
+		assert.NoError(t, m.SendHTTP(numberPoint, toGenericParametersN(newWideNumberPoint(float64(i)))...), "expected no error sending")
+	}
+
+	result := m.Flush(context.Background())
+	if !assert.NoError(t, result.Err, "no error expected flushing") {
+		return
+	}
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	assert.Greater(t, len(b.bodies), 1, "expected multiple requests")
+
+	received := []float64{}
+	for _, body := range b.bodies {
+		assert.LessOrEqual(t, len(body), maxRequestBytes, "expected the body under the limit")
+
+		var points []structs.NumberPoint
+		if !assert.NoError(t, json.Unmarshal(body, &points), "expected a valid json array") {
+			return
+		}
+
+		for _, p := range points {
+			received = append(received, p.Value)
+		}
+	}
+
+	expected := []float64{}
+	for i := 0; i < numPoints; i++ {
+		expected = append(expected, float64(i))
+	}
+
+	assert.Equal(t, expected, received, "expected all points in order")
+}
+
+// TestMaxRequestBytesPartialRetry - tests if only the requests not accepted are retried when a later one fails
+func TestMaxRequestBytesPartialRetry(t *testing.T) {
+
+	b := newCaptureBackend()
+	b.failRequest = 2
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+	conf.MaxRequestBytes = 2048
+	conf.MaxRetries = 1
+	conf.RetryInterval = 10 * time.Millisecond
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	numPoints := 20
+	for i := 0; i < numPoints; i++ {
+		assert.NoError(t, m.SendHTTP(numberPoint, toGenericParametersN(newWideNumberPoint(float64(i)))...), "expected no error sending")
+	}
+
+	result := m.Flush(context.Background())
+	if !assert.NoError(t, result.Err, "no error expected flushing after the retry") {
+		return
+	}
+
+	assert.Equal(t, uint64(1), m.Stats().Retries, "expected one retry")
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	assert.Greater(t, len(b.bodies), 2, "expected more than two accepted requests")
+
+	received := []float64{}
+	for _, body := range b.bodies {
+
+		var points []structs.NumberPoint
+		if !assert.NoError(t, json.Unmarshal(body, &points), "expected a valid json array") {
+			return
+		}
+
+		for _, p := range points {
+			received = append(received, p.Value)
+		}
+	}
+
+	expected := []float64{}
+	for i := 0; i < numPoints; i++ {
+		expected = append(expected, float64(i))
+	}
+
+	assert.Equal(t, expected, received, "expected each point received once and in order")
+}
+
+// TestMaxRequestBytesPointTooLarge - tests if a single point exceeding the limit fails the send
+func TestMaxRequestBytesPointTooLarge(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+	conf.MaxRequestBytes = 64
+
+	m := createServerManager(t, b.server, conf)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newWideNumberPoint(1))...)
+	assert.Error(t, err, "expected an error sending a point over the limit")
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	assert.Empty(t, b.bodies, "expected no requests")
+}
+
+// TestInvalidMaxRequestBytes - tests the max request bytes validation
+func TestInvalidMaxRequestBytes(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+	conf.MaxRequestBytes = -1
+
+	_, err := timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an invalid max request bytes error")
+}
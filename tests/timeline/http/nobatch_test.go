@@ -0,0 +1,54 @@
+package timeline_http_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+)
+
+/**
+* The timeline disabled batching tests.
+**/
+
+// TestDisableBatching - tests if each point is sent promptly without waiting the batch interval
+func TestDisableBatching(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+	conf.DisableBatching = true
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	numbers := []*structs.NumberPoint{newNumberPoint(1), newNumberPoint(2)}
+
+	for _, number := range numbers {
+
+		err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+		if !assert.NoError(t, err, "no error expected when sending number") {
+			return
+		}
+
+		var requestData *httpserver.RequestData
+
+		select {
+		case requestData = <-s.RequestChannel():
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		testRequestData(t, requestData, []*structs.NumberPoint{number}, true)
+	}
+
+	<-time.After(100 * time.Millisecond)
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(2), stats.PointsSent, "expected two points sent")
+	assert.Equal(t, uint64(2), stats.BatchesSent, "expected one batch per point")
+}
@@ -0,0 +1,139 @@
+package timeline_http_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline buffer overflow policy tests.
+**/
+
+// createDropOldestManager - creates a not started manager using the drop oldest policy
+func createDropOldestManager(bufferSize int) *timeline.Manager {
+
+	conf := createHTTPTransportConfig()
+	conf.TransportBufferSize = bufferSize
+	conf.OverflowPolicy = timeline.DropOldest
+
+	return createTimelineManagerWithConfig(conf, false)
+}
+
+// TestDropOldest - tests if the newest points survive when the buffer overflows
+func TestDropOldest(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createDropOldestManager(3)
+
+	numbers := []*structs.NumberPoint{}
+	for i := 0; i < 5; i++ {
+		number := newNumberPoint(float64(i))
+		numbers = append(numbers, number)
+
+		err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+		assert.NoError(t, err, "no error expected when sending number")
+	}
+
+	assert.Equal(t, 3, m.BufferLen(), "expected a full buffer")
+	assert.Equal(t, uint64(2), m.Stats().DroppedPoints, "expected the oldest points to be dropped")
+
+	err := m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	testRequestData(t, requestData, numbers[2:], true)
+}
+
+// TestDropCallback - tests if the drop callback and the drop counter reflect the number of dropped points
+func TestDropCallback(t *testing.T) {
+
+	m := createDropOldestManager(3)
+
+	var dropped int64
+
+	err := m.OnDrop(func(count int) {
+		atomic.AddInt64(&dropped, int64(count))
+	})
+	if !assert.NoError(t, err, "no error expected setting the drop callback") {
+		return
+	}
+
+	for i := 0; i < 10; i++ {
+		err := m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected when sending number")
+	}
+
+	assert.Equal(t, int64(7), atomic.LoadInt64(&dropped), "expected the callback notified of all dropped points")
+	assert.Equal(t, uint64(7), m.Stats().DroppedPoints, "expected the drop counter")
+}
+
+// TestTrySendFullBuffer - tests if the non blocking send returns false when the buffer is full
+func TestTrySendFullBuffer(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+	conf.TransportBufferSize = 3
+
+	m := createTimelineManagerWithConfig(conf, false)
+
+	for i := 0; i < 3; i++ {
+		ok, err := m.TrySendHTTP(numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected when sending number")
+		assert.True(t, ok, "expected the point to be buffered")
+	}
+
+	done := make(chan bool, 1)
+
+	go func() {
+		ok, _ := m.TrySendHTTP(numberPoint, toGenericParametersN(newNumberPoint(3))...)
+		done <- ok
+	}()
+
+	select {
+	case ok := <-done:
+		assert.False(t, ok, "expected the point to be rejected by the full buffer")
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the send to not block")
+	}
+
+	assert.Equal(t, 3, m.BufferLen(), "expected a full buffer")
+	assert.Equal(t, uint64(0), m.Stats().DroppedPoints, "expected no dropped points")
+}
+
+// TestInvalidOverflowPolicy - tests the overflow policy validation
+func TestInvalidOverflowPolicy(t *testing.T) {
+
+	conf := createHTTPTransportConfig()
+	conf.OverflowPolicy = timeline.OverflowPolicy(10)
+
+	_, err := timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an error with an invalid overflow policy")
+}
+
+// BenchmarkDropOldest - benchmarks the enqueue with an overflowing buffer
+func BenchmarkDropOldest(b *testing.B) {
+
+	m := createDropOldestManager(128)
+	parameters := toGenericParametersN(newNumberPoint(1))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		m.SendHTTP(numberPoint, parameters...)
+	}
+}
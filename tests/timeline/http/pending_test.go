@@ -0,0 +1,139 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline pending points tests.
+**/
+
+// outageBackend - a backend failing all requests while it is down
+type outageBackend struct {
+	server *httptest.Server
+	down   int32
+	values []float64
+	mutex  sync.Mutex
+}
+
+// newOutageBackend - creates a new backend (starting down)
+func newOutageBackend() *outageBackend {
+
+	b := &outageBackend{down: 1}
+
+	b.server = httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+
+		if atomic.LoadInt32(&b.down) == 1 {
+			res.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var points []structs.NumberPoint
+		if err := json.NewDecoder(req.Body).Decode(&points); err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		b.mutex.Lock()
+		for _, p := range points {
+			b.values = append(b.values, p.Value)
+		}
+		b.mutex.Unlock()
+
+		res.WriteHeader(http.StatusCreated)
+	}))
+
+	return b
+}
+
+// received - returns the received point values
+func (b *outageBackend) received() []float64 {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	return append([]float64{}, b.values...)
+}
+
+// createPendingManager - creates a started manager with the pending area enabled
+func createPendingManager(t *testing.T, server *httptest.Server, maxPendingPoints int) *timeline.Manager {
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 100 * time.Millisecond
+	conf.MaxPendingPoints = maxPendingPoints
+
+	return createServerManager(t, server, conf)
+}
+
+// sendValues - sends number points with the specified values
+func sendValues(t *testing.T, m *timeline.Manager, values ...float64) {
+
+	for _, v := range values {
+		assert.NoError(t, m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(v))...), "expected no error sending")
+	}
+}
+
+// TestPendingReplay - tests if the points failed during an outage are replayed (oldest first) after the recovery
+func TestPendingReplay(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	m := createPendingManager(t, b.server, 100)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1, 2)
+	<-time.After(250 * time.Millisecond)
+
+	sendValues(t, m, 3)
+	<-time.After(250 * time.Millisecond)
+
+	assert.Equal(t, 3, m.Stats().PendingPoints, "expected the points as pending")
+	assert.Empty(t, b.received(), "expected no points during the outage")
+
+	atomic.StoreInt32(&b.down, 0)
+
+	sendValues(t, m, 4)
+	<-time.After(250 * time.Millisecond)
+
+	assert.Equal(t, []float64{1, 2, 3, 4}, b.received(), "expected all points in order")
+	assert.Equal(t, 0, m.Stats().PendingPoints, "expected no pending points")
+}
+
+// TestPendingLimit - tests if the oldest pending points are dropped when the limit is reached
+func TestPendingLimit(t *testing.T) {
+
+	b := newOutageBackend()
+	defer b.server.Close()
+
+	m := createPendingManager(t, b.server, 2)
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1, 2, 3)
+	<-time.After(250 * time.Millisecond)
+
+	atomic.StoreInt32(&b.down, 0)
+	<-time.After(250 * time.Millisecond)
+
+	assert.Equal(t, []float64{2, 3}, b.received(), "expected only the newest pending points")
+	assert.Equal(t, uint64(1), m.Stats().DroppedPoints, "expected one dropped point")
+}
@@ -0,0 +1,111 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/timeline"
+	serializer "github.com/uol/serializer/json"
+)
+
+/**
+* The timestamp precision tests.
+**/
+
+// testTimestampScale - checks if the timestamp is the current time in the precision
+func testTimestampScale(t *testing.T, precision structs.TimestampPrecision, timestamp int64) bool {
+
+	var unit time.Duration
+	switch precision {
+	case structs.Milliseconds:
+		unit = time.Millisecond
+	case structs.Nanoseconds:
+		unit = time.Nanosecond
+	default:
+		unit = time.Second
+	}
+
+	now := time.Now().UnixNano() / int64(unit)
+	margin := int64(time.Minute / unit)
+
+	return assert.InDelta(t, now, timestamp, float64(margin), "expected a timestamp with precision %d", precision)
+}
+
+// TestNumberPointPrecision - tests the emitted timestamp scale of the number point constructors
+func TestNumberPointPrecision(t *testing.T) {
+
+	m := createTimelineManager(false)
+	tags := map[string]string{"type": "number"}
+
+	points := []*structs.NumberPoint{
+		structs.NewNumberPoint("metric", 1, tags),
+		structs.NewNumberPointMillis("metric", 1, tags),
+		structs.NewNumberPointNanos("metric", 1, tags),
+	}
+
+	expected := []structs.TimestampPrecision{structs.Seconds, structs.Milliseconds, structs.Nanoseconds}
+
+	for i, point := range points {
+
+		serialized, err := m.SerializeHTTP(numberPoint, toGenericParametersN(point)...)
+		if !assert.NoError(t, err, "no error expected serializing") {
+			return
+		}
+
+		var emitted map[string]interface{}
+		decoder := json.NewDecoder(strings.NewReader(serialized))
+		decoder.UseNumber()
+		if !assert.NoError(t, decoder.Decode(&emitted), "no error expected decoding") {
+			return
+		}
+
+		timestamp, err := emitted["timestamp"].(json.Number).Int64()
+		if assert.NoError(t, err, "expected an integer timestamp") {
+			assert.Equal(t, point.Timestamp, timestamp, "expected the same timestamp")
+			testTimestampScale(t, expected[i], timestamp)
+		}
+	}
+}
+
+// TestTransportTimestampPrecision - tests if the transport generates the missing timestamps using the configured precision
+func TestTransportTimestampPrecision(t *testing.T) {
+
+	for _, precision := range []structs.TimestampPrecision{structs.Seconds, structs.Milliseconds, structs.Nanoseconds} {
+
+		conf := createHTTPTransportConfig()
+		conf.TimestampPrecision = precision
+
+		transport := createHTTPTransportWithConfig(conf)
+
+		point, err := transport.DataChannelItemToFlattenedPoint(timeline.Sum, &serializer.ArrayItem{
+			Name:       numberPoint,
+			Parameters: []interface{}{"metric", "metric", "value", 1.0},
+		})
+		if !assert.NoError(t, err, "no error expected flattening") {
+			return
+		}
+
+		item, err := transport.FlattenedPointToDataChannelItem(point)
+		if !assert.NoError(t, err, "no error expected converting the flattened point") {
+			return
+		}
+
+		parameters := item.(serializer.ArrayItem).Parameters
+		for i := 0; i+1 < len(parameters); i += 2 {
+			if parameters[i] == "timestamp" {
+				testTimestampScale(t, precision, parameters[i+1].(int64))
+			}
+		}
+	}
+
+	conf := createHTTPTransportConfig()
+	conf.TimestampPrecision = structs.Nanoseconds + 1
+
+	_, err := timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an invalid precision error")
+}
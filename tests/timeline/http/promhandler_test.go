@@ -0,0 +1,62 @@
+package timeline_http_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+/**
+* The timeline prometheus handler tests.
+**/
+
+// TestPrometheusHandler - tests if the exposed counters move after sending points
+func TestPrometheusHandler(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	m := createServerManager(t, b.server, createHTTPTransportConfig())
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	scrape := func() string {
+		recorder := httptest.NewRecorder()
+		m.PrometheusHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body, err := ioutil.ReadAll(recorder.Body)
+		assert.NoError(t, err, "no error expected reading the body")
+
+		return string(body)
+	}
+
+	before := scrape()
+	assert.Contains(t, before, "timeline_points_sent_total{transport=\"http\"} 0\n", "expected no points sent")
+	assert.Contains(t, before, "# TYPE timeline_points_sent_total counter\n", "expected the counter type")
+	assert.Contains(t, before, "# TYPE timeline_buffer_length gauge\n", "expected the gauge type")
+
+	for i := 0; i < 3; i++ {
+		err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected sending")
+	}
+
+	after := scrape()
+	for _, line := range []string{
+		"timeline_points_sent_total{transport=\"http\"} 3\n",
+		"timeline_points_dropped_total{transport=\"http\"} 0\n",
+		"timeline_send_errors_total{transport=\"http\"} 0\n",
+		"timeline_buffer_length{transport=\"http\"} 0\n",
+	} {
+		assert.Contains(t, after, line, "expected the metric")
+	}
+
+	assert.Contains(t, after, "timeline_batch_send_duration_seconds{transport=\"http\"} ", "expected the send duration")
+	assert.NotContains(t, after, "timeline_batch_send_duration_seconds{transport=\"http\"} 0\n", "expected a send duration")
+}
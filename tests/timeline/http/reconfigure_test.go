@@ -0,0 +1,176 @@
+package timeline_http_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline http transport live reconfiguration tests.
+**/
+
+// TestReconfigureIntervalAndEndpoint - tests changing the batch send interval and the endpoint of the running transport
+func TestReconfigureIntervalAndEndpoint(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+
+	transport := createHTTPTransportWithConfig(conf)
+
+	m := startManager(t, newServerManager(t, b.server, transport))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	sendValues(t, m, 1)
+	<-time.After(100 * time.Millisecond)
+
+	assert.Empty(t, b.receivedPaths(), "expected no batch sent before the configured interval")
+
+	newConf := createHTTPTransportConfig()
+	newConf.BatchSendInterval = 50 * time.Millisecond
+	newConf.ServiceEndpoint = "/api/v2/put"
+
+	if !assert.NoError(t, transport.Reconfigure(newConf), "no error expected reconfiguring the transport") {
+		return
+	}
+
+	delivered := assert.Eventually(t, func() bool {
+		return len(b.receivedPaths()) == 1
+	}, time.Second, 10*time.Millisecond, "expected the buffered batch sent using the new interval")
+
+	if !delivered {
+		return
+	}
+
+	assert.True(t, strings.HasSuffix(b.receivedPaths()[0], "/api/v2/put"), "expected the batch sent to the new endpoint")
+
+	sendValues(t, m, 2)
+
+	assert.Eventually(t, func() bool {
+		return len(b.receivedPaths()) == 2
+	}, time.Second, 10*time.Millisecond, "expected the next batch sent using the new interval")
+}
+
+// TestReconfigureInvalid - tests if the invalid and the unchangeable configurations are rejected
+func TestReconfigureInvalid(t *testing.T) {
+
+	transport := createHTTPTransport()
+
+	assert.Error(t, transport.Reconfigure(nil), "expected an error with a null configuration")
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 0
+	assert.Error(t, transport.Reconfigure(conf), "expected an error with an invalid batch send interval")
+
+	conf = createHTTPTransportConfig()
+	conf.SerializerBufferSize = 10
+	assert.Error(t, transport.Reconfigure(conf), "expected an error changing the serializer buffer size")
+
+	conf = createHTTPTransportConfig()
+	conf.ValueProperty = "v"
+	assert.Error(t, transport.Reconfigure(conf), "expected an error changing the value property")
+
+	conf = createHTTPTransportConfig()
+	conf.OverflowPolicy = timeline.DropOldest
+	assert.Error(t, transport.Reconfigure(conf), "expected an error changing the overflow policy")
+
+	conf = createHTTPTransportConfig()
+	conf.TransportBufferSize = 16
+	if assert.NoError(t, transport.Reconfigure(conf), "no error expected resizing the buffer before starting") {
+		assert.Equal(t, 16, transport.BufferCap(), "expected the resized buffer")
+	}
+}
+
+// TestReconfigureBufferRunning - tests if the buffer is not resized while the transport is running
+func TestReconfigureBufferRunning(t *testing.T) {
+
+	b := newCaptureBackend()
+	defer b.server.Close()
+
+	transport := createHTTPTransport()
+
+	m := startManager(t, newServerManager(t, b.server, transport))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	conf := createHTTPTransportConfig()
+	conf.TransportBufferSize = 16
+
+	assert.Error(t, transport.Reconfigure(conf), "expected an error resizing the buffer while running")
+	assert.Equal(t, 1024, transport.BufferCap(), "expected the original buffer")
+}
+
+// TestReconfigureDuringSend - tests if the reconfiguration is not blocked by a batch being sent
+func TestReconfigureDuringSend(t *testing.T) {
+
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+
+		select {
+		case arrived <- struct{}{}:
+		default:
+		}
+
+		<-release
+		res.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 10 * time.Millisecond
+	conf.RequestTimeout = time.Minute
+
+	transport := createHTTPTransportWithConfig(conf)
+
+	m := startManager(t, newServerManager(t, server, transport))
+	if m == nil {
+		close(release)
+		return
+	}
+
+	defer m.Shutdown()
+	defer close(release)
+
+	sendValues(t, m, 1)
+
+	select {
+	case <-arrived:
+	case <-time.After(time.Second):
+		assert.Fail(t, "expected the batch to be sent")
+		return
+	}
+
+	reconfigured := make(chan error, 1)
+
+	go func() {
+		newConf := createHTTPTransportConfig()
+		newConf.BatchSendInterval = 20 * time.Millisecond
+		newConf.RequestTimeout = time.Minute
+		reconfigured <- transport.Reconfigure(newConf)
+	}()
+
+	select {
+	case err := <-reconfigured:
+		assert.NoError(t, err, "no error expected reconfiguring the transport")
+	case <-time.After(500 * time.Millisecond):
+		assert.Fail(t, "expected the reconfiguration not to wait for the batch being sent")
+	}
+}
@@ -0,0 +1,142 @@
+package timeline_http_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline retry tests.
+**/
+
+// createFlakyBackend - creates a backend responding the failure status before succeeding
+func createFlakyBackend(failureStatus, failures int, hits *int32) *httptest.Server {
+
+	return httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+
+		if int(atomic.AddInt32(hits, 1)) <= failures {
+			res.WriteHeader(failureStatus)
+			return
+		}
+
+		res.WriteHeader(http.StatusCreated)
+	}))
+}
+
+// createRetryConfig - creates a transport configuration with retries using the classifier
+func createRetryConfig(classifier func(int, error) bool) *timeline.HTTPTransportConfig {
+
+	conf := createHTTPTransportConfig()
+	conf.MaxRetries = 3
+	conf.RetryInterval = 50 * time.Millisecond
+	conf.RetryClassifier = classifier
+
+	return conf
+}
+
+// TestCustomRetryClassifier - tests a custom classifier retrying a specific 4xx status
+func TestCustomRetryClassifier(t *testing.T) {
+
+	var hits int32
+	s := createFlakyBackend(http.StatusConflict, 2, &hits)
+	defer s.Close()
+
+	m := createServerManager(t, s, createRetryConfig(func(status int, err error) bool {
+		return status == http.StatusConflict
+	}))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.NoError(t, err, "expected the point to be sent after the retries")
+	assert.Equal(t, int32(3), atomic.LoadInt32(&hits), "expected two retries")
+	assert.Equal(t, uint64(2), m.Stats().Retries, "expected two retries")
+}
+
+// TestDefaultRetryClassifier - tests the default classifier retrying 5xx but not 4xx statuses
+func TestDefaultRetryClassifier(t *testing.T) {
+
+	var hits int32
+	s := createFlakyBackend(http.StatusServiceUnavailable, 1, &hits)
+	defer s.Close()
+
+	m := createServerManager(t, s, createRetryConfig(nil))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.NoError(t, err, "expected the point to be sent after the retry")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&hits), "expected one retry")
+
+	hits = 0
+	conflict := createFlakyBackend(http.StatusConflict, 5, &hits)
+	defer conflict.Close()
+
+	m = createServerManager(t, conflict, createRetryConfig(nil))
+	if m == nil {
+		return
+	}
+
+	defer m.Shutdown()
+
+	err = m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&hits), "expected no retries")
+
+	var statusErr *timeline.StatusError
+	if assert.True(t, errors.As(err, &statusErr), "expected a status error") {
+		assert.Equal(t, http.StatusConflict, statusErr.Status, "expected the response status")
+	}
+
+	assert.True(t, timeline.DefaultRetryClassifier(http.StatusTooManyRequests, nil), "expected 429 to be retried")
+	assert.False(t, timeline.DefaultRetryClassifier(http.StatusBadRequest, nil), "expected 400 not to be retried")
+}
+
+// TestRetryJitter - tests if the retry delays are randomized within the strategy bounds
+func TestRetryJitter(t *testing.T) {
+
+	interval := 100 * time.Millisecond
+
+	assert.Equal(t, interval, timeline.NoJitter.Delay(interval), "expected the exact interval without jitter")
+
+	bounds := []struct {
+		jitter timeline.RetryJitter
+		min    time.Duration
+	}{
+		{timeline.FullJitter, 0},
+		{timeline.EqualJitter, interval / 2},
+	}
+
+	for _, b := range bounds {
+
+		distinct := map[time.Duration]struct{}{}
+
+		for i := 0; i < 100; i++ {
+			delay := b.jitter.Delay(interval)
+			assert.GreaterOrEqual(t, int64(delay), int64(b.min), "expected the delay above the lower bound")
+			assert.LessOrEqual(t, int64(delay), int64(interval), "expected the delay below the interval")
+			distinct[delay] = struct{}{}
+		}
+
+		assert.Greater(t, len(distinct), 1, "expected randomized delays")
+	}
+
+	conf := createHTTPTransportConfig()
+	conf.RetryJitter = timeline.RetryJitter(3)
+	_, err := timeline.NewHTTPTransport(conf)
+	assert.Error(t, err, "expected an error with an invalid jitter")
+}
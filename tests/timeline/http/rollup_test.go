@@ -0,0 +1,123 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline rollup points tests.
+**/
+
+const rollupPoint = "rollupJSON"
+
+// createRollupBackend - creates a new test server simulating a timeseries backend accepting rollups
+func createRollupBackend() *httpserver.HTTPServer {
+
+	headers := http.Header{}
+	headers.Add("Content-type", "application/json")
+
+	responses := []httpserver.ResponseData{}
+
+	for _, uri := range []string{"/api/put", "/api/rollup"} {
+		responses = append(responses, httpserver.ResponseData{
+			RequestData: httpserver.RequestData{
+				URI:     uri,
+				Method:  "PUT",
+				Headers: headers,
+			},
+			Status: 201,
+		})
+	}
+
+	return httpserver.CreateNewTestHTTPServer(responses)
+}
+
+// TestRollupPoint - tests if the rollup points are sent to the rollup endpoint
+func TestRollupPoint(t *testing.T) {
+
+	s := createRollupBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.RollupServiceEndpoint = "/api/rollup"
+
+	transport := createHTTPTransportWithConfig(conf)
+
+	err := transport.AddJSONMapping(
+		rollupPoint,
+		structs.NumberPoint{Aggregator: "-", Interval: "-"},
+		"metric",
+		"value",
+		"timestamp",
+		"tags",
+		"aggregator",
+		"interval",
+	)
+	if !assert.NoError(t, err, "no error expected adding the rollup mapping") {
+		return
+	}
+
+	m, err := timeline.NewManager(transport, &timeline.Backend{Host: httpserver.TestServerHost, Port: httpserver.TestServerPort})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	err = m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	rollup := newNumberPoint(10)
+	rollup.Aggregator = "SUM"
+	rollup.Interval = "1h"
+
+	err = m.SendHTTP(rollupPoint, append(toGenericParametersN(rollup), "aggregator", rollup.Aggregator, "interval", rollup.Interval)...)
+	if !assert.NoError(t, err, "no error expected when sending the rollup") {
+		return
+	}
+
+	number := newNumberPoint(1)
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending the number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requests := map[string]*httpserver.RequestData{}
+	for i := 0; i < 2; i++ {
+		requestData := httpserver.WaitForHTTPServerRequest(s)
+		if !assert.NotNil(t, requestData, "request data cannot be null") {
+			return
+		}
+
+		requests[requestData.URI] = requestData
+	}
+
+	if assert.Contains(t, requests, "/api/put", "expected a put request") {
+		testSerializeCompareNumber(t, requests["/api/put"].Body, []*structs.NumberPoint{number})
+	}
+
+	if assert.Contains(t, requests, "/api/rollup", "expected a rollup request") {
+		var actual []structs.NumberPoint
+		err = json.Unmarshal([]byte(requests["/api/rollup"].Body), &actual)
+		if assert.NoError(t, err, "error unmarshalling to number point") && assert.Len(t, actual, 1, "expected one rollup point") {
+			assert.Equal(t, "SUM", actual[0].Aggregator, "expected the rollup aggregator")
+			assert.Equal(t, "1h", actual[0].Interval, "expected the rollup interval")
+			assert.Equal(t, rollup.Value, actual[0].Value, "expected the rollup value")
+			assert.Equal(t, rollup.Metric, actual[0].Metric, "expected the rollup metric")
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package timeline_http_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+)
+
+/**
+* The timeline self metrics tests.
+**/
+
+// TestSelfMetrics - tests if the self metrics are sent using the configured prefix
+func TestSelfMetrics(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	err := m.EnableSelfMetrics(300*time.Millisecond, "test_timeline")
+	if !assert.NoError(t, err, "no error expected enabling the self metrics") {
+		return
+	}
+
+	err = m.EnableSelfMetrics(300*time.Millisecond, "test_timeline")
+	assert.Error(t, err, "expected an error enabling the self metrics twice")
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	var actual []structs.NumberPoint
+	err = json.Unmarshal([]byte(requestData.Body), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") || !assert.NotEmpty(t, actual, "expected self metric points") {
+		return
+	}
+
+	metrics := map[string]bool{}
+	for _, point := range actual {
+		assert.True(t, strings.HasPrefix(point.Metric, "test_timeline_"), "expected the configured prefix")
+		assert.Equal(t, "http", point.Tags["transport"], "expected the transport tag")
+		metrics[point.Metric] = true
+	}
+
+	assert.True(t, metrics["test_timeline_points_sent"], "expected the points sent metric")
+	assert.True(t, metrics["test_timeline_buffer_depth"], "expected the buffer depth metric")
+	assert.True(t, metrics["test_timeline_cardinality_exceeded"], "expected the cardinality exceeded metric")
+}
+
+// TestSelfMetricsValidation - tests the self metrics parameters validation
+func TestSelfMetricsValidation(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	assert.Error(t, m.EnableSelfMetrics(0, "test_timeline"), "expected an error with no interval")
+	assert.Error(t, m.EnableSelfMetrics(time.Second, ""), "expected an error with no prefix")
+}
@@ -0,0 +1,94 @@
+package timeline_http_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline synchronous send tests.
+**/
+
+// TestSendSync - tests if the point reaches the backend before the call returns
+func TestSendSync(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	number := newNumberPoint(1)
+
+	err := m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending number") {
+		return
+	}
+
+	var requestData *httpserver.RequestData
+
+	select {
+	case requestData = <-s.RequestChannel():
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	testRequestData(t, requestData, []*structs.NumberPoint{number}, true)
+
+	stats := m.Stats()
+	assert.Equal(t, uint64(1), stats.PointsSent, "expected one point sent")
+	assert.Equal(t, 0, stats.BufferedPoints, "expected no buffered points")
+}
+
+// TestSendSyncError - tests if the backend error is returned
+func TestSendSyncError(t *testing.T) {
+
+	transport := createHTTPTransport()
+
+	m, err := timeline.NewManager(transport, &timeline.Backend{Host: httpserver.TestServerHost, Port: hungBackendPort + 1})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	err = m.SendHTTPSync(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	assert.Error(t, err, "expected an error with no backend")
+	assert.Equal(t, uint64(1), m.Stats().SendErrors, "expected one send error")
+}
+
+// TestSendSyncResult - tests if the send result has the delivery details
+func TestSendSyncResult(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = time.Hour
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	result := m.SendHTTPSyncResult(context.Background(), numberPoint, toGenericParametersN(newNumberPoint(1))...)
+	if !assert.NoError(t, result.Err, "no error expected when sending number") {
+		return
+	}
+
+	assert.Equal(t, 1, result.PointsSent, "expected one point sent")
+	assert.Equal(t, 1, result.BatchesSent, "expected one batch sent")
+	assert.True(t, result.Duration > 0, "expected the send duration")
+
+	httpserver.WaitForHTTPServerRequest(s)
+
+	result = m.SendHTTPSyncResult(context.Background(), "unknown", toGenericParametersN(newNumberPoint(1))...)
+	assert.Error(t, result.Err, "expected an error with an unknown schema")
+	assert.Equal(t, 0, result.PointsSent, "expected no points sent")
+	assert.Equal(t, 0, result.BatchesSent, "expected no batches sent")
+}
@@ -0,0 +1,469 @@
+package timeline_http_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline tag transform tests.
+**/
+
+// hashTag - hashes a tag value using sha256
+func hashTag(value string) string {
+
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// TestTagTransform - tests if the tag transform is applied to the sent points without changing the original ones
+func TestTagTransform(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	m.SetTagTransform("userID", hashTag)
+
+	number := newNumberPoint(1)
+	number.Tags["userID"] = "john.doe"
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	assert.Equal(t, "john.doe", number.Tags["userID"], "expected the original tags to be unchanged")
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	var actual []structs.NumberPoint
+	err = json.Unmarshal([]byte(requestData.Body), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") || !assert.Len(t, actual, 1, "expected one point") {
+		return
+	}
+
+	assert.Equal(t, hashTag("john.doe"), actual[0].Tags["userID"], "expected the hashed tag value")
+	assert.Equal(t, "number-test", actual[0].Tags["customTag"], "expected the other tags to be unchanged")
+}
+
+// TestTagTransformDrop - tests if a tag is dropped when the transform returns an empty value
+func TestTagTransformDrop(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	m.SetTagTransform("customTag", func(string) string { return "" })
+
+	number := newNumberPoint(1)
+
+	text, err := m.SerializeHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when serializing number") {
+		return
+	}
+
+	var actual structs.NumberPoint
+	err = json.Unmarshal([]byte(text), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") {
+		return
+	}
+
+	_, exists := actual.Tags["customTag"]
+	assert.False(t, exists, "expected the tag to be dropped")
+	assert.Equal(t, "number", actual.Tags["type"], "expected the other tags to be kept")
+	assert.Equal(t, "number-test", number.Tags["customTag"], "expected the original tags to be unchanged")
+
+	m.SetTagTransform("customTag", nil)
+
+	text, err = m.SerializeHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when serializing number") {
+		return
+	}
+
+	assert.True(t, testSerializeCompareNumber(t, fmt.Sprintf("[%s]", text), []*structs.NumberPoint{number}), "expected the transform to be removed")
+}
+
+// serializeTags - serializes a number point returning the resulting tags
+func serializeTags(t *testing.T, m *timeline.Manager, number *structs.NumberPoint) (map[string]string, error) {
+
+	text, err := m.SerializeHTTP(numberPoint, toGenericParametersN(number)...)
+	if err != nil {
+		return nil, err
+	}
+
+	var actual structs.NumberPoint
+	err = json.Unmarshal([]byte(text), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") {
+		return nil, err
+	}
+
+	return actual.Tags, nil
+}
+
+// sendTags - sends the number point tracking its tag values and returns the tags of the serialized point
+func sendTags(t *testing.T, m *timeline.Manager, number *structs.NumberPoint) (map[string]string, error) {
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return serializeTags(t, m, number)
+}
+
+// TestTagLengthTruncate - tests the truncation of over-long tag keys and values
+func TestTagLengthTruncate(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetTagLengthLimits(timeline.TagLengthLimits{
+		MaxTagKeyLength:   8,
+		MaxTagValueLength: 10,
+		Mode:              timeline.TruncateTag,
+		TruncationMarker:  "~",
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	number := newNumberPoint(1)
+	number.Tags["description"] = "a very long tag value"
+
+	tags, err := serializeTags(t, m, number)
+	if !assert.NoError(t, err, "no error expected when serializing number") {
+		return
+	}
+
+	assert.Len(t, tags, 3, "expected all tags")
+	assert.Equal(t, "a very lo~", tags["descrip~"], "expected the truncated key and value")
+	assert.Equal(t, "number-te~", tags["customT~"], "expected the truncated key and value")
+	assert.Equal(t, "number", tags["type"], "expected the short tag to be unchanged")
+	assert.Equal(t, "a very long tag value", number.Tags["description"], "expected the original tags to be unchanged")
+}
+
+// TestTagLengthReject - tests the rejection of points with over-long tag values
+func TestTagLengthReject(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetTagLengthLimits(timeline.TagLengthLimits{
+		MaxTagValueLength: 11,
+		Mode:              timeline.RejectTag,
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	number := newNumberPoint(1)
+
+	tags, err := serializeTags(t, m, number)
+	if !assert.NoError(t, err, "no error expected when serializing number") {
+		return
+	}
+
+	assert.Equal(t, number.Tags, tags, "expected the tags to be unchanged")
+
+	number.Tags["description"] = "a very long tag value"
+
+	_, err = serializeTags(t, m, number)
+	assert.Error(t, err, "expected an error with an over-long tag value")
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	assert.Error(t, err, "expected an error sending a point with an over-long tag value")
+
+	err = m.SetTagLengthLimits(timeline.TagLengthLimits{MaxTagValueLength: -1})
+	assert.Error(t, err, "expected an error with a negative length")
+}
+
+// TestDefaultTagsFromEnv - tests the default tags resolved from the environment variables
+func TestDefaultTagsFromEnv(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	os.Setenv("TIMELINE_TEST_DEPLOY_ENV", "production")
+	defer os.Unsetenv("TIMELINE_TEST_DEPLOY_ENV")
+	os.Unsetenv("TIMELINE_TEST_VERSION")
+
+	m.SetDefaultTagsFromEnv(map[string]string{
+		"env":     "TIMELINE_TEST_DEPLOY_ENV",
+		"version": "TIMELINE_TEST_VERSION",
+		"type":    "TIMELINE_TEST_DEPLOY_ENV",
+	})
+
+	os.Setenv("TIMELINE_TEST_DEPLOY_ENV", "staging")
+
+	number := newNumberPoint(1)
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if !assert.NoError(t, err, "no error expected when sending number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	var actual []structs.NumberPoint
+	err = json.Unmarshal([]byte(requestData.Body), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") || !assert.Len(t, actual, 1, "expected one point") {
+		return
+	}
+
+	assert.Equal(t, "production", actual[0].Tags["env"], "expected the value resolved when setting the default tags")
+	assert.Equal(t, timeline.UnsetEnvTagValue, actual[0].Tags["version"], "expected the fallback value")
+	assert.Equal(t, "number", actual[0].Tags["type"], "expected the point tag to have precedence")
+	assert.Equal(t, "number-test", actual[0].Tags["customTag"], "expected the point tags")
+	assert.Len(t, number.Tags, 2, "expected the original tags to be unchanged")
+}
+
+// TestRequireTags - tests if the points missing a tag required by the metric are rejected
+func TestRequireTags(t *testing.T) {
+
+	m := createTimelineManager(false)
+	m.RequireTags("number-metric", "host")
+
+	number := newNumberPoint(1)
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	if assert.Error(t, err, "expected an error sending a point without the required tag") {
+		assert.Contains(t, err.Error(), "host", "expected the missing tag in the error")
+	}
+
+	err = m.FlattenHTTP(timeline.Sum, numberPoint, toGenericParametersN(number)...)
+	assert.Error(t, err, "expected an error flattening a point without the required tag")
+
+	other := newNumberPoint(1)
+	other.Metric = "other-metric"
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(other)...)
+	assert.NoError(t, err, "expected no error sending a point of a metric without requirements")
+
+	m.SetDefaultTags(map[string]string{"host": "default-host"})
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	assert.NoError(t, err, "expected the required tag from the default tags")
+
+	m.SetDefaultTags(nil)
+	m.RequireTags("number-metric")
+
+	err = m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	assert.NoError(t, err, "expected no error after removing the requirement")
+}
+
+// TestCardinalityDropTag - tests if the tag values exceeding the cardinality limit are dropped
+func TestCardinalityDropTag(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetCardinalityLimits(timeline.CardinalityLimits{
+		MaxTagValues: 3,
+		Mode:         timeline.DropCardinalityTag,
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	for i := 0; i < 10; i++ {
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = fmt.Sprintf("request-%d", i)
+
+		tags, err := sendTags(t, m, number)
+		if !assert.NoError(t, err, "no error expected when sending number") {
+			return
+		}
+
+		if i < 3 {
+			assert.Equal(t, number.Tags["requestID"], tags["requestID"], "expected the tag within the limit")
+		} else {
+			assert.NotContains(t, tags, "requestID", "expected the tag exceeding the limit to be dropped")
+		}
+
+		assert.Equal(t, "number", tags["type"], "expected the low cardinality tag to be kept")
+	}
+
+	number := newNumberPoint(1)
+	number.Tags["requestID"] = "request-0"
+
+	tags, err := sendTags(t, m, number)
+	if assert.NoError(t, err, "no error expected when sending number") {
+		assert.Equal(t, "request-0", tags["requestID"], "expected the known value to be kept")
+	}
+
+	assert.Equal(t, uint64(7), m.CardinalityExceeded(), "expected the guard to trip for each new value")
+}
+
+// TestCardinalityDropPoint - tests if the points exceeding the cardinality limit are rejected
+func TestCardinalityDropPoint(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetCardinalityLimits(timeline.CardinalityLimits{
+		MaxTagValues: 2,
+		Mode:         timeline.DropCardinalityPoint,
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = fmt.Sprintf("request-%d", i)
+
+		_, err := sendTags(t, m, number)
+		if i < 2 {
+			assert.NoError(t, err, "no error expected within the limit")
+		} else {
+			assert.Error(t, err, "expected an error exceeding the limit")
+		}
+	}
+
+	assert.Equal(t, uint64(3), m.CardinalityExceeded(), "expected the guard to trip for each new value")
+
+	for i := 0; i < 2; i++ {
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = "request-0"
+		number.Tags["sessionID"] = fmt.Sprintf("session-%d", i)
+		number.Tags["userID"] = fmt.Sprintf("user-%d", i)
+
+		_, err := sendTags(t, m, number)
+		assert.NoError(t, err, "no error expected within the limit")
+	}
+
+	number := newNumberPoint(1)
+	number.Tags["requestID"] = "request-5"
+	number.Tags["sessionID"] = "session-5"
+	number.Tags["userID"] = "user-5"
+
+	_, err = sendTags(t, m, number)
+	assert.Error(t, err, "expected an error with all the tags exceeding the limit")
+
+	assert.Equal(t, uint64(4), m.CardinalityExceeded(), "expected the guard to trip once for each rejected point")
+
+	err = m.SetCardinalityLimits(timeline.CardinalityLimits{MaxTagValues: -1})
+	assert.Error(t, err, "expected an error with a negative limit")
+
+	err = m.SetCardinalityLimits(timeline.CardinalityLimits{})
+	if assert.NoError(t, err, "no error expected disabling the guard") {
+		assert.Equal(t, uint64(0), m.CardinalityExceeded(), "expected no counter with the guard disabled")
+	}
+}
+
+// TestCardinalityDropPointKeepsValues - tests if a rejected point does not track the new values of its other tags
+func TestCardinalityDropPointKeepsValues(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetCardinalityLimits(timeline.CardinalityLimits{
+		MaxTagValues: 1,
+		Mode:         timeline.DropCardinalityPoint,
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	number := newNumberPoint(1)
+	number.Tags["requestID"] = "request-0"
+
+	_, err = sendTags(t, m, number)
+	if !assert.NoError(t, err, "no error expected within the limit") {
+		return
+	}
+
+	for i := 0; i < 50; i++ {
+
+		host := fmt.Sprintf("host%d", i)
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = fmt.Sprintf("request-%d", i+1)
+		number.Tags[host] = "rejected"
+
+		_, err := sendTags(t, m, number)
+		assert.Error(t, err, "expected an error exceeding the limit")
+
+		number = newNumberPoint(1)
+		number.Tags["requestID"] = "request-0"
+		number.Tags[host] = "accepted"
+
+		tags, err := sendTags(t, m, number)
+		if assert.NoError(t, err, "expected the value of the rejected point not to be tracked") {
+			assert.Equal(t, "accepted", tags[host], "expected the new value to be kept")
+		}
+	}
+
+	assert.Equal(t, uint64(50), m.CardinalityExceeded(), "expected the guard to trip for each rejected point")
+}
+
+// TestCardinalitySerialize - tests if serializing a point checks the cardinality limit without tracking its values
+func TestCardinalitySerialize(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	err := m.SetCardinalityLimits(timeline.CardinalityLimits{
+		MaxTagValues: 2,
+		Mode:         timeline.DropCardinalityTag,
+	})
+	if !assert.NoError(t, err, "no error expected setting the limits") {
+		return
+	}
+
+	for i := 0; i < 5; i++ {
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = fmt.Sprintf("request-%d", i)
+
+		tags, err := serializeTags(t, m, number)
+		if assert.NoError(t, err, "no error expected when serializing number") {
+			assert.Equal(t, number.Tags["requestID"], tags["requestID"], "expected the serialized value not to be tracked")
+		}
+	}
+
+	assert.Equal(t, uint64(0), m.CardinalityExceeded(), "expected no counter serializing the points")
+
+	for i := 0; i < 2; i++ {
+
+		number := newNumberPoint(1)
+		number.Tags["requestID"] = fmt.Sprintf("sent-%d", i)
+
+		_, err := sendTags(t, m, number)
+		if !assert.NoError(t, err, "no error expected when sending number") {
+			return
+		}
+	}
+
+	number := newNumberPoint(1)
+	number.Tags["requestID"] = "request-0"
+
+	tags, err := serializeTags(t, m, number)
+	if assert.NoError(t, err, "no error expected when serializing number") {
+		assert.NotContains(t, tags, "requestID", "expected the serialized tag exceeding the limit to be dropped")
+	}
+
+	assert.Equal(t, uint64(0), m.CardinalityExceeded(), "expected no counter serializing the points")
+}
@@ -22,12 +22,18 @@ import (
 // createTimelineManager - creates a new timeline manager
 func createTimelineManager(start bool) *timeline.Manager {
 
+	return createTimelineManagerWithConfig(createHTTPTransportConfig(), start)
+}
+
+// createTimelineManagerWithConfig - creates a new timeline manager using the specified transport configuration
+func createTimelineManagerWithConfig(conf *timeline.HTTPTransportConfig, start bool) *timeline.Manager {
+
 	backend := timeline.Backend{
 		Host: httpserver.TestServerHost,
 		Port: httpserver.TestServerPort,
 	}
 
-	transport := createHTTPTransport()
+	transport := createHTTPTransportWithConfig(conf)
 
 	manager, err := timeline.NewManager(transport, &backend)
 	if err != nil {
@@ -293,7 +299,10 @@ func TestSendCustomNumber(t *testing.T) {
 
 	number := newNumberPoint(1.0)
 
-	transport := m.GetTransport().(*timeline.HTTPTransport)
+	transport, ok := m.GetHTTPTransport()
+	if !assert.True(t, ok, "expected a http transport") {
+		return
+	}
 
 	const custom = "customPoint"
 
@@ -329,7 +338,10 @@ func TestSendCustomText(t *testing.T) {
 
 	text := newTextPoint("woohoo")
 
-	transport := m.GetTransport().(*timeline.HTTPTransport)
+	transport, ok := m.GetHTTPTransport()
+	if !assert.True(t, ok, "expected a http transport") {
+		return
+	}
 
 	const custom = "customPoint"
 
@@ -385,3 +397,44 @@ func TestTextSerialization(t *testing.T) {
 
 	testSerializeCompareText(t, fmt.Sprintf("[%s]", serialized), []*structs.TextPoint{text})
 }
+
+// TestSendMixedPoints - tests if the number and text points are sent in the same request
+func TestSendMixedPoints(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	number := newNumberPoint(1)
+	text := newTextPoint("mixed")
+
+	err := m.SendHTTP(numberPoint, toGenericParametersN(number)...)
+	assert.NoError(t, err, "no error expected when sending number")
+
+	err = m.SendHTTP(textPoint, toGenericParametersT(text)...)
+	assert.NoError(t, err, "no error expected when sending text")
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	var actual []map[string]interface{}
+	err = json.Unmarshal([]byte(requestData.Body), &actual)
+	if !assert.NoError(t, err, "error unmarshalling the mixed points") || !assert.Len(t, actual, 2, "expected both points in one request") {
+		return
+	}
+
+	assert.Equal(t, number.Value, actual[0]["value"], "expected the number point first")
+	assert.Equal(t, text.Text, actual[1]["text"], "expected the text point last")
+
+	select {
+	case <-s.RequestChannel():
+		assert.Fail(t, "expected only one request")
+	case <-time.After(1500 * time.Millisecond):
+	}
+}
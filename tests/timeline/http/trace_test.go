@@ -0,0 +1,78 @@
+package timeline_http_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+)
+
+/**
+* The timeline trace id propagation tests.
+**/
+
+// traceContextKey - the context key type used to store the trace id
+type traceContextKey struct{}
+
+// TestTraceIDTag - tests if the context trace id is added as a tag
+func TestTraceIDTag(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	err := m.SetTraceIDTag(traceContextKey{}, "traceID")
+	if !assert.NoError(t, err, "no error expected setting the trace id tag") {
+		return
+	}
+
+	ctx := context.WithValue(context.Background(), traceContextKey{}, "4bf92f3577b34da6")
+
+	traced := newNumberPoint(1)
+	err = m.SendHTTPCtx(ctx, numberPoint, toGenericParametersN(traced)...)
+	if !assert.NoError(t, err, "no error expected when sending the traced number") {
+		return
+	}
+
+	untraced := newNumberPoint(2)
+	err = m.SendHTTPCtx(context.Background(), numberPoint, toGenericParametersN(untraced)...)
+	if !assert.NoError(t, err, "no error expected when sending the untraced number") {
+		return
+	}
+
+	<-time.After(2 * time.Second)
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "request data cannot be null") {
+		return
+	}
+
+	var actual []structs.NumberPoint
+	err = json.Unmarshal([]byte(requestData.Body), &actual)
+	if !assert.NoError(t, err, "error unmarshalling to number point") || !assert.Len(t, actual, 2, "expected two points") {
+		return
+	}
+
+	assert.Equal(t, "4bf92f3577b34da6", actual[0].Tags["traceID"], "expected the trace id tag")
+	assert.Equal(t, "number-test", actual[0].Tags["customTag"], "expected the point tags")
+	_, exists := actual[1].Tags["traceID"]
+	assert.False(t, exists, "expected no trace id tag without a trace id in the context")
+	_, exists = traced.Tags["traceID"]
+	assert.False(t, exists, "expected the original tags to be unchanged")
+}
+
+// TestTraceIDTagValidation - tests the trace id tag configuration validation
+func TestTraceIDTagValidation(t *testing.T) {
+
+	m := createTimelineManager(false)
+
+	assert.Error(t, m.SetTraceIDTag(nil, "traceID"), "expected an error with no context key")
+	assert.Error(t, m.SetTraceIDTag(traceContextKey{}, ""), "expected an error with no tag key")
+}
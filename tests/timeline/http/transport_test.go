@@ -0,0 +1,198 @@
+package timeline_http_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline transport tests.
+**/
+
+// nopProducer - a kafka producer doing nothing
+type nopProducer struct{}
+
+// Produce - does nothing
+func (p *nopProducer) Produce(topic string, key, value []byte) error { return nil }
+
+// Close - does nothing
+func (p *nopProducer) Close() error { return nil }
+
+// TestTransportNames - tests the name of each transport
+func TestTransportNames(t *testing.T) {
+
+	defaultConf := timeline.DefaultTransportConfiguration{
+		RequestTimeout:       time.Second,
+		BatchSendInterval:    time.Second,
+		TransportBufferSize:  1024,
+		SerializerBufferSize: 5,
+	}
+
+	openTSDBTransport, err := timeline.NewOpenTSDBTransport(&timeline.OpenTSDBTransportConfig{
+		DefaultTransportConfiguration: defaultConf,
+		MaxReadTimeout:                time.Second,
+		ReconnectionTimeout:           time.Second,
+	})
+	if !assert.NoError(t, err, "no error expected creating the opentsdb transport") {
+		return
+	}
+
+	promTransport, err := timeline.NewPromRemoteWriteTransport(&timeline.PromRemoteWriteTransportConfig{
+		DefaultTransportConfiguration: defaultConf,
+		ServiceEndpoint:               "/api/v1/write",
+	})
+	if !assert.NoError(t, err, "no error expected creating the prometheus transport") {
+		return
+	}
+
+	kafkaTransport, err := timeline.NewKafkaTransport(&timeline.KafkaTransportConfig{
+		DefaultTransportConfiguration: defaultConf,
+		Topic:                         "metrics",
+	}, &nopProducer{})
+	if !assert.NoError(t, err, "no error expected creating the kafka transport") {
+		return
+	}
+
+	assert.Equal(t, "http", createHTTPTransport().Name(), "unexpected http transport name")
+	assert.Equal(t, "opentsdb", openTSDBTransport.Name(), "unexpected opentsdb transport name")
+	assert.Equal(t, "prometheus", promTransport.Name(), "unexpected prometheus transport name")
+	assert.Equal(t, "kafka", kafkaTransport.Name(), "unexpected kafka transport name")
+}
+
+// TestStats - tests the manager statistics
+func TestStats(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	m := createTimelineManager(true)
+	defer m.Shutdown()
+
+	assert.Equal(t, timeline.Stats{Transport: "http"}, m.Stats(), "expected empty statistics")
+
+	numbers := []*structs.NumberPoint{newNumberPoint(1), newNumberPoint(2)}
+
+	for _, n := range numbers {
+		err := m.SendHTTP(numberPoint, toGenericParametersN(n)...)
+		assert.NoError(t, err, "no error expected when sending number")
+	}
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !testRequestData(t, requestData, numbers, true) {
+		return
+	}
+
+	<-time.After(100 * time.Millisecond)
+
+	stats := m.Stats()
+	assert.True(t, stats.LastSendLatency > 0, "expected the send latency")
+
+	expected := timeline.Stats{
+		Transport:        "http",
+		PointsSent:       2,
+		BatchesSent:      1,
+		SkippedIntervals: stats.SkippedIntervals,
+		LastSendLatency:  stats.LastSendLatency,
+	}
+
+	assert.Equal(t, expected, stats, "unexpected statistics")
+}
+
+// batchReport - the values reported by the batch sent callback
+type batchReport struct {
+	count    int
+	duration time.Duration
+	err      error
+}
+
+// TestOnBatchSent - tests if the callback reports the sent batch
+func TestOnBatchSent(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	transport := createHTTPTransport()
+
+	reports := make(chan batchReport, 1)
+	transport.OnBatchSent(func(count int, duration time.Duration, err error) {
+		reports <- batchReport{count, duration, err}
+	})
+
+	m, err := timeline.NewManager(transport, &timeline.Backend{Host: httpserver.TestServerHost, Port: httpserver.TestServerPort})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	err = m.Start()
+	if !assert.NoError(t, err, "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	for i := 0; i < 3; i++ {
+		err = m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected when sending number")
+	}
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, 3, report.count, "expected the number of points in the batch")
+		assert.NoError(t, report.err, "expected no error in the batch")
+		assert.True(t, report.duration > 0, "expected the batch duration")
+	case <-time.After(3 * time.Second):
+		assert.Fail(t, "expected the callback to be called")
+	}
+}
+
+// TestBufferLen - tests the buffer length growing while the points are not sent
+func TestBufferLen(t *testing.T) {
+
+	transport := createHTTPTransport()
+
+	m, err := timeline.NewManager(transport, &timeline.Backend{Host: httpserver.TestServerHost, Port: httpserver.TestServerPort})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	assert.Equal(t, 1024, m.BufferCap(), "expected the configured buffer size")
+	assert.Equal(t, 1024, transport.BufferCap(), "expected the configured buffer size")
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, i, m.BufferLen(), "expected the buffered points")
+
+		err = m.SendHTTP(numberPoint, toGenericParametersN(newNumberPoint(float64(i)))...)
+		assert.NoError(t, err, "no error expected when sending number")
+
+		assert.Equal(t, i+1, transport.BufferLen(), "expected the buffered points")
+	}
+}
+
+// TestSkipEmptyIntervals - tests if no request is sent when the buffer is empty
+func TestSkipEmptyIntervals(t *testing.T) {
+
+	s := createTimeseriesBackend()
+	defer s.Close()
+
+	conf := createHTTPTransportConfig()
+	conf.BatchSendInterval = 100 * time.Millisecond
+
+	m := createTimelineManagerWithConfig(conf, true)
+	defer m.Shutdown()
+
+	select {
+	case <-s.RequestChannel():
+		assert.Fail(t, "expected no requests with an empty buffer")
+	case <-time.After(650 * time.Millisecond):
+	}
+
+	stats := m.Stats()
+	assert.True(t, stats.SkippedIntervals >= 5, "expected the skipped intervals to be counted: %d", stats.SkippedIntervals)
+	assert.Equal(t, uint64(0), stats.BatchesSent, "expected no batches")
+}
@@ -0,0 +1,343 @@
+package timeline_kafka_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+	serializer "github.com/uol/serializer/opentsdb"
+)
+
+/**
+* The timeline kafka transport tests.
+**/
+
+const testTopic = "metrics"
+
+// message - a produced message
+type message struct {
+	topic string
+	key   string
+	value string
+}
+
+// fakeProducer - captures all produced messages
+type fakeProducer struct {
+	messages []message
+	mutex    sync.Mutex
+	closed   bool
+}
+
+// Produce - captures the message
+func (p *fakeProducer) Produce(topic string, key, value []byte) error {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.messages = append(p.messages, message{topic: topic, key: string(key), value: string(value)})
+
+	return nil
+}
+
+// Close - marks the producer as closed
+func (p *fakeProducer) Close() error {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.closed = true
+
+	return nil
+}
+
+// failingProducer - a producer failing every operation
+type failingProducer struct{}
+
+// Produce - always fails
+func (p *failingProducer) Produce(topic string, key, value []byte) error {
+
+	return fmt.Errorf("broker unavailable")
+}
+
+// Close - always fails
+func (p *failingProducer) Close() error {
+
+	return fmt.Errorf("producer already closed")
+}
+
+// flakyProducer - a producer failing the first message of a metric with the error (a network error if not set)
+type flakyProducer struct {
+	fakeProducer
+	metric string
+	err    error
+	failed bool
+}
+
+// Produce - fails the first message of the metric
+func (p *flakyProducer) Produce(topic string, key, value []byte) error {
+
+	p.mutex.Lock()
+	if !p.failed && string(key) == p.metric {
+		p.failed = true
+		p.mutex.Unlock()
+		if p.err != nil {
+			return p.err
+		}
+		return &net.OpError{Op: "write", Net: "tcp", Err: fmt.Errorf("broken pipe")}
+	}
+	p.mutex.Unlock()
+
+	return p.fakeProducer.Produce(topic, key, value)
+}
+
+// getMessages - returns a copy of the produced messages
+func (p *fakeProducer) getMessages() []message {
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return append([]message{}, p.messages...)
+}
+
+// createTimelineManager - creates a new timeline manager using the kafka transport
+func createTimelineManager(producer timeline.KafkaProducer, configure ...func(*timeline.KafkaTransportConfig)) *timeline.Manager {
+
+	configuration := &timeline.KafkaTransportConfig{
+		DefaultTransportConfiguration: timeline.DefaultTransportConfiguration{
+			RequestTimeout:       time.Second,
+			BatchSendInterval:    500 * time.Millisecond,
+			TransportBufferSize:  1024,
+			SerializerBufferSize: 5,
+		},
+		Topic: testTopic,
+	}
+
+	for _, c := range configure {
+		c(configuration)
+	}
+
+	transport, err := timeline.NewKafkaTransport(configuration, producer)
+	if err != nil {
+		panic(err)
+	}
+
+	manager, err := timeline.NewManager(transport, &timeline.Backend{})
+	if err != nil {
+		panic(err)
+	}
+
+	err = manager.Start()
+	if err != nil {
+		panic(err)
+	}
+
+	return manager
+}
+
+// serialize - serializes a point using the manager
+func serialize(m *timeline.Manager, value float64, timestamp int64, metric string, tags ...interface{}) string {
+
+	serialized, err := m.SerializeOpenTSDB(value, timestamp, metric, tags...)
+	if err != nil {
+		panic(err)
+	}
+
+	return serialized
+}
+
+// TestProduceByMetric - tests the messages produced for each metric
+func TestProduceByMetric(t *testing.T) {
+
+	producer := &fakeProducer{}
+
+	m := createTimelineManager(producer)
+
+	now := time.Now().Unix()
+
+	assert.NoError(t, m.SendOpenTSDB(1, now, "metric1", "host", "a"), "no error expected sending metric1")
+	assert.NoError(t, m.SendOpenTSDB(2, now, "metric2", "host", "a"), "no error expected sending metric2")
+	assert.NoError(t, m.SendOpenTSDB(3, now, "metric1", "host", "b"), "no error expected sending metric1")
+
+	<-time.After(time.Second)
+
+	messages := producer.getMessages()
+	if !assert.Len(t, messages, 2, "expected one message per metric") {
+		return
+	}
+
+	expected := []message{
+		{
+			topic: testTopic,
+			key:   "metric1",
+			value: serialize(m, 1, now, "metric1", "host", "a") + serialize(m, 3, now, "metric1", "host", "b"),
+		},
+		{
+			topic: testTopic,
+			key:   "metric2",
+			value: serialize(m, 2, now, "metric2", "host", "a"),
+		},
+	}
+
+	assert.Equal(t, expected, messages, "unexpected produced messages")
+
+	m.Shutdown()
+
+	producer.mutex.Lock()
+	defer producer.mutex.Unlock()
+
+	assert.True(t, producer.closed, "expected the producer to be closed")
+}
+
+// TestNoProducer - tests the transport creation without a producer
+func TestNoProducer(t *testing.T) {
+
+	_, err := timeline.NewKafkaTransport(&timeline.KafkaTransportConfig{
+		DefaultTransportConfiguration: timeline.DefaultTransportConfiguration{
+			RequestTimeout:       time.Second,
+			BatchSendInterval:    time.Second,
+			TransportBufferSize:  1024,
+			SerializerBufferSize: 5,
+		},
+		Topic: testTopic,
+	}, nil)
+
+	assert.Error(t, err, "expected an error without a producer")
+}
+
+// TestShutdownFlush - tests if the buffered points are sent when shutting down
+func TestShutdownFlush(t *testing.T) {
+
+	producer := &fakeProducer{}
+
+	m := createTimelineManager(producer)
+
+	now := time.Now().Unix()
+
+	assert.NoError(t, m.SendOpenTSDB(1, now, "metric1", "host", "a"), "no error expected sending metric1")
+
+	err := m.Shutdown()
+	if !assert.NoError(t, err, "no error expected shutting down") {
+		return
+	}
+
+	messages := producer.getMessages()
+	if !assert.Len(t, messages, 1, "expected the buffered point to be flushed") {
+		return
+	}
+
+	assert.Equal(t, "metric1", messages[0].key, "unexpected message key")
+}
+
+// TestShutdownErrors - tests if all errors found shutting down are returned
+func TestShutdownErrors(t *testing.T) {
+
+	m := createTimelineManager(&failingProducer{})
+
+	assert.NoError(t, m.SendOpenTSDB(1, time.Now().Unix(), "metric1", "host", "a"), "no error expected sending metric1")
+
+	err := m.Shutdown()
+	if !assert.Error(t, err, "expected an error shutting down") {
+		return
+	}
+
+	assert.Contains(t, err.Error(), "broker unavailable", "expected the final flush error")
+	assert.Contains(t, err.Error(), "producer already closed", "expected the producer close error")
+}
+
+// TestRetryFailedMetrics - tests if only the metrics failing with a network error are produced again
+func TestRetryFailedMetrics(t *testing.T) {
+
+	producer := &flakyProducer{metric: "metric2"}
+
+	m := createTimelineManager(producer, func(c *timeline.KafkaTransportConfig) {
+		c.MaxRetries = 1
+		c.RetryInterval = 10 * time.Millisecond
+	})
+	defer m.Shutdown()
+
+	now := time.Now().Unix()
+
+	assert.NoError(t, m.SendOpenTSDB(1, now, "metric1", "host", "a"), "no error expected sending metric1")
+	assert.NoError(t, m.SendOpenTSDB(2, now, "metric2", "host", "a"), "no error expected sending metric2")
+
+	<-time.After(time.Second)
+
+	messages := producer.getMessages()
+	if !assert.Len(t, messages, 2, "expected each metric to be produced once") {
+		return
+	}
+
+	assert.Equal(t, "metric1", messages[0].key, "expected the first metric produced in the first attempt")
+	assert.Equal(t, "metric2", messages[1].key, "expected the failed metric produced in the retry")
+}
+
+// TestRetryProducerErrors - tests if the producer errors are retried by the default retry classifier
+func TestRetryProducerErrors(t *testing.T) {
+
+	producer := &flakyProducer{metric: "metric1", err: fmt.Errorf("leader not available")}
+
+	m := createTimelineManager(producer, func(c *timeline.KafkaTransportConfig) {
+		c.MaxRetries = 1
+		c.RetryInterval = 10 * time.Millisecond
+	})
+	defer m.Shutdown()
+
+	assert.NoError(t, m.SendOpenTSDB(1, time.Now().Unix(), "metric1", "host", "a"), "no error expected sending metric1")
+
+	<-time.After(time.Second)
+
+	messages := producer.getMessages()
+	if assert.Len(t, messages, 1, "expected the metric produced in the retry") {
+		assert.Equal(t, "metric1", messages[0].key, "expected the failed metric")
+	}
+
+	assert.Equal(t, uint64(1), m.Stats().Retries, "expected one retry")
+
+	var producerErr *timeline.ProducerError
+	err := fmt.Errorf("error producing messages: %w", &timeline.ProducerError{Err: fmt.Errorf("leader not available")})
+	assert.True(t, errors.As(err, &producerErr), "expected a producer error")
+	assert.True(t, timeline.DefaultRetryClassifier(0, err), "expected the producer error to be retryable")
+}
+
+// TestTransferErrors - tests if the producer errors are wrapped with the points of the failed metrics
+func TestTransferErrors(t *testing.T) {
+
+	producer := &flakyProducer{metric: "metric2"}
+
+	transport, err := timeline.NewKafkaTransport(&timeline.KafkaTransportConfig{
+		DefaultTransportConfiguration: timeline.DefaultTransportConfiguration{
+			RequestTimeout:       time.Second,
+			BatchSendInterval:    time.Second,
+			TransportBufferSize:  1024,
+			SerializerBufferSize: 5,
+		},
+		Topic: testTopic,
+	}, producer)
+	if !assert.NoError(t, err, "no error expected creating the transport") {
+		return
+	}
+
+	now := time.Now().Unix()
+
+	err = transport.TransferData(context.Background(), []interface{}{
+		serializer.ArrayItem{Metric: "metric1", Timestamp: now, Value: 1, Tags: []interface{}{"host", "a"}},
+		serializer.ArrayItem{Metric: "metric2", Timestamp: now, Value: 2, Tags: []interface{}{"host", "a"}},
+	})
+	if !assert.Error(t, err, "expected the producer error") {
+		return
+	}
+
+	assert.True(t, timeline.DefaultRetryClassifier(0, err), "expected the network error to be retryable")
+
+	var partialErr *timeline.PartialTransferError
+	if assert.True(t, errors.As(err, &partialErr), "expected a partial transfer error") && assert.Len(t, partialErr.Failed, 1, "expected only the failed metric") {
+		assert.Equal(t, "metric2", partialErr.Failed[0].(serializer.ArrayItem).Metric, "expected the failed metric points")
+	}
+}
@@ -0,0 +1,157 @@
+package timeline_opentsdb_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+	serializer "github.com/uol/serializer/opentsdb"
+)
+
+/**
+* The timeline opentsdb batch deadline tests.
+**/
+
+// createHungBackend - creates a telnet backend accepting connections and never reading or responding
+func createHungBackend(port int) net.Listener {
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", telnetHost, port))
+	if err != nil {
+		panic(err)
+	}
+
+	go func() {
+		conns := []net.Conn{}
+
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			conns = append(conns, conn)
+		}
+	}()
+
+	return listener
+}
+
+// newTestItems - creates the data channel items of a batch
+func newTestItems(numPoints int) []interface{} {
+
+	items := make([]interface{}, numPoints)
+
+	for i := range items {
+		items[i] = serializer.ArrayItem{
+			Metric:    "metric",
+			Value:     float64(i),
+			Timestamp: time.Now().Unix(),
+			Tags:      []interface{}{"host", "test"},
+		}
+	}
+
+	return items
+}
+
+// TestTransferDataDeadline - tests a batch sent to a backend that never responds
+func TestTransferDataDeadline(t *testing.T) {
+
+	port := generatePort()
+
+	listener := createHungBackend(port)
+	defer listener.Close()
+
+	transport := createOpenTSDBTransport()
+
+	err := transport.ConfigureBackend(&timeline.Backend{Host: telnetHost, Port: port})
+	if !assert.NoError(t, err, "no error expected configuring the backend") {
+		return
+	}
+
+	defer transport.Close()
+
+	// a payload larger than the socket buffers (the write blocks as the backend never reads)
+	items := newTestItems(200000)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+
+	err = transport.TransferData(ctx, items)
+
+	elapsed := time.Since(start)
+
+	assert.Error(t, err, "expected a deadline error")
+	assert.True(t, elapsed >= 2*time.Second, "expected the send to wait until the context deadline")
+	assert.True(t, elapsed < 3*time.Second, "expected the send to stop at the context deadline")
+}
+
+// TestTransferDataBackendDown - tests if the reconnection stops at the context deadline when the backend is down
+func TestTransferDataBackendDown(t *testing.T) {
+
+	conf := createOpenTSDBTransportConfig()
+	conf.RequestTimeout = 300 * time.Millisecond
+
+	transport := createOpenTSDBTransportWithConfig(conf)
+
+	err := transport.ConfigureBackend(&timeline.Backend{Host: telnetHost, Port: generatePort()})
+	if !assert.NoError(t, err, "no error expected configuring the backend down") {
+		return
+	}
+
+	defer transport.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+
+	err = transport.TransferData(ctx, newTestItems(1))
+
+	elapsed := time.Since(start)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "expected the deadline error")
+	assert.True(t, elapsed < time.Second, "expected the reconnection to stop at the context deadline")
+}
+
+// TestSendSyncBackendDown - tests if the synchronous send returns when the backend is down
+func TestSendSyncBackendDown(t *testing.T) {
+
+	port := generatePort()
+
+	conf := createOpenTSDBTransportConfig()
+	conf.RequestTimeout = 300 * time.Millisecond
+
+	m, err := timeline.NewManager(createOpenTSDBTransportWithConfig(conf), &timeline.Backend{Host: telnetHost, Port: port})
+	if !assert.NoError(t, err, "no error expected creating the manager with the backend down") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	start := time.Now()
+
+	err = m.SendOpenTSDBSync(context.Background(), 1, time.Now().Unix(), "metric", "host", "test")
+	assert.Error(t, err, "expected an error sending to the backend down")
+
+	err = m.SendOpenTSDB(1, time.Now().Unix(), "metric", "host", "test")
+	assert.NoError(t, err, "no error expected buffering the point")
+
+	m.Shutdown()
+
+	assert.True(t, time.Since(start) < 2*time.Second, "expected the send and the shutdown to stop at the request timeout")
+}
@@ -0,0 +1,153 @@
+package timeline_opentsdb_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline opentsdb synchronous send tests (run them with -race).
+**/
+
+// droppingBackend - a telnet backend closing each connection after its first read
+type droppingBackend struct {
+	listener net.Listener
+	lines    []string
+	mutex    sync.Mutex
+}
+
+// newDroppingBackend - creates a telnet backend dropping every connection after its first read
+func newDroppingBackend(port int) *droppingBackend {
+
+	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", telnetHost, port))
+	if err != nil {
+		panic(err)
+	}
+
+	b := &droppingBackend{listener: listener}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			go b.handle(conn)
+		}
+	}()
+
+	return b
+}
+
+// handle - keeps the complete lines of the first read and drops the connection
+func (b *droppingBackend) handle(conn net.Conn) {
+
+	defer conn.Close()
+
+	buffer := make([]byte, maxBuffer)
+
+	if err := conn.SetReadDeadline(time.Now().Add(time.Second)); err != nil {
+		return
+	}
+
+	n, err := conn.Read(buffer)
+	if err != nil {
+		return
+	}
+
+	lines := strings.Split(string(buffer[:n]), "\n")
+
+	b.mutex.Lock()
+	b.lines = append(b.lines, lines[:len(lines)-1]...)
+	b.mutex.Unlock()
+}
+
+// received - returns the received lines
+func (b *droppingBackend) received() []string {
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	lines := make([]string, len(b.lines))
+	copy(lines, b.lines)
+
+	return lines
+}
+
+// TestSyncAndAsyncSends - tests the synchronous and the buffered sends sharing the connection dropped by the backend
+func TestSyncAndAsyncSends(t *testing.T) {
+
+	port := generatePort()
+
+	b := newDroppingBackend(port)
+	defer b.listener.Close()
+
+	conf := createOpenTSDBTransportConfig()
+	conf.BatchSendInterval = 10 * time.Millisecond
+	conf.MaxReadTimeout = 10 * time.Millisecond
+	conf.ReconnectionTimeout = 10 * time.Millisecond
+	conf.TransportBufferSize = 1024
+
+	m, err := timeline.NewManager(createOpenTSDBTransportWithConfig(conf), &timeline.Backend{Host: telnetHost, Port: port})
+	if !assert.NoError(t, err, "no error expected creating the manager") {
+		return
+	}
+
+	if !assert.NoError(t, m.Start(), "no error expected starting the manager") {
+		return
+	}
+
+	defer m.Shutdown()
+
+	numPoints := 50
+
+	wg := sync.WaitGroup{}
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < numPoints; i++ {
+			err := m.SendOpenTSDB(float64(i), time.Now().Unix(), "async", "host", "a")
+			assert.NoError(t, err, "no error expected sending the buffered point")
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < numPoints; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			err := m.SendOpenTSDBSync(ctx, float64(i), time.Now().Unix(), "sync", "host", "b")
+			cancel()
+
+			assert.NoError(t, err, "no error expected sending the synchronous point")
+		}
+	}()
+
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if !assert.NoError(t, m.Drain(ctx), "expected the buffered points drained") {
+		return
+	}
+
+	lines := b.received()
+	assert.NotEmpty(t, lines, "expected the backend to receive some points")
+
+	for _, line := range lines {
+		assert.Regexp(t, `^put (async \d+ [\d.]+ host=a|sync \d+ [\d.]+ host=b)$`, line, "expected no interleaved writes")
+	}
+}
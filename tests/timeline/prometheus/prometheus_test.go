@@ -0,0 +1,294 @@
+package timeline_prometheus_test
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/tester/httpserver"
+	"github.com/uol/gobol/timeline"
+)
+
+/**
+* The timeline prometheus remote-write transport tests.
+**/
+
+const (
+	testServerPort = 18081
+	remoteWriteURI = "/api/v1/write"
+)
+
+// series - a decoded remote-write series
+type series struct {
+	labels    map[string]string
+	value     float64
+	timestamp int64
+}
+
+// createRemoteWriteBackend - creates a new test server simulating a prometheus remote-write backend
+func createRemoteWriteBackend() *httpserver.HTTPServer {
+
+	server, err := httpserver.NewHTTPServer(httpserver.TestServerHost, testServerPort, 5, []httpserver.ResponseData{
+		{
+			RequestData: httpserver.RequestData{
+				URI:    remoteWriteURI,
+				Method: "POST",
+			},
+			Status: http.StatusNoContent,
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	return server
+}
+
+// createTimelineManager - creates a new timeline manager using the prometheus transport
+func createTimelineManager() *timeline.Manager {
+
+	return createPrecisionTimelineManager(structs.Seconds)
+}
+
+// createPrecisionTimelineManager - creates a new timeline manager using the prometheus transport and the timestamp precision
+func createPrecisionTimelineManager(precision structs.TimestampPrecision) *timeline.Manager {
+
+	transport, err := timeline.NewPromRemoteWriteTransport(&timeline.PromRemoteWriteTransportConfig{
+		DefaultTransportConfiguration: timeline.DefaultTransportConfiguration{
+			RequestTimeout:       time.Second,
+			BatchSendInterval:    time.Second,
+			TransportBufferSize:  1024,
+			SerializerBufferSize: 5,
+			TimestampPrecision:   precision,
+		},
+		ServiceEndpoint: remoteWriteURI,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	manager, err := timeline.NewManager(transport, &timeline.Backend{
+		Host: httpserver.TestServerHost,
+		Port: testServerPort,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	err = manager.Start()
+	if err != nil {
+		panic(err)
+	}
+
+	return manager
+}
+
+// readField - reads a protobuf field returning the field number, the wire type and the new offset
+func readField(t *testing.T, data []byte) (int, int, []byte) {
+
+	key, n := binary.Uvarint(data)
+	if !assert.True(t, n > 0, "expected a valid field key") {
+		t.FailNow()
+	}
+
+	return int(key >> 3), int(key & 7), data[n:]
+}
+
+// readBytes - reads a length delimited protobuf field
+func readBytes(t *testing.T, data []byte) ([]byte, []byte) {
+
+	length, n := binary.Uvarint(data)
+	if !assert.True(t, n > 0 && int(length) <= len(data[n:]), "expected a valid length") {
+		t.FailNow()
+	}
+
+	return data[n : n+int(length)], data[n+int(length):]
+}
+
+// decodeWriteRequest - decodes the remote-write protobuf message
+func decodeWriteRequest(t *testing.T, data []byte) []series {
+
+	result := []series{}
+
+	for len(data) > 0 {
+
+		var ts []byte
+		field, wireType, rest := readField(t, data)
+		assert.Equal(t, 1, field, "expected the timeseries field")
+		assert.Equal(t, 2, wireType, "expected a length delimited field")
+		ts, data = readBytes(t, rest)
+
+		s := series{labels: map[string]string{}}
+
+		for len(ts) > 0 {
+
+			var content []byte
+			field, _, rest = readField(t, ts)
+			content, ts = readBytes(t, rest)
+
+			if field == 1 {
+
+				values := []string{}
+				for len(content) > 0 {
+					var value []byte
+					_, _, rest = readField(t, content)
+					value, content = readBytes(t, rest)
+					values = append(values, string(value))
+				}
+
+				s.labels[values[0]] = values[1]
+
+				continue
+			}
+
+			for len(content) > 0 {
+				field, _, rest = readField(t, content)
+				if field == 1 {
+					s.value = math.Float64frombits(binary.LittleEndian.Uint64(rest))
+					content = rest[8:]
+				} else {
+					timestamp, n := binary.Uvarint(rest)
+					s.timestamp = int64(timestamp)
+					content = rest[n:]
+				}
+			}
+		}
+
+		result = append(result, s)
+	}
+
+	return result
+}
+
+// TestRemoteWrite - tests the series posted to the remote-write backend
+func TestRemoteWrite(t *testing.T) {
+
+	s := createRemoteWriteBackend()
+	defer s.Close()
+
+	m := createTimelineManager()
+	defer m.Shutdown()
+
+	now := time.Now().Unix()
+
+	err := m.SendOpenTSDB(10.5, now, "http_requests_total", "method", "get", "code", 200)
+	if !assert.NoError(t, err, "no error expected sending the first point") {
+		return
+	}
+
+	err = m.SendOpenTSDB(-3, now+1, "cpu_usage", "host", "host1")
+	if !assert.NoError(t, err, "no error expected sending the second point") {
+		return
+	}
+
+	requestData := httpserver.WaitForHTTPServerRequest(s)
+	if !assert.NotNil(t, requestData, "expected a request") {
+		return
+	}
+
+	assert.Equal(t, "snappy", requestData.Headers.Get("Content-Encoding"), "expected snappy encoding")
+	assert.Equal(t, "application/x-protobuf", requestData.Headers.Get("Content-Type"), "expected protobuf content")
+
+	decoded, err := snappy.Decode(nil, []byte(requestData.Body))
+	if !assert.NoError(t, err, "expected a snappy compressed body") {
+		return
+	}
+
+	expected := []series{
+		{
+			labels: map[string]string{
+				"__name__": "http_requests_total",
+				"method":   "get",
+				"code":     "200",
+			},
+			value:     10.5,
+			timestamp: now * 1000,
+		},
+		{
+			labels: map[string]string{
+				"__name__": "cpu_usage",
+				"host":     "host1",
+			},
+			value:     -3,
+			timestamp: (now + 1) * 1000,
+		},
+	}
+
+	assert.Equal(t, expected, decodeWriteRequest(t, decoded), "unexpected series")
+}
+
+// TestRemoteWritePrecision - tests if the timestamps are converted from the transport precision to milliseconds
+func TestRemoteWritePrecision(t *testing.T) {
+
+	now := time.Now()
+
+	for _, precision := range []structs.TimestampPrecision{structs.Seconds, structs.Milliseconds, structs.Nanoseconds} {
+
+		m := createPrecisionTimelineManager(precision)
+
+		serialized, err := m.SerializeOpenTSDB(1, precision.Timestamp(now), "cpu_usage", "host", "host1")
+		m.Shutdown()
+
+		if !assert.NoError(t, err, "no error expected serializing with precision %d", precision) {
+			return
+		}
+
+		decoded := decodeWriteRequest(t, []byte(serialized))
+		if !assert.Len(t, decoded, 1, "expected one series") {
+			return
+		}
+
+		expected := now.UnixNano() / int64(time.Millisecond)
+		if precision == structs.Seconds {
+			expected = now.Unix() * 1000
+		}
+
+		assert.Equal(t, expected, decoded[0].timestamp, "expected the timestamp in milliseconds with precision %d", precision)
+	}
+}
+
+// TestInvalidLabelName - tests the label name charset validation
+func TestInvalidLabelName(t *testing.T) {
+
+	m := createTimelineManager()
+	defer m.Shutdown()
+
+	_, err := m.SerializeOpenTSDB(1, time.Now().Unix(), "valid_metric", "invalid-label", "value")
+	assert.Error(t, err, "expected an error with an invalid label name")
+
+	_, err = m.SerializeOpenTSDB(1, time.Now().Unix(), "invalid.metric", "label", "value")
+	assert.Error(t, err, "expected an error with an invalid metric name")
+
+	serialized, err := m.SerializeOpenTSDB(1, time.Now().Unix(), "valid_metric", "label", "value")
+	if !assert.NoError(t, err, "no error expected with valid names") {
+		return
+	}
+
+	assert.Len(t, decodeWriteRequest(t, []byte(serialized)), 1, "expected one series")
+}
+
+// TestTypedTransportAccessors - tests if the typed transport accessors only return the configured transport
+func TestTypedTransportAccessors(t *testing.T) {
+
+	m := createTimelineManager()
+	defer m.Shutdown()
+
+	_, ok := m.GetHTTPTransport()
+	assert.False(t, ok, "expected no http transport")
+
+	_, ok = m.GetOpenTSDBTransport()
+	assert.False(t, ok, "expected no opentsdb transport")
+
+	_, ok = m.GetKafkaTransport()
+	assert.False(t, ok, "expected no kafka transport")
+
+	transport, ok := m.GetPromRemoteWriteTransport()
+	assert.True(t, ok, "expected the prometheus transport")
+	assert.Equal(t, m.GetTransport(), transport, "expected the configured transport")
+}
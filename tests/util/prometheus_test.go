@@ -0,0 +1,59 @@
+package util_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/uol/gobol/util"
+)
+
+/**
+* The util/prometheus library tests.
+**/
+
+// TestWritePrometheusMetrics - tests the text exposition format
+func TestWritePrometheusMetrics(t *testing.T) {
+
+	metrics := []util.PrometheusMetric{
+		{Name: "requests_total", Help: "The requests.", Type: util.PrometheusCounter, Labels: map[string]string{"path": "/a", "code": "200"}, Value: 10},
+		{Name: "requests_total", Help: "The requests.", Type: util.PrometheusCounter, Labels: map[string]string{"path": "/\"b\""}, Value: 2},
+		{Name: "temperature", Type: util.PrometheusGauge, Value: 0.5},
+	}
+
+	b := bytes.Buffer{}
+	if !assert.NoError(t, util.WritePrometheusMetrics(&b, metrics), "no error expected writing") {
+		return
+	}
+
+	expected := "# HELP requests_total The requests.\n" +
+		"# TYPE requests_total counter\n" +
+		"requests_total{code=\"200\",path=\"/a\"} 10\n" +
+		"requests_total{path=\"/\\\"b\\\"\"} 2\n" +
+		"# TYPE temperature gauge\n" +
+		"temperature 0.5\n"
+
+	assert.Equal(t, expected, b.String(), "unexpected exposition")
+}
+
+// TestPrometheusHandler - tests the handler content type and body
+func TestPrometheusHandler(t *testing.T) {
+
+	handler := util.PrometheusHandler(func(r *http.Request) []util.PrometheusMetric {
+		return []util.PrometheusMetric{{Name: "up", Type: util.PrometheusGauge, Value: 1}}
+	})
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body, err := ioutil.ReadAll(recorder.Body)
+	if !assert.NoError(t, err, "no error expected reading the body") {
+		return
+	}
+
+	assert.Equal(t, util.PrometheusContentType, recorder.Header().Get("Content-Type"), "expected the text format content type")
+	assert.Equal(t, "# TYPE up gauge\nup 1\n", string(body), "unexpected body")
+}
@@ -0,0 +1,116 @@
+package timeline
+
+import (
+	"fmt"
+	"sync"
+)
+
+/**
+* The weighted round-robin distribution of the batches across multiple backends.
+**/
+
+// MultiBackendTransport - a transport distributing the batches across multiple backends
+type MultiBackendTransport interface {
+
+	// ConfigureBackends - configures the backends receiving the batches proportionally to their weights
+	ConfigureBackends(backends []*Backend) error
+}
+
+// NewManagerMultiBackend - creates a timeline manager distributing the batches across the backends
+// (each backend receives a share of the batches proportional to its weight)
+func NewManagerMultiBackend(transport Transport, backends ...*Backend) (*Manager, error) {
+
+	if transport == nil {
+		return nil, fmt.Errorf("transport implementation is required")
+	}
+
+	mbt, ok := transport.(MultiBackendTransport)
+	if !ok {
+		return nil, fmt.Errorf("transport does not support multiple backends: %s", transport.Name())
+	}
+
+	if err := mbt.ConfigureBackends(backends); err != nil {
+		return nil, err
+	}
+
+	return &Manager{
+		transport:    transport,
+		loggers:      newManagerLoggers(transport),
+		shutdownChan: make(chan struct{}),
+	}, nil
+}
+
+// backendWeights - validates the backends returning their weights (zero weight means 1)
+func backendWeights(backends []*Backend) ([]int, error) {
+
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("no backend was configured")
+	}
+
+	weights := make([]int, len(backends))
+
+	for i, backend := range backends {
+		if backend == nil {
+			return nil, fmt.Errorf("no backend was configured at index: %d", i)
+		}
+
+		if backend.Weight < 0 {
+			return nil, fmt.Errorf("invalid backend weight: %d", backend.Weight)
+		}
+
+		weights[i] = backend.Weight
+		if weights[i] == 0 {
+			weights[i] = 1
+		}
+	}
+
+	return weights, nil
+}
+
+// weightedSelector - selects the indexes using the smooth weighted round-robin
+// (the selections of each index are evenly interleaved instead of grouped)
+type weightedSelector struct {
+	weights []int
+	current []int
+	total   int
+	mutex   sync.Mutex
+}
+
+// newWeightedSelector - creates a new selector
+func newWeightedSelector(weights []int) *weightedSelector {
+
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	return &weightedSelector{
+		weights: weights,
+		current: make([]int, len(weights)),
+		total:   total,
+	}
+}
+
+// next - returns the next selected index
+func (s *weightedSelector) next() int {
+
+	if len(s.weights) == 1 {
+		return 0
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	selected := 0
+
+	for i, w := range s.weights {
+		s.current[i] += w
+		if s.current[i] > s.current[selected] {
+			selected = i
+		}
+	}
+
+	s.current[selected] -= s.total
+
+	return selected
+}
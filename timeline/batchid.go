@@ -0,0 +1,75 @@
+package timeline
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+/**
+* Identifies the sent batches for the end-to-end tracing.
+**/
+
+// DefaultBatchIDHeader - the default http header containing the batch id
+const DefaultBatchIDHeader string = "X-Batch-ID"
+
+// batchIDKey - the context key of the batch id
+type batchIDKey struct{}
+
+// WithBatchID - returns a context identifying the batch sent with it (synchronous sends and the transfer functions)
+func WithBatchID(ctx context.Context, id string) context.Context {
+
+	return context.WithValue(ctx, batchIDKey{}, id)
+}
+
+// BatchIDFromContext - returns the batch id from the context (empty if not set)
+func BatchIDFromContext(ctx context.Context) string {
+
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(batchIDKey{}).(string)
+
+	return id
+}
+
+// newBatchID - generates a random (version 4) uuid
+func newBatchID() (string, error) {
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// batchID - returns the context with the batch id supplied by the caller or generated (if configured)
+func (s *httpSettings) batchID(ctx context.Context) (context.Context, string, error) {
+
+	id := BatchIDFromContext(ctx)
+	if len(id) > 0 || !s.configuration.GenerateBatchID {
+		return ctx, id, nil
+	}
+
+	id, err := newBatchID()
+	if err != nil {
+		return ctx, "", fmt.Errorf("error generating the batch id: %w", err)
+	}
+
+	return WithBatchID(ctx, id), id, nil
+}
+
+// batchIDHeader - returns the configured batch id header or the default one
+func (s *httpSettings) batchIDHeader() string {
+
+	if len(s.configuration.BatchIDHeader) > 0 {
+		return s.configuration.BatchIDHeader
+	}
+
+	return DefaultBatchIDHeader
+}
@@ -0,0 +1,163 @@
+package timeline
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/uol/gobol/logh"
+)
+
+/**
+* Limits the number of distinct values per tag key to protect the backend cardinality.
+**/
+
+// CardinalityMode - the action taken when a tag key exceeds the max number of distinct values
+type CardinalityMode uint8
+
+const (
+	// DropCardinalityTag - drops the tag with the new value, keeping the rest of the point
+	DropCardinalityTag CardinalityMode = 0
+
+	// DropCardinalityPoint - rejects the whole point containing the new value
+	DropCardinalityPoint CardinalityMode = 1
+)
+
+// CardinalityLimits - the cardinality guard configuration
+type CardinalityLimits struct {
+	MaxTagValues int
+	Mode         CardinalityMode
+}
+
+// cardinalityGuard - tracks the distinct values of each tag key
+type cardinalityGuard struct {
+	limits   CardinalityLimits
+	values   map[string]map[string]struct{}
+	warned   map[string]struct{}
+	exceeded uint64
+	mutex    sync.Mutex
+}
+
+// SetCardinalityLimits - enables the guard tracking the distinct values of each tag key, when a key reaches the
+// max number of values its new values are handled by the mode (zero max values disables the guard)
+func (m *Manager) SetCardinalityLimits(limits CardinalityLimits) error {
+
+	if limits.MaxTagValues < 0 {
+		return fmt.Errorf("invalid max tag values: %d", limits.MaxTagValues)
+	}
+
+	if limits.Mode != DropCardinalityTag && limits.Mode != DropCardinalityPoint {
+		return fmt.Errorf("invalid cardinality mode: %d", limits.Mode)
+	}
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	if limits.MaxTagValues == 0 {
+		m.tags.cardinality = nil
+		return nil
+	}
+
+	m.tags.cardinality = &cardinalityGuard{
+		limits: limits,
+		values: map[string]map[string]struct{}{},
+		warned: map[string]struct{}{},
+	}
+
+	return nil
+}
+
+// CardinalityExceeded - returns the number of tags or points dropped by the cardinality guard
+func (m *Manager) CardinalityExceeded() uint64 {
+
+	m.tags.mutex.RLock()
+	guard := m.tags.cardinality
+	m.tags.mutex.RUnlock()
+
+	if guard == nil {
+		return 0
+	}
+
+	return atomic.LoadUint64(&guard.exceeded)
+}
+
+// admit - checks all the point tags against the guard returning which tags must be dropped, the new values are tracked and
+// the dropped tags or point counted only if track is set (in the DropCardinalityPoint mode no value is tracked if any tag
+// exceeds the limit, since the point is rejected)
+func (g *cardinalityGuard) admit(keys, values []string, track bool) ([]bool, error) {
+
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	dropped := make([]bool, len(keys))
+	pending := map[string]map[string]struct{}{}
+	numDropped := 0
+	var exceededKey string
+
+	for i, key := range keys {
+
+		if _, ok := g.values[key][values[i]]; ok {
+			continue
+		}
+
+		if _, ok := pending[key][values[i]]; ok {
+			continue
+		}
+
+		if len(g.values[key])+len(pending[key]) >= g.limits.MaxTagValues {
+			dropped[i] = true
+			numDropped++
+			if track {
+				g.warn(key)
+			}
+			if len(exceededKey) == 0 {
+				exceededKey = key
+			}
+			continue
+		}
+
+		if _, ok := pending[key]; !ok {
+			pending[key] = map[string]struct{}{}
+		}
+
+		pending[key][values[i]] = struct{}{}
+	}
+
+	if numDropped > 0 && g.limits.Mode == DropCardinalityPoint {
+		if track {
+			atomic.AddUint64(&g.exceeded, 1)
+		}
+
+		return nil, fmt.Errorf("tag key \"%s\" exceeds the max number of distinct values: %d", exceededKey, g.limits.MaxTagValues)
+	}
+
+	if !track {
+		return dropped, nil
+	}
+
+	atomic.AddUint64(&g.exceeded, uint64(numDropped))
+
+	for key, newValues := range pending {
+
+		if _, ok := g.values[key]; !ok {
+			g.values[key] = map[string]struct{}{}
+		}
+
+		for value := range newValues {
+			g.values[key][value] = struct{}{}
+		}
+	}
+
+	return dropped, nil
+}
+
+// warn - warns once per key about the tag exceeding the limit
+func (g *cardinalityGuard) warn(key string) {
+
+	if _, ok := g.warned[key]; !ok {
+		g.warned[key] = struct{}{}
+		if logh.WarnEnabled {
+			logh.Warn().Msg(fmt.Sprintf("tag key \"%s\" reached the max number of distinct values: %d", key, g.limits.MaxTagValues))
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package timeline
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/uol/gobol/logh"
+)
+
+/**
+* Notifies the points dropped by the buffer overflow or the pending area limit.
+**/
+
+// dropWarnInterval - the min interval between the dropped points warnings
+const dropWarnInterval time.Duration = 10 * time.Second
+
+// OnDrop - sets a callback invoked synchronously with the number of dropped points every time points are dropped
+// by the buffer overflow or the pending area limit (call it before starting, the callback must not block)
+func (m *Manager) OnDrop(callback func(count int)) error {
+
+	ct, ok := m.transport.(coreTransport)
+	if !ok {
+		return fmt.Errorf("transport does not support the drop callback: %s", m.transport.Name())
+	}
+
+	ct.getCore().onDrop = callback
+
+	return nil
+}
+
+// drop - counts the dropped points, notifying the callback and logging a warning at most once per interval
+func (t *transportCore) drop(count int) {
+
+	atomic.AddUint64(&t.droppedPoints, uint64(count))
+
+	if t.onDrop != nil {
+		t.onDrop(count)
+	}
+
+	unwarned := atomic.AddUint64(&t.unwarnedDrops, uint64(count))
+
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&t.lastDropWarn)
+
+	if now-last < int64(dropWarnInterval) || !atomic.CompareAndSwapInt64(&t.lastDropWarn, last, now) {
+		return
+	}
+
+	atomic.AddUint64(&t.unwarnedDrops, -unwarned)
+
+	if logh.WarnEnabled {
+		t.loggers.Warn().Msg(fmt.Sprintf("%d points were dropped (%d in total)", unwarned, atomic.LoadUint64(&t.droppedPoints)))
+	}
+}
@@ -2,6 +2,7 @@ package timeline
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -33,8 +34,35 @@ const (
 
 	// Min - aggregation
 	Min FlatOperation = 4
+
+	// Last - aggregation (the last added value)
+	Last FlatOperation = 5
 )
 
+// PointKind - the semantics of the point values when flattened
+type PointKind uint8
+
+const (
+	// Gauge - a point measuring a current value (the last value wins)
+	Gauge PointKind = 0
+
+	// Counter - a point counting occurrences (the values are summed)
+	Counter PointKind = 1
+)
+
+// Operation - returns the flat operation matching the point kind
+func (k PointKind) Operation() (FlatOperation, error) {
+
+	switch k {
+	case Gauge:
+		return Last, nil
+	case Counter:
+		return Sum, nil
+	default:
+		return 0, fmt.Errorf("point kind id %d is not mapped", k)
+	}
+}
+
 // flattenerPointData - all common properties from a point
 type flattenerPointData struct {
 	operation       FlatOperation
@@ -60,14 +88,18 @@ type Flattener struct {
 	configuration *FlattenerConfig
 	pointMap      sync.Map
 	terminateChan chan struct{}
+	cycleDone     chan struct{}
 	transport     Transport
 	loggers       *logh.ContextualLogger
 }
 
 // mapEntry - a map entry containing all values from a point
+// (the flushed entries are removed from the map and no longer receive values)
 type mapEntry struct {
 	flattenerPointData
-	values []float64
+	values  []float64
+	flushed bool
+	mutex   sync.Mutex
 }
 
 // NewFlattener - creates a new flattener
@@ -91,52 +123,76 @@ func NewFlattener(transport Transport, configuration *FlattenerConfig) (*Flatten
 // Start - starts the flattenner and the transport
 func (f *Flattener) Start() error {
 
+	if err := f.transport.Start(); err != nil {
+		return err
+	}
+
+	f.cycleDone = make(chan struct{})
+
 	go f.beginCycle()
 
-	return f.transport.Start()
+	return nil
 }
 
 // beginCycle - begins the flattening loop cycle
 func (f *Flattener) beginCycle() {
 
+	defer close(f.cycleDone)
+
 	if logh.InfoEnabled {
 		f.loggers.Info().Msg("starting flattening cycle")
 	}
 
 	for {
-		<-time.After(f.configuration.CycleDuration)
-
 		select {
 		case <-f.terminateChan:
 			if logh.InfoEnabled {
 				f.loggers.Info().Msg("breaking flattening cycle")
 			}
 			return
-		default:
+		case <-time.After(f.configuration.CycleDuration):
 		}
 
-		count := 0
-
-		f.pointMap.Range(func(k, v interface{}) bool {
+		count, _ := f.flush()
 
-			entry := v.(*mapEntry)
+		if logh.InfoEnabled {
+			f.loggers.Info().Msg(fmt.Sprintf("%d points were flattened", count))
+		}
+	}
+}
 
-			f.processEntry(entry)
+// flush - processes all stored entries, returning the number of processed entries and the errors found
+func (f *Flattener) flush() (int, []error) {
 
-			f.pointMap.Delete(k)
+	count := 0
+	errs := []error{}
 
-			count++
+	f.pointMap.Range(func(k, _ interface{}) bool {
 
+		v, loaded := f.pointMap.LoadAndDelete(k)
+		if !loaded {
 			return true
-		})
+		}
 
-		if logh.InfoEnabled {
-			f.loggers.Info().Msg(fmt.Sprintf("%d points were flattened", count))
+		entry := v.(*mapEntry)
+		entry.mutex.Lock()
+		entry.flushed = true
+		entry.mutex.Unlock()
+
+		err := f.processEntry(entry)
+		if err != nil {
+			errs = append(errs, err)
 		}
-	}
+
+		count++
+
+		return true
+	})
+
+	return count, errs
 }
 
-// Add - adds a new entry to the flattening process
+// Add - adds a new entry to the flattening process (safe for concurrent use)
 func (f *Flattener) Add(point *FlattenerPoint) error {
 
 	hash, err := hashing.Generate(f.configuration.HashingAlgorithm, point.hashParameters...)
@@ -146,29 +202,46 @@ func (f *Flattener) Add(point *FlattenerPoint) error {
 
 	key := hex.EncodeToString(hash)
 
-	item, ok := f.pointMap.Load(key)
-	if ok {
-		entry := item.(*mapEntry)
-		entry.values = append(entry.values, point.value)
-		return nil
+	for {
+		item, ok := f.pointMap.Load(key)
+		if !ok {
+			entry := &mapEntry{
+				values: []float64{point.value},
+				flattenerPointData: flattenerPointData{
+					operation:       point.operation,
+					timestamp:       point.timestamp,
+					dataChannelItem: point.dataChannelItem,
+				},
+			}
+
+			if item, ok = f.pointMap.LoadOrStore(key, entry); !ok {
+				return nil
+			}
+		}
+
+		if f.addValue(item.(*mapEntry), point.value) {
+			return nil
+		}
 	}
+}
+
+// addValue - adds the value to the entry, returns false if the entry was already flushed
+func (f *Flattener) addValue(entry *mapEntry, value float64) bool {
 
-	entry := &mapEntry{
-		values: []float64{point.value},
-		flattenerPointData: flattenerPointData{
-			operation:       point.operation,
-			timestamp:       point.timestamp,
-			dataChannelItem: point.dataChannelItem,
-		},
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
+
+	if entry.flushed {
+		return false
 	}
 
-	f.pointMap.Store(key, entry)
+	entry.values = append(entry.values, value)
 
-	return nil
+	return true
 }
 
 // processEntry - process the values from an entry
-func (f *Flattener) processEntry(entry *mapEntry) {
+func (f *Flattener) processEntry(entry *mapEntry) error {
 
 	newValue, err := f.flatten(entry)
 	if err != nil {
@@ -177,7 +250,7 @@ func (f *Flattener) processEntry(entry *mapEntry) {
 			f.loggers.Error().Msg(err.Error())
 		}
 
-		return
+		return err
 	}
 
 	item, err := f.transport.FlattenedPointToDataChannelItem(newValue)
@@ -187,10 +260,10 @@ func (f *Flattener) processEntry(entry *mapEntry) {
 			f.loggers.Error().Msg(err.Error())
 		}
 
-		return
+		return err
 	}
 
-	f.transport.DataChannel() <- item
+	return enqueue(f.transport, item, nil)
 }
 
 // flatten - flats the values using the specified operation
@@ -240,6 +313,10 @@ func (f *Flattener) flatten(entry *mapEntry) (*FlattenerPoint, error) {
 			}
 		}
 
+	case Last:
+
+		flatValue = entry.values[len(entry.values)-1]
+
 	default:
 
 		return nil, fmt.Errorf("operation id %d is not mapped", entry.operation)
@@ -251,14 +328,21 @@ func (f *Flattener) flatten(entry *mapEntry) (*FlattenerPoint, error) {
 	}, nil
 }
 
-// Close - terminates the flattener and the transport
-func (f *Flattener) Close() {
+// Close - terminates the flattener and the transport, flushing the stored points (returns all errors found)
+// (waits the running flattening cycle to finish before closing the transport)
+func (f *Flattener) Close() error {
 
 	if logh.InfoEnabled {
 		f.loggers.Info().Msg("closing...")
 	}
 
-	f.transport.Close()
-
 	f.terminateChan <- struct{}{}
+
+	if f.cycleDone != nil {
+		<-f.cycleDone
+	}
+
+	_, errs := f.flush()
+
+	return errors.Join(append(errs, f.transport.Close())...)
 }
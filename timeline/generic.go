@@ -0,0 +1,144 @@
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/uol/gobol/structs"
+)
+
+/**
+* Sends the points having a value of any supported type.
+**/
+
+// GenericPointHTTPSchema - the json mapping name registered in the http transport to send the generic points
+const GenericPointHTTPSchema string = "timelineGenericPoint"
+
+// genericMapping - the lazy registration of the generic point json mapping
+type genericMapping struct {
+	once sync.Once
+	err  error
+}
+
+// genericValue - normalizes the value returning its type (an error is returned if the type is not supported)
+func genericValue(value interface{}) (interface{}, structs.ValueType, error) {
+
+	switch v := value.(type) {
+	case float64:
+		return v, structs.NumberValue, nil
+	case float32:
+		return float64(v), structs.NumberValue, nil
+	case int:
+		return int64(v), structs.IntegerValue, nil
+	case int8:
+		return int64(v), structs.IntegerValue, nil
+	case int16:
+		return int64(v), structs.IntegerValue, nil
+	case int32:
+		return int64(v), structs.IntegerValue, nil
+	case int64:
+		return v, structs.IntegerValue, nil
+	case uint:
+		return uint64(v), structs.IntegerValue, nil
+	case uint8:
+		return uint64(v), structs.IntegerValue, nil
+	case uint16:
+		return uint64(v), structs.IntegerValue, nil
+	case uint32:
+		return uint64(v), structs.IntegerValue, nil
+	case uint64:
+		return v, structs.IntegerValue, nil
+	case string:
+		return v, structs.TextValue, nil
+	case bool:
+		return v, structs.BoolValue, nil
+	default:
+		return nil, "", fmt.Errorf("unsupported generic point value type: %T", value)
+	}
+}
+
+// addGenericMapping - registers the generic point json mapping in the http transport (only once, the value and
+// timestamp properties cannot be reconfigured)
+func (m *Manager) addGenericMapping(t *HTTPTransport, configuration *HTTPTransportConfig) error {
+
+	m.generic.once.Do(func() {
+		m.generic.err = t.AddJSONMapping(
+			GenericPointHTTPSchema,
+			structs.GenericPoint{},
+			"metric",
+			configuration.ValueProperty,
+			configuration.TimestampProperty,
+			"tags",
+			"type",
+		)
+	})
+
+	return m.generic.err
+}
+
+// SendGenericPoint - sends a point with a value of any supported type (float, integer, string or bool), the point type
+// is set from the value, only the number and integer values are supported by the non http transports
+func (m *Manager) SendGenericPoint(point *structs.GenericPoint) error {
+
+	if point == nil {
+		return fmt.Errorf("null generic point")
+	}
+
+	value, valueType, err := genericValue(point.Value)
+	if err != nil {
+		return err
+	}
+
+	if len(point.Type) > 0 && point.Type != valueType {
+		return fmt.Errorf("generic point type \"%s\" does not match the value type: %s", point.Type, valueType)
+	}
+
+	timestamp := point.Timestamp
+	if timestamp == 0 {
+		timestamp = m.now()
+	}
+
+	if t, ok := m.transport.(*HTTPTransport); ok {
+		configuration := t.currentSettings().configuration
+
+		if err := m.addGenericMapping(t, configuration); err != nil {
+			return err
+		}
+
+		return m.SendHTTP(
+			GenericPointHTTPSchema,
+			"metric", point.Metric,
+			configuration.ValueProperty, value,
+			configuration.TimestampProperty, timestamp,
+			"tags", point.Tags,
+			"type", string(valueType),
+		)
+	}
+
+	var number float64
+	switch v := value.(type) {
+	case float64:
+		number = v
+	case int64:
+		number = float64(v)
+	case uint64:
+		number = float64(v)
+	default:
+		return fmt.Errorf("generic point value type is not supported by the transport %s: %s", m.transport.Name(), valueType)
+	}
+
+	keys := make([]string, 0, len(point.Tags))
+	for k := range point.Tags {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	tags := make([]interface{}, 0, 2*len(keys))
+	for _, k := range keys {
+		tags = append(tags, k, point.Tags[k])
+	}
+
+	return m.SendOpenTSDB(number, timestamp, point.Metric, tags...)
+}
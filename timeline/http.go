@@ -2,9 +2,13 @@ package timeline
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/uol/gobol/logh"
@@ -21,13 +25,51 @@ import (
 type HTTPTransport struct {
 	core                 transportCore
 	httpClient           *http.Client
-	serviceURL           string
+	backends             []*Backend
+	targets              []httpTarget
+	selector             *weightedSelector
 	configuration        *HTTPTransportConfig
 	serializer           *serializer.Serializer
 	useCustomJSONMapping bool
+	floatFactor          float64
+	configMutex          sync.RWMutex
 }
 
+// httpSettings - a snapshot of the configuration, client and backends used by a send
+// (taken under the configuration lock, which is not held while sending)
+type httpSettings struct {
+	configuration *HTTPTransportConfig
+	httpClient    *http.Client
+	floatFactor   float64
+	targets       []httpTarget
+	selector      *weightedSelector
+}
+
+// httpTarget - the service urls of a backend
+type httpTarget struct {
+	backendAddress string
+	rollupURL      string
+	serviceURL     string
+	numberURL      string
+	textURL        string
+}
+
+// BodyFormat - the framing of the points in the request body
+type BodyFormat uint8
+
+const (
+	// JSONArray - all points in a json array (default)
+	JSONArray BodyFormat = 0
+
+	// NDJSON - one json object per line (newline delimited json)
+	NDJSON BodyFormat = 1
+)
+
 // HTTPTransportConfig - has all HTTP event manager configurations
+// (FloatPrecision limits the decimal places of the float values, zero keeps the full precision)
+// (NumberServiceEndpoint and TextServiceEndpoint override the ServiceEndpoint for each point type)
+// (MaxRequestBytes splits the batch in multiple requests with bodies up to the size, zero means no limit)
+// (the batch id from the context or generated by GenerateBatchID is sent in the BatchIDHeader, X-Batch-ID by default)
 type HTTPTransportConfig struct {
 	DefaultTransportConfiguration
 	ServiceEndpoint        string
@@ -35,43 +77,100 @@ type HTTPTransportConfig struct {
 	ExpectedResponseStatus int
 	TimestampProperty      string
 	ValueProperty          string
+	BodyFormat             BodyFormat
+	RollupServiceEndpoint  string
+	ContentType            string
+	FloatPrecision         int
+	NumberServiceEndpoint  string
+	TextServiceEndpoint    string
+	MaxRequestBytes        int
+	BatchIDHeader          string
+	GenerateBatchID        bool
 }
 
+// allowedHTTPMethods - the http methods allowed to send the points
+var allowedHTTPMethods = map[string]struct{}{
+	http.MethodPost:  {},
+	http.MethodPut:   {},
+	http.MethodPatch: {},
+}
+
+const (
+	// rollupAggregatorParameter - the rollup aggregator parameter name
+	rollupAggregatorParameter string = "aggregator"
+
+	// rollupIntervalParameter - the rollup interval parameter name
+	rollupIntervalParameter string = "interval"
+)
+
 // NewHTTPTransport - creates a new HTTP event manager
 func NewHTTPTransport(configuration *HTTPTransportConfig) (*HTTPTransport, error) {
 
+	if err := validateHTTPConfiguration(configuration); err != nil {
+		return nil, err
+	}
+
+	s := serializer.New(configuration.SerializerBufferSize)
+
+	t := &HTTPTransport{
+		core:          newTransportCore(&configuration.DefaultTransportConfiguration, "timeline/http"),
+		configuration: configuration,
+		httpClient:    util.CreateHTTPClient(configuration.RequestTimeout, true),
+		serializer:    s,
+		floatFactor:   floatFactor(configuration.FloatPrecision),
+	}
+
+	t.core.transport = t
+
+	return t, nil
+}
+
+// validateHTTPConfiguration - validates the http transport configuration
+func validateHTTPConfiguration(configuration *HTTPTransportConfig) error {
+
 	if configuration == nil {
-		return nil, fmt.Errorf("null configuration found")
+		return fmt.Errorf("null configuration found")
 	}
 
 	if err := configuration.Validate(); err != nil {
-		return nil, err
+		return err
 	}
 
 	if len(configuration.TimestampProperty) == 0 {
-		return nil, fmt.Errorf("timestamp property is not configured")
+		return fmt.Errorf("timestamp property is not configured")
 	}
 
 	if len(configuration.ValueProperty) == 0 {
-		return nil, fmt.Errorf("value property is not configured")
+		return fmt.Errorf("value property is not configured")
 	}
 
-	s := serializer.New(configuration.SerializerBufferSize)
+	if _, ok := allowedHTTPMethods[configuration.Method]; !ok {
+		return fmt.Errorf("unsupported http method: \"%s\"", configuration.Method)
+	}
 
-	t := &HTTPTransport{
-		core: transportCore{
-			batchSendInterval: configuration.BatchSendInterval,
-			pointChannel:      make(chan interface{}, configuration.TransportBufferSize),
-			loggers:           logh.CreateContextualLogger("pkg", "timeline/http"),
-		},
-		configuration: configuration,
-		httpClient:    util.CreateHTTPClient(configuration.RequestTimeout, true),
-		serializer:    s,
+	if configuration.FloatPrecision < 0 {
+		return fmt.Errorf("invalid float precision: %d", configuration.FloatPrecision)
 	}
 
-	t.core.transport = t
+	if configuration.MaxRequestBytes < 0 {
+		return fmt.Errorf("invalid max request bytes: %d", configuration.MaxRequestBytes)
+	}
 
-	return t, nil
+	if configuration.BodyFormat != JSONArray && configuration.BodyFormat != NDJSON {
+		return fmt.Errorf("invalid body format: %d", configuration.BodyFormat)
+	}
+
+	return nil
+}
+
+// floatFactor - returns the factor rounding the float values to the precision (zero keeps the full precision)
+func floatFactor(precision int) float64 {
+
+	if precision > 0 {
+		return math.Pow10(precision)
+	}
+
+	return 0
 }
 
 // AddJSONMapping - overrides the default generic property mappings
@@ -80,6 +179,25 @@ func (t *HTTPTransport) AddJSONMapping(name string, p interface{}, variables ...
 	return t.serializer.Add(name, p, variables...)
 }
 
+// BufferLen - returns the number of buffered points
+func (t *HTTPTransport) BufferLen() int {
+
+	return len(t.core.pointChannel)
+}
+
+// BufferCap - returns the max number of buffered points
+func (t *HTTPTransport) BufferCap() int {
+
+	return cap(t.core.pointChannel)
+}
+
+// OnBatchSent - sets a callback invoked (asynchronously) after each batch is sent with the number of points,
+// the send duration and the error found (call it before starting the transport)
+func (t *HTTPTransport) OnBatchSent(callback func(count int, duration time.Duration, err error)) {
+
+	t.core.onBatchSent = callback
+}
+
 // ConfigureBackend - configures the backend
 func (t *HTTPTransport) ConfigureBackend(backend *Backend) error {
 
@@ -87,15 +205,93 @@ func (t *HTTPTransport) ConfigureBackend(backend *Backend) error {
 		return fmt.Errorf("no backend was configured")
 	}
 
-	t.serviceURL = fmt.Sprintf("http://%s:%d/%s", backend.Host, backend.Port, t.configuration.ServiceEndpoint)
+	return t.ConfigureBackends([]*Backend{backend})
+}
+
+// ConfigureBackends - configures the backends receiving the batches proportionally to their weights
+func (t *HTTPTransport) ConfigureBackends(backends []*Backend) error {
+
+	weights, err := backendWeights(backends)
+	if err != nil {
+		return err
+	}
+
+	t.configMutex.RLock()
+	targets := t.newTargets(backends)
+	t.configMutex.RUnlock()
 
-	if logh.InfoEnabled {
-		t.core.loggers.Info().Msg(fmt.Sprintf("backend was configured to use service: %s", t.serviceURL))
+	for i := range targets {
+
+		if logh.InfoEnabled {
+			t.core.loggers.Info().Msg(fmt.Sprintf("backend was configured to use services: %s (number) and %s (text) with weight %d", targets[i].numberURL, targets[i].textURL, weights[i]))
+		}
 	}
 
+	t.configMutex.Lock()
+	defer t.configMutex.Unlock()
+
+	t.backends = backends
+	t.targets = targets
+	t.selector = newWeightedSelector(weights)
+
 	return nil
 }
 
+// newTargets - creates the targets of the backends
+func (t *HTTPTransport) newTargets(backends []*Backend) []httpTarget {
+
+	targets := make([]httpTarget, len(backends))
+
+	for i, backend := range backends {
+		targets[i] = t.newTarget(backend)
+	}
+
+	return targets
+}
+
+// newTarget - creates the service urls of the backend
+func (t *HTTPTransport) newTarget(backend *Backend) httpTarget {
+
+	target := httpTarget{
+		backendAddress: fmt.Sprintf("%s:%d", backend.Host, backend.Port),
+	}
+
+	target.serviceURL = fmt.Sprintf("http://%s/%s", target.backendAddress, t.configuration.ServiceEndpoint)
+	target.numberURL = target.endpointURL(t.configuration.NumberServiceEndpoint)
+	target.textURL = target.endpointURL(t.configuration.TextServiceEndpoint)
+
+	if len(t.configuration.RollupServiceEndpoint) > 0 {
+		target.rollupURL = fmt.Sprintf("http://%s/%s", target.backendAddress, t.configuration.RollupServiceEndpoint)
+	}
+
+	return target
+}
+
+// endpointURL - returns the endpoint url or the service url if the endpoint is not configured
+func (target *httpTarget) endpointURL(endpoint string) string {
+
+	if len(endpoint) == 0 {
+		return target.serviceURL
+	}
+
+	return fmt.Sprintf("http://%s/%s", target.backendAddress, endpoint)
+}
+
+// currentSettings - returns a snapshot of the current settings
+func (t *HTTPTransport) currentSettings() httpSettings {
+
+	t.configMutex.RLock()
+	defer t.configMutex.RUnlock()
+
+	return httpSettings{
+		configuration: t.configuration,
+		httpClient:    t.httpClient,
+		floatFactor:   t.floatFactor,
+		targets:       t.targets,
+		selector:      t.selector,
+	}
+}
+
 // DataChannel - send a new point
 func (t *HTTPTransport) DataChannel() chan<- interface{} {
 
@@ -103,50 +299,266 @@ func (t *HTTPTransport) DataChannel() chan<- interface{} {
 }
 
 // TransferData - transfers the data to the backend throught this transport
-func (t *HTTPTransport) TransferData(dataList []interface{}) error {
+func (t *HTTPTransport) TransferData(ctx context.Context, dataList []interface{}) error {
+
+	settings := t.currentSettings()
+
+	if len(settings.targets) == 0 {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	target := &settings.targets[settings.selector.next()]
+
+	ctx, batchID, err := settings.batchID(ctx)
+	if err != nil {
+		return err
+	}
 
 	numPoints := len(dataList)
-	points := make([]serializer.ArrayItem, numPoints)
-	var ok bool
+
+	if len(batchID) > 0 && logh.InfoEnabled {
+		t.core.loggers.Info().Str("batchID", batchID).Msg(fmt.Sprintf("sending a batch of %d points", numPoints))
+	}
+
+	points := newHTTPGroup(target.numberURL, numPoints)
+	texts := newHTTPGroup(target.textURL, 0)
+	rollups := newHTTPGroup(target.rollupURL, 0)
 	for i := 0; i < numPoints; i++ {
-		points[i], ok = dataList[i].(serializer.ArrayItem)
+		point, ok := dataList[i].(serializer.ArrayItem)
 		if !ok {
 			return fmt.Errorf("error casting data to serializer.ArrayItem")
 		}
+
+		rounded := settings.roundValue(point)
+
+		if len(target.rollupURL) > 0 && isRollupPoint(&rounded) {
+			rollups.add(rounded, point)
+		} else if target.textURL != target.numberURL && !settings.hasValue(&rounded) {
+			texts.add(rounded, point)
+		} else {
+			points.add(rounded, point)
+		}
+	}
+
+	payloads := []httpPayload{}
+	for _, group := range []*httpGroup{points, texts, rollups} {
+
+		if len(group.points) == 0 {
+			continue
+		}
+
+		groupPayloads, err := t.serializePayloads(&settings, group.url, group.points, group.items)
+		if err != nil {
+			return err
+		}
+
+		payloads = append(payloads, groupPayloads...)
+	}
+
+	return t.sendPayloads(ctx, &settings, payloads)
+}
+
+// httpGroup - the points sent to the same url
+type httpGroup struct {
+	url    string
+	points []serializer.ArrayItem
+	items  []interface{}
+}
+
+// newHTTPGroup - creates a new group of points sent to the url
+func newHTTPGroup(url string, capacity int) *httpGroup {
+
+	return &httpGroup{
+		url:    url,
+		points: make([]serializer.ArrayItem, 0, capacity),
+		items:  make([]interface{}, 0, capacity),
+	}
+}
+
+// add - adds the point to be serialized and its original data channel item
+func (g *httpGroup) add(point serializer.ArrayItem, item interface{}) {
+
+	g.points = append(g.points, point)
+	g.items = append(g.items, item)
+}
+
+// httpPayload - a request body and the data channel items serialized in it
+type httpPayload struct {
+	url   string
+	body  string
+	items []interface{}
+}
+
+// hasValue - checks if the point has the value parameter (the points without it are text points)
+func (s *httpSettings) hasValue(point *serializer.ArrayItem) bool {
+
+	for i := 0; i+1 < len(point.Parameters); i += 2 {
+
+		if key, ok := point.Parameters[i].(string); ok && key == s.configuration.ValueProperty {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isRollupPoint - checks if the point has the rollup aggregator and interval parameters
+func isRollupPoint(point *serializer.ArrayItem) bool {
+
+	hasAggregator := false
+	hasInterval := false
+
+	for i := 0; i+1 < len(point.Parameters); i += 2 {
+
+		key, ok := point.Parameters[i].(string)
+		if !ok {
+			continue
+		}
+
+		value, ok := point.Parameters[i+1].(string)
+		if !ok || len(value) == 0 {
+			continue
+		}
+
+		switch key {
+		case rollupAggregatorParameter:
+			hasAggregator = true
+		case rollupIntervalParameter:
+			hasInterval = true
+		}
+	}
+
+	return hasAggregator && hasInterval
+}
+
+// sendPayloads - sends the payloads in order, the items of the payloads not sent are returned
+// in a PartialTransferError if any payload was already accepted
+func (t *HTTPTransport) sendPayloads(ctx context.Context, settings *httpSettings, payloads []httpPayload) error {
+
+	for i, payload := range payloads {
+
+		err := t.sendPayload(ctx, settings, payload.url, payload.body)
+		if err == nil {
+			continue
+		}
+
+		if i == 0 {
+			return err
+		}
+
+		failed := []interface{}{}
+		for _, unsent := range payloads[i:] {
+			failed = append(failed, unsent.items...)
+		}
+
+		return &PartialTransferError{Failed: failed, Err: err}
 	}
 
-	payload, err := t.serializer.SerializeArray(points...)
+	return nil
+}
+
+// serializePayloads - serializes the points splitting them in payloads up to the max request bytes (if configured)
+func (t *HTTPTransport) serializePayloads(settings *httpSettings, url string, points []serializer.ArrayItem, items []interface{}) ([]httpPayload, error) {
+
+	body, err := t.serializePayload(settings, points)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if settings.configuration.MaxRequestBytes == 0 || len(body) <= settings.configuration.MaxRequestBytes {
+		return []httpPayload{{url: url, body: body, items: items}}, nil
+	}
+
+	if len(points) == 1 {
+		return nil, fmt.Errorf("point exceeds the max request bytes (%d): %d bytes", settings.configuration.MaxRequestBytes, len(body))
 	}
 
-	req, err := http.NewRequest(t.configuration.Method, t.serviceURL, bytes.NewBuffer([]byte(payload)))
+	half := len(points) / 2
+
+	first, err := t.serializePayloads(settings, url, points[:half], items[:half])
+	if err != nil {
+		return nil, err
+	}
+
+	second, err := t.serializePayloads(settings, url, points[half:], items[half:])
+	if err != nil {
+		return nil, err
+	}
+
+	return append(first, second...), nil
+}
+
+// sendPayload - sends the serialized payload to the url
+func (t *HTTPTransport) sendPayload(ctx context.Context, settings *httpSettings, url, payload string) error {
+
+	req, err := http.NewRequestWithContext(ctx, settings.configuration.Method, url, bytes.NewBuffer([]byte(payload)))
 	if err != nil {
 		return err
 	}
 
-	req.Header.Set("Content-type", "application/json")
+	req.Header.Set("Content-type", settings.contentType())
+
+	if id := BatchIDFromContext(ctx); len(id) > 0 {
+		req.Header.Set(settings.batchIDHeader(), id)
+	}
 
-	res, err := t.httpClient.Do(req)
+	res, err := settings.httpClient.Do(req)
 	if err != nil {
 		return err
 	}
 
-	if res.StatusCode != t.configuration.ExpectedResponseStatus {
+	defer res.Body.Close()
+
+	if res.StatusCode != settings.configuration.ExpectedResponseStatus {
 
 		reqResponse, err := ioutil.ReadAll(res.Body)
 		if err != nil {
 			return fmt.Errorf("error reading body: %s", err.Error())
 		}
 
-		return fmt.Errorf("error body: %s", string(reqResponse))
+		return &StatusError{Status: res.StatusCode, Body: string(reqResponse)}
 	}
 
-	res.Body.Close()
-
 	return nil
 }
 
+// contentType - returns the configured content type or the one matching the body format
+func (s *httpSettings) contentType() string {
+
+	if len(s.configuration.ContentType) > 0 {
+		return s.configuration.ContentType
+	}
+
+	if s.configuration.BodyFormat == NDJSON {
+		return "application/x-ndjson"
+	}
+
+	return "application/json"
+}
+
+// serializePayload - serializes the points using the configured body format
+func (t *HTTPTransport) serializePayload(settings *httpSettings, points []serializer.ArrayItem) (string, error) {
+
+	if settings.configuration.BodyFormat == JSONArray {
+		return t.serializer.SerializeArray(points...)
+	}
+
+	var b strings.Builder
+
+	for _, point := range points {
+
+		line, err := t.serializer.SerializeGeneric(point)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(line)
+		b.WriteByte('\n')
+	}
+
+	return b.String(), nil
+}
+
 // MatchType - checks if this transport implementation matches the given type
 func (t *HTTPTransport) MatchType(tt transportType) bool {
 
@@ -161,6 +573,8 @@ func (t *HTTPTransport) DataChannelItemToFlattenedPoint(operation FlatOperation,
 		return nil, fmt.Errorf("error casting instance to data channel item")
 	}
 
+	configuration := t.currentSettings().configuration
+
 	hashParameters := []interface{}{}
 	hashParameters = append(hashParameters, item.Name, operation)
 
@@ -178,7 +592,7 @@ func (t *HTTPTransport) DataChannelItemToFlattenedPoint(operation FlatOperation,
 				return nil, fmt.Errorf("expecting a property name in parameter item: %s", item.Parameters[i])
 			}
 
-			if !valueFound && key == t.configuration.ValueProperty {
+			if !valueFound && key == configuration.ValueProperty {
 				valueFound = true
 				value, ok = item.Parameters[i+1].(float64)
 				if !ok {
@@ -189,7 +603,7 @@ func (t *HTTPTransport) DataChannelItemToFlattenedPoint(operation FlatOperation,
 				continue
 			}
 
-			if !timestampFound && key == t.configuration.TimestampProperty {
+			if !timestampFound && key == configuration.TimestampProperty {
 				timestampFound = true
 				timestamp, ok = item.Parameters[i+1].(int64)
 				if !ok {
@@ -205,7 +619,7 @@ func (t *HTTPTransport) DataChannelItemToFlattenedPoint(operation FlatOperation,
 	}
 
 	if !timestampFound {
-		timestamp = time.Now().Unix()
+		timestamp = t.core.now()
 	}
 
 	return &FlattenerPoint{
@@ -230,7 +644,9 @@ func (t *HTTPTransport) FlattenedPointToDataChannelItem(point *FlattenerPoint) (
 		return nil, fmt.Errorf("error casting point's data channel item")
 	}
 
-	item.Parameters = append(item.Parameters, t.configuration.TimestampProperty, point.timestamp, t.configuration.ValueProperty, point.value)
+	configuration := t.currentSettings().configuration
+
+	item.Parameters = append(item.Parameters, configuration.TimestampProperty, point.timestamp, configuration.ValueProperty, point.value)
 
 	return item, nil
 }
@@ -242,13 +658,77 @@ func (t *HTTPTransport) Start() error {
 }
 
 // Close - closes this transport
-func (t *HTTPTransport) Close() {
+func (t *HTTPTransport) Close() error {
 
-	t.core.Close()
+	return t.core.Close()
 }
 
 // Serialize - renders the text using the configured serializer
 func (t *HTTPTransport) Serialize(item interface{}) (string, error) {
 
+	if point, ok := item.(serializer.ArrayItem); ok {
+		settings := t.currentSettings()
+		item = settings.roundValue(point)
+	}
+
 	return t.serializer.SerializeGeneric(item)
 }
+
+// roundValue - returns the point with the float value rounded to the configured precision
+// (the parameters are copied, the point is returned unchanged if no precision is configured)
+func (s *httpSettings) roundValue(point serializer.ArrayItem) serializer.ArrayItem {
+
+	if s.floatFactor == 0 {
+		return point
+	}
+
+	for i := 0; i+1 < len(point.Parameters); i += 2 {
+
+		if key, ok := point.Parameters[i].(string); !ok || key != s.configuration.ValueProperty {
+			continue
+		}
+
+		value, ok := point.Parameters[i+1].(float64)
+		if !ok {
+			return point
+		}
+
+		parameters := make([]interface{}, len(point.Parameters))
+		copy(parameters, point.Parameters)
+		parameters[i+1] = math.Round(value*s.floatFactor) / s.floatFactor
+
+		return serializer.ArrayItem{Name: point.Name, Parameters: parameters}
+	}
+
+	return point
+}
+
+// HealthCheck - checks if all backends accept connections
+func (t *HTTPTransport) HealthCheck(ctx context.Context) error {
+
+	targets := t.currentSettings().targets
+
+	if len(targets) == 0 {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	for _, target := range targets {
+		if err := dialHealthCheck(ctx, target.backendAddress); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Name - returns the transport name
+func (t *HTTPTransport) Name() string {
+
+	return "http"
+}
+
+// getCore - returns the transport core
+func (t *HTTPTransport) getCore() *transportCore {
+
+	return &t.core
+}
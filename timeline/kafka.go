@@ -0,0 +1,229 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/uol/gobol/logh"
+	serializer "github.com/uol/serializer/opentsdb"
+)
+
+/**
+* The Kafka transport implementation.
+**/
+
+// KafkaProducer - the kafka client used by the transport (any kafka library can be adapted to it)
+type KafkaProducer interface {
+
+	// Produce - produces a message to the specified topic
+	Produce(topic string, key, value []byte) error
+
+	// Close - closes the producer
+	Close() error
+}
+
+// ProducerError - the error returned by the kafka producer (retried by the default retry classifier)
+type ProducerError struct {
+	Err error
+}
+
+// Error - returns the error message
+func (e *ProducerError) Error() string {
+
+	return e.Err.Error()
+}
+
+// Unwrap - returns the producer error
+func (e *ProducerError) Unwrap() error {
+
+	return e.Err
+}
+
+// KafkaTransport - implements the kafka transport (uses the same points as the openTSDB transport)
+type KafkaTransport struct {
+	core          transportCore
+	configuration *KafkaTransportConfig
+	serializer    *serializer.Serializer
+	producer      KafkaProducer
+}
+
+// KafkaTransportConfig - has all kafka transport configurations
+type KafkaTransportConfig struct {
+	DefaultTransportConfiguration
+	Topic string
+}
+
+// NewKafkaTransport - creates a new kafka transport
+func NewKafkaTransport(configuration *KafkaTransportConfig, producer KafkaProducer) (*KafkaTransport, error) {
+
+	if configuration == nil {
+		return nil, fmt.Errorf("null configuration found")
+	}
+
+	if producer == nil {
+		return nil, fmt.Errorf("kafka producer is required")
+	}
+
+	if err := configuration.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(configuration.Topic) == 0 {
+		return nil, fmt.Errorf("kafka topic is not configured")
+	}
+
+	t := &KafkaTransport{
+		core:          newTransportCore(&configuration.DefaultTransportConfiguration, "timeline/kafka"),
+		configuration: configuration,
+		serializer:    serializer.New(configuration.SerializerBufferSize),
+		producer:      producer,
+	}
+
+	t.core.transport = t
+
+	return t, nil
+}
+
+// ConfigureBackend - configures the backend (the brokers are configured in the producer)
+func (t *KafkaTransport) ConfigureBackend(backend *Backend) error {
+
+	if backend == nil {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	if logh.InfoEnabled {
+		t.core.loggers.Info().Msg(fmt.Sprintf("backend was configured to use topic: %s", t.configuration.Topic))
+	}
+
+	return nil
+}
+
+// DataChannel - send a new point
+func (t *KafkaTransport) DataChannel() chan<- interface{} {
+
+	return t.core.pointChannel
+}
+
+// TransferData - transfers the data to the backend throught this transport (one message per metric),
+// the points of the metrics not produced are returned in a PartialTransferError
+func (t *KafkaTransport) TransferData(ctx context.Context, dataList []interface{}) error {
+
+	metrics := []string{}
+	pointsByMetric := map[string][]serializer.ArrayItem{}
+
+	for i := 0; i < len(dataList); i++ {
+
+		item, ok := dataList[i].(serializer.ArrayItem)
+		if !ok {
+			return fmt.Errorf("error casting data to serializer.ArrayItem")
+		}
+
+		if _, exists := pointsByMetric[item.Metric]; !exists {
+			metrics = append(metrics, item.Metric)
+		}
+
+		pointsByMetric[item.Metric] = append(pointsByMetric[item.Metric], item)
+	}
+
+	failed := []interface{}{}
+	errs := []error{}
+
+	for i, metric := range metrics {
+
+		if ctx.Err() != nil {
+			for _, remaining := range metrics[i:] {
+				failed = append(failed, toInterfaces(pointsByMetric[remaining])...)
+			}
+			errs = append(errs, fmt.Errorf("error producing messages: %w", ctx.Err()))
+			break
+		}
+
+		payload, err := t.serializer.SerializeArray(pointsByMetric[metric]...)
+		if err != nil {
+			failed = append(failed, toInterfaces(pointsByMetric[metric])...)
+			errs = append(errs, fmt.Errorf("error serializing messages for metric %s: %w", metric, err))
+			continue
+		}
+
+		err = t.producer.Produce(t.configuration.Topic, []byte(metric), []byte(payload))
+		if err != nil {
+			failed = append(failed, toInterfaces(pointsByMetric[metric])...)
+			errs = append(errs, fmt.Errorf("error producing messages for metric %s: %w", metric, &ProducerError{Err: err}))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &PartialTransferError{Failed: failed, Err: errors.Join(errs...)}
+	}
+
+	return nil
+}
+
+// toInterfaces - converts the serializer items to data channel items
+func toInterfaces(items []serializer.ArrayItem) []interface{} {
+
+	result := make([]interface{}, len(items))
+	for i, item := range items {
+		result[i] = item
+	}
+
+	return result
+}
+
+// MatchType - checks if this transport implementation matches the given type
+func (t *KafkaTransport) MatchType(tt transportType) bool {
+
+	return tt == typeOpenTSDB
+}
+
+// DataChannelItemToFlattenedPoint - converts the data channel item to the flattened point one
+func (t *KafkaTransport) DataChannelItemToFlattenedPoint(operation FlatOperation, instance interface{}) (*FlattenerPoint, error) {
+
+	return openTSDBItemToFlattenedPoint(operation, instance, &t.core)
+}
+
+// FlattenedPointToDataChannelItem - converts the flattened point to the data channel one
+func (t *KafkaTransport) FlattenedPointToDataChannelItem(point *FlattenerPoint) (interface{}, error) {
+
+	return flattenedPointToOpenTSDBItem(point)
+}
+
+// Start - starts this transport
+func (t *KafkaTransport) Start() error {
+
+	return t.core.Start()
+}
+
+// Close - closes this transport and the producer
+func (t *KafkaTransport) Close() error {
+
+	coreErr := t.core.Close()
+
+	var producerErr error
+
+	err := t.producer.Close()
+	if err != nil {
+		producerErr = fmt.Errorf("error closing the producer: %w", err)
+	}
+
+	return errors.Join(coreErr, producerErr)
+}
+
+// Serialize - renders the text using the configured serializer
+func (t *KafkaTransport) Serialize(item interface{}) (string, error) {
+
+	return t.serializer.SerializeGeneric(item)
+}
+
+// Name - returns the transport name
+func (t *KafkaTransport) Name() string {
+
+	return "kafka"
+}
+
+// getCore - returns the transport core
+func (t *KafkaTransport) getCore() *transportCore {
+
+	return &t.core
+}
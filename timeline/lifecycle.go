@@ -0,0 +1,161 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"github.com/uol/gobol/logh"
+)
+
+/**
+* Manages the manager start and shutdown states.
+**/
+
+const (
+	stateCreated int32 = 0
+	stateRunning int32 = 1
+	stateClosed  int32 = 2
+)
+
+// ErrTransportClosed - returned when sending or shutting down after the manager shutdown
+var ErrTransportClosed = errors.New("transport is closed")
+
+// SetLazyStart - enables the manager start on the first sent point if it was not started yet
+// (the explicit start keeps working)
+func (m *Manager) SetLazyStart(enabled bool) {
+
+	m.lifecycleMutex.Lock()
+	defer m.lifecycleMutex.Unlock()
+
+	m.lazyStart = enabled
+}
+
+// StartContext - starts the manager, shutting it down (flushing the buffered points) when the context is done
+func (m *Manager) StartContext(ctx context.Context) error {
+
+	if err := m.Start(); err != nil {
+		return err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-m.shutdownChan:
+			return
+		}
+
+		if logh.InfoEnabled {
+			m.loggers.Info().Msg("context is done, shutting down manager...")
+		}
+
+		if err := m.Shutdown(); err != nil && err != ErrTransportClosed {
+			if logh.ErrorEnabled {
+				m.loggers.Error().Err(err).Msg("error shutting down the manager on context done")
+			}
+		}
+	}()
+
+	return nil
+}
+
+// start - starts the flattener or the transport (must be called locked)
+func (m *Manager) start() error {
+
+	switch atomic.LoadInt32(&m.state) {
+	case stateRunning:
+		return nil
+	case stateClosed:
+		return ErrTransportClosed
+	}
+
+	var err error
+
+	if m.flattener != nil {
+		err = m.flattener.Start()
+	} else {
+		err = m.transport.Start()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	atomic.StoreInt32(&m.state, stateRunning)
+
+	return nil
+}
+
+// prepareSend - checks if the manager was not shut down, starting it if the lazy start is enabled
+func (m *Manager) prepareSend() error {
+
+	switch atomic.LoadInt32(&m.state) {
+	case stateRunning:
+		return nil
+	case stateClosed:
+		return ErrTransportClosed
+	}
+
+	m.lifecycleMutex.Lock()
+	defer m.lifecycleMutex.Unlock()
+
+	if atomic.LoadInt32(&m.state) == stateClosed {
+		return ErrTransportClosed
+	}
+
+	if !m.lazyStart || atomic.LoadInt32(&m.state) == stateRunning {
+		return nil
+	}
+
+	if logh.InfoEnabled {
+		m.loggers.Info().Msg("starting manager on the first sent point...")
+	}
+
+	return m.start()
+}
+
+// beginSend - checks the manager was not shut down and holds the shutdown until endSend is called,
+// so the buffer is never closed while a point is being sent (starts the manager if the lazy start is enabled)
+func (m *Manager) beginSend() error {
+
+	m.sendMutex.RLock()
+
+	if err := m.prepareSend(); err != nil {
+		m.sendMutex.RUnlock()
+		return err
+	}
+
+	return nil
+}
+
+// endSend - releases the shutdown held by beginSend
+func (m *Manager) endSend() {
+
+	m.sendMutex.RUnlock()
+}
+
+// Running - checks if the manager was started and not shut down yet (safe for concurrent use)
+func (m *Manager) Running() bool {
+
+	return atomic.LoadInt32(&m.state) == stateRunning
+}
+
+// markClosed - marks the manager as closed and waits for the sends in progress (returns false if it was already closed)
+// (the sends blocked by a full buffer give up returning ErrTransportClosed)
+func (m *Manager) markClosed() bool {
+
+	m.lifecycleMutex.Lock()
+
+	if atomic.SwapInt32(&m.state, stateClosed) == stateClosed {
+		m.lifecycleMutex.Unlock()
+		return false
+	}
+
+	close(m.shutdownChan)
+	m.lifecycleMutex.Unlock()
+
+	m.sendMutex.Lock()
+	m.sendMutex.Unlock()
+
+	return true
+}
@@ -1,9 +1,13 @@
 package timeline
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/uol/gobol/logh"
 	jsonSerializer "github.com/uol/serializer/json"
 	openTSDBSerializer "github.com/uol/serializer/opentsdb"
 )
@@ -14,15 +18,32 @@ import (
 **/
 
 // Manager - the parent of all event managers
+// (a single manager can be shared by many goroutines: the send, flatten, flush and configuration methods
+// are safe for concurrent use, the parameters and tags passed are not modified and may be reused after the call;
+// Shutdown may run while other goroutines are sending, every accepted point is sent and the later sends return ErrTransportClosed)
 type Manager struct {
-	transport Transport
-	flattener *Flattener
+	transport      Transport
+	flattener      *Flattener
+	loggers        *logh.ContextualLogger
+	tags           tagProcessor
+	selfMetrics    *selfMetrics
+	generic        genericMapping
+	lazyStart      bool
+	state          int32
+	shutdownChan   chan struct{}
+	lifecycleMutex sync.Mutex
+	sendMutex      sync.RWMutex
 }
 
+// drainCheckInterval - the interval checking if the transport buffer was drained
+const drainCheckInterval time.Duration = 10 * time.Millisecond
+
 // Backend - the destiny opentsdb backend
+// (the weight is the relative share of batches when multiple backends are configured, zero means 1)
 type Backend struct {
-	Host string
-	Port int
+	Host   string
+	Port   int
+	Weight int
 }
 
 // NewManager - creates a timeline manager
@@ -42,7 +63,9 @@ func NewManager(transport Transport, backend *Backend) (*Manager, error) {
 	}
 
 	return &Manager{
-		transport: transport,
+		transport:    transport,
+		loggers:      newManagerLoggers(transport),
+		shutdownChan: make(chan struct{}),
 	}, nil
 }
 
@@ -63,38 +86,119 @@ func NewManagerF(flattener *Flattener, backend *Backend) (*Manager, error) {
 	}
 
 	return &Manager{
-		flattener: flattener,
-		transport: flattener.transport,
+		flattener:    flattener,
+		transport:    flattener.transport,
+		loggers:      newManagerLoggers(flattener.transport),
+		shutdownChan: make(chan struct{}),
 	}, nil
 }
 
-// SendHTTP - sends a new data using the http transport
-func (m *Manager) SendHTTP(schemaName string, parameters ...interface{}) error {
+// newManagerLoggers - creates the manager loggers identifying the transport
+func newManagerLoggers(transport Transport) *logh.ContextualLogger {
+
+	return logh.CreateContextualLogger("pkg", "timeline/manager", "transport", transport.Name())
+}
+
+// newHTTPItem - creates a new http data channel item
+func (m *Manager) newHTTPItem(schemaName string, parameters []interface{}) (jsonSerializer.ArrayItem, error) {
 
 	if !m.transport.MatchType(typeHTTP) {
-		return fmt.Errorf("this transport does not accepts http messages")
+		return jsonSerializer.ArrayItem{}, fmt.Errorf("this transport does not accepts http messages")
 	}
 
-	m.transport.DataChannel() <- jsonSerializer.ArrayItem{
+	parameters, err := m.tags.processParameters(parameters, true)
+	if err != nil {
+		return jsonSerializer.ArrayItem{}, err
+	}
+
+	if err := m.tags.checkRequiredParameters(parameters); err != nil {
+		return jsonSerializer.ArrayItem{}, err
+	}
+
+	return jsonSerializer.ArrayItem{
 		Name:       schemaName,
 		Parameters: parameters,
+	}, nil
+}
+
+// SendHTTP - sends a new data using the http transport (safe for concurrent use)
+func (m *Manager) SendHTTP(schemaName string, parameters ...interface{}) error {
+
+	item, err := m.newHTTPItem(schemaName, parameters)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if err := m.beginSend(); err != nil {
+		return err
+	}
+
+	defer m.endSend()
+
+	return enqueue(m.transport, item, m.shutdownChan)
+}
+
+// TrySendHTTP - sends a new data using the http transport without blocking, returning false if the buffer is full
+// (the point is not buffered and the overflow policy is not applied, letting the caller decide what to do)
+func (m *Manager) TrySendHTTP(schemaName string, parameters ...interface{}) (bool, error) {
+
+	item, err := m.newHTTPItem(schemaName, parameters)
+	if err != nil {
+		return false, err
+	}
+
+	if err := m.beginSend(); err != nil {
+		return false, err
+	}
+
+	defer m.endSend()
+
+	return tryEnqueue(m.transport, item), nil
+}
+
+// SendHTTPSync - sends a new data using the http transport, bypassing the buffer and returning the backend result
+func (m *Manager) SendHTTPSync(ctx context.Context, schemaName string, parameters ...interface{}) error {
+
+	return m.SendHTTPSyncResult(ctx, schemaName, parameters...).Err
+}
+
+// SendHTTPSyncResult - sends a new data using the http transport, bypassing the buffer and returning the send result
+func (m *Manager) SendHTTPSyncResult(ctx context.Context, schemaName string, parameters ...interface{}) SendResult {
+
+	item, err := m.newHTTPItem(schemaName, parameters)
+	if err != nil {
+		return SendResult{Err: err}
+	}
+
+	return m.sendSync(ctx, item)
 }
 
 // SerializeHTTP - serializes a point using the json serializer
 func (m *Manager) SerializeHTTP(schemaName string, parameters ...interface{}) (string, error) {
 
+	parameters, err := m.tags.processParameters(parameters, false)
+	if err != nil {
+		return "", err
+	}
+
 	return m.transport.Serialize(jsonSerializer.ArrayItem{
 		Name:       schemaName,
 		Parameters: parameters,
 	})
 }
 
-// FlattenHTTP - flatten a point
+// FlattenHTTP - flatten a point (safe for concurrent use)
 func (m *Manager) FlattenHTTP(operation FlatOperation, name string, parameters ...interface{}) error {
 
+	parameters, err := m.tags.processParameters(parameters, true)
+	if err != nil {
+		return err
+	}
+
+	if err := m.tags.checkRequiredParameters(parameters); err != nil {
+		return err
+	}
+
 	flattenerPoint, err := m.transport.DataChannelItemToFlattenedPoint(
 		operation,
 		&jsonSerializer.ArrayItem{
@@ -107,33 +211,140 @@ func (m *Manager) FlattenHTTP(operation FlatOperation, name string, parameters .
 		return err
 	}
 
+	if err := m.beginSend(); err != nil {
+		return err
+	}
+
+	defer m.endSend()
+
 	return m.flattener.Add(flattenerPoint)
 }
 
-// SendOpenTSDB - sends a new data using the openTSDB transport
-func (m *Manager) SendOpenTSDB(value float64, timestamp int64, metric string, tags ...interface{}) error {
+// FlattenHTTPKind - flatten a point using the operation matching the point kind
+func (m *Manager) FlattenHTTPKind(kind PointKind, name string, parameters ...interface{}) error {
+
+	operation, err := kind.Operation()
+	if err != nil {
+		return err
+	}
+
+	return m.FlattenHTTP(operation, name, parameters...)
+}
+
+// newOpenTSDBItem - creates a new opentsdb data channel item
+func (m *Manager) newOpenTSDBItem(value float64, timestamp int64, metric string, tags []interface{}) (openTSDBSerializer.ArrayItem, error) {
 
 	if !m.transport.MatchType(typeOpenTSDB) {
-		return fmt.Errorf("this transport does not accepts opentsdb messages")
+		return openTSDBSerializer.ArrayItem{}, fmt.Errorf("this transport does not accepts opentsdb messages")
 	}
 
 	if timestamp == 0 {
-		timestamp = time.Now().Unix()
+		timestamp = m.now()
 	}
 
-	m.transport.DataChannel() <- openTSDBSerializer.ArrayItem{
+	tags, err := m.tags.processList(tags, true)
+	if err != nil {
+		return openTSDBSerializer.ArrayItem{}, err
+	}
+
+	if err := m.tags.checkRequiredList(metric, tags); err != nil {
+		return openTSDBSerializer.ArrayItem{}, err
+	}
+
+	return openTSDBSerializer.ArrayItem{
 		Metric:    metric,
 		Tags:      tags,
 		Timestamp: timestamp,
 		Value:     value,
+	}, nil
+}
+
+// SendOpenTSDB - sends a new data using the openTSDB transport (safe for concurrent use)
+func (m *Manager) SendOpenTSDB(value float64, timestamp int64, metric string, tags ...interface{}) error {
+
+	item, err := m.newOpenTSDBItem(value, timestamp, metric, tags)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	if err := m.beginSend(); err != nil {
+		return err
+	}
+
+	defer m.endSend()
+
+	return enqueue(m.transport, item, m.shutdownChan)
+}
+
+// TrySendOpenTSDB - sends a new data using the openTSDB transport without blocking, returning false if the buffer is full
+// (the point is not buffered and the overflow policy is not applied, letting the caller decide what to do)
+func (m *Manager) TrySendOpenTSDB(value float64, timestamp int64, metric string, tags ...interface{}) (bool, error) {
+
+	item, err := m.newOpenTSDBItem(value, timestamp, metric, tags)
+	if err != nil {
+		return false, err
+	}
+
+	if err := m.beginSend(); err != nil {
+		return false, err
+	}
+
+	defer m.endSend()
+
+	return tryEnqueue(m.transport, item), nil
+}
+
+// SendOpenTSDBSync - sends a new data using the openTSDB transport, bypassing the buffer and returning the backend result
+func (m *Manager) SendOpenTSDBSync(ctx context.Context, value float64, timestamp int64, metric string, tags ...interface{}) error {
+
+	return m.SendOpenTSDBSyncResult(ctx, value, timestamp, metric, tags...).Err
+}
+
+// SendOpenTSDBSyncResult - sends a new data using the openTSDB transport, bypassing the buffer and returning the send result
+func (m *Manager) SendOpenTSDBSyncResult(ctx context.Context, value float64, timestamp int64, metric string, tags ...interface{}) SendResult {
+
+	item, err := m.newOpenTSDBItem(value, timestamp, metric, tags)
+	if err != nil {
+		return SendResult{Err: err}
+	}
+
+	return m.sendSync(ctx, item)
+}
+
+// now - returns the current timestamp using the transport timestamp precision
+func (m *Manager) now() int64 {
+
+	if ct, ok := m.transport.(coreTransport); ok {
+		return ct.getCore().now()
+	}
+
+	return time.Now().Unix()
+}
+
+// sendSync - sends a single item immediately (bounded by the context and the transport request timeout)
+func (m *Manager) sendSync(ctx context.Context, item interface{}) SendResult {
+
+	if atomic.LoadInt32(&m.state) == stateClosed {
+		return SendResult{Err: ErrTransportClosed}
+	}
+
+	start := time.Now()
+
+	if ct, ok := m.transport.(coreTransport); ok {
+		return newSendResult(1, start, ct.getCore().sendBatchCtx(ctx, []interface{}{item}))
+	}
+
+	return newSendResult(1, start, m.transport.TransferData(ctx, []interface{}{item}))
 }
 
 // SerializeOpenTSDB - serializes a point using the opentsdb serializer
 func (m *Manager) SerializeOpenTSDB(value float64, timestamp int64, metric string, tags ...interface{}) (string, error) {
 
+	tags, err := m.tags.processList(tags, false)
+	if err != nil {
+		return "", err
+	}
+
 	return m.transport.Serialize(openTSDBSerializer.ArrayItem{
 		Metric:    metric,
 		Tags:      tags,
@@ -142,11 +353,20 @@ func (m *Manager) SerializeOpenTSDB(value float64, timestamp int64, metric strin
 	})
 }
 
-// FlattenOpenTSDB - flatten a point
+// FlattenOpenTSDB - flatten a point (safe for concurrent use)
 func (m *Manager) FlattenOpenTSDB(operation FlatOperation, value float64, timestamp int64, metric string, tags ...interface{}) error {
 
 	if timestamp == 0 {
-		timestamp = time.Now().Unix()
+		timestamp = m.now()
+	}
+
+	tags, err := m.tags.processList(tags, true)
+	if err != nil {
+		return err
+	}
+
+	if err := m.tags.checkRequiredList(metric, tags); err != nil {
+		return err
 	}
 
 	flattenerPoint, err := m.transport.DataChannelItemToFlattenedPoint(
@@ -163,29 +383,68 @@ func (m *Manager) FlattenOpenTSDB(operation FlatOperation, value float64, timest
 		return err
 	}
 
+	if err := m.beginSend(); err != nil {
+		return err
+	}
+
+	defer m.endSend()
+
 	return m.flattener.Add(flattenerPoint)
 }
 
-// Start - starts the manager
+// FlattenOpenTSDBKind - flatten a point using the operation matching the point kind
+func (m *Manager) FlattenOpenTSDBKind(kind PointKind, value float64, timestamp int64, metric string, tags ...interface{}) error {
+
+	operation, err := kind.Operation()
+	if err != nil {
+		return err
+	}
+
+	return m.FlattenOpenTSDB(operation, value, timestamp, metric, tags...)
+}
+
+// Start - starts the manager (does nothing if it was already started)
 func (m *Manager) Start() error {
 
-	if m.flattener != nil {
-		return m.flattener.Start()
+	if logh.InfoEnabled {
+		m.loggers.Info().Msg("starting manager...")
 	}
 
-	return m.transport.Start()
+	m.lifecycleMutex.Lock()
+	defer m.lifecycleMutex.Unlock()
+
+	return m.start()
 }
 
-// Shutdown - shuts down the transport
-func (m *Manager) Shutdown() {
+// Shutdown - shuts down the transport (returns all errors found while shutting down, the sends after it return ErrTransportClosed)
+// (safe to call while other goroutines are sending, it waits the sends in progress before closing the buffer)
+func (m *Manager) Shutdown() error {
+
+	if !m.markClosed() {
+		return ErrTransportClosed
+	}
+
+	if logh.InfoEnabled {
+		m.loggers.Info().Msg("shutting down manager...")
+	}
+
+	m.stopSelfMetrics()
+
+	var err error
 
 	if m.flattener != nil {
-		m.flattener.Close()
+		err = m.flattener.Close()
+	} else {
+		err = m.transport.Close()
+	}
 
-		return
+	if err != nil {
+		if logh.ErrorEnabled {
+			m.loggers.Error().Err(err).Msg("errors found shutting down the manager")
+		}
 	}
 
-	m.transport.Close()
+	return err
 }
 
 // GetTransport - returns the configured transport
@@ -193,3 +452,135 @@ func (m *Manager) GetTransport() Transport {
 
 	return m.transport
 }
+
+// GetHTTPTransport - returns the configured transport if it is a HTTP transport
+func (m *Manager) GetHTTPTransport() (*HTTPTransport, bool) {
+
+	t, ok := m.transport.(*HTTPTransport)
+	return t, ok
+}
+
+// GetOpenTSDBTransport - returns the configured transport if it is an OpenTSDB transport
+func (m *Manager) GetOpenTSDBTransport() (*OpenTSDBTransport, bool) {
+
+	t, ok := m.transport.(*OpenTSDBTransport)
+	return t, ok
+}
+
+// GetKafkaTransport - returns the configured transport if it is a Kafka transport
+func (m *Manager) GetKafkaTransport() (*KafkaTransport, bool) {
+
+	t, ok := m.transport.(*KafkaTransport)
+	return t, ok
+}
+
+// GetPromRemoteWriteTransport - returns the configured transport if it is a Prometheus remote write transport
+func (m *Manager) GetPromRemoteWriteTransport() (*PromRemoteWriteTransport, bool) {
+
+	t, ok := m.transport.(*PromRemoteWriteTransport)
+	return t, ok
+}
+
+// HealthCheck - checks the backend health using the transport (if supported)
+func (m *Manager) HealthCheck(ctx context.Context) error {
+
+	hc, ok := m.transport.(HealthChecker)
+	if !ok {
+		return fmt.Errorf("transport does not support health checks: %s", m.transport.Name())
+	}
+
+	return hc.HealthCheck(ctx)
+}
+
+// SetFallbackTransport - sets the transport receiving the batches failed by the primary transport, it must accept
+// the same data channel items and have its backend configured (it is not started or closed by the manager,
+// call it before starting the manager)
+func (m *Manager) SetFallbackTransport(fallback Transport) error {
+
+	if fallback == nil {
+		return fmt.Errorf("fallback transport is required")
+	}
+
+	if fallback == m.transport {
+		return fmt.Errorf("the fallback transport must not be the primary transport")
+	}
+
+	ct, ok := m.transport.(coreTransport)
+	if !ok {
+		return fmt.Errorf("the primary transport does not support a fallback transport")
+	}
+
+	ct.getCore().fallback = fallback
+
+	return nil
+}
+
+// BufferLen - returns the number of points buffered by the transport
+func (m *Manager) BufferLen() int {
+
+	if ct, ok := m.transport.(coreTransport); ok {
+		return len(ct.getCore().pointChannel)
+	}
+
+	return 0
+}
+
+// Drain - blocks until all buffered points are sent by the transport loop (not triggering a send),
+// returns the context error if it is done before (the points buffered by the flattener are not waited)
+func (m *Manager) Drain(ctx context.Context) error {
+
+	ct, ok := m.transport.(coreTransport)
+	if !ok {
+		return nil
+	}
+
+	core := ct.getCore()
+
+	for !core.drained(ctx) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(drainCheckInterval):
+		}
+	}
+
+	return nil
+}
+
+// Flush - sends the buffered and pending points now, waiting for the send result
+// (if the send fails the points are kept as pending to be resent by the next send, flush or shutdown)
+func (m *Manager) Flush(ctx context.Context) SendResult {
+
+	if atomic.LoadInt32(&m.state) == stateClosed {
+		return SendResult{Err: ErrTransportClosed}
+	}
+
+	ct, ok := m.transport.(coreTransport)
+	if !ok {
+		return SendResult{Err: fmt.Errorf("transport does not support flushing: %s", m.transport.Name())}
+	}
+
+	return ct.getCore().flush(ctx)
+}
+
+// BufferCap - returns the max number of points buffered by the transport
+func (m *Manager) BufferCap() int {
+
+	if ct, ok := m.transport.(coreTransport); ok {
+		return cap(ct.getCore().pointChannel)
+	}
+
+	return 0
+}
+
+// Stats - returns the transport statistics
+func (m *Manager) Stats() Stats {
+
+	if ct, ok := m.transport.(coreTransport); ok {
+		return ct.getCore().stats()
+	}
+
+	return Stats{
+		Transport: m.transport.Name(),
+	}
+}
@@ -1,9 +1,12 @@
 package timeline
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"sync"
 	"time"
 
 	"github.com/uol/gobol/logh"
@@ -17,11 +20,12 @@ import (
 
 // OpenTSDBTransport - implements the openTSDB transport
 type OpenTSDBTransport struct {
-	core          transportCore
-	configuration *OpenTSDBTransportConfig
-	serializer    *serializer.Serializer
-	address       *net.TCPAddr
-	connection    net.Conn
+	core            transportCore
+	configuration   *OpenTSDBTransportConfig
+	serializer      *serializer.Serializer
+	address         *net.TCPAddr
+	connection      net.Conn
+	connectionMutex sync.Mutex
 }
 
 // OpenTSDBTransportConfig - has all openTSDB event manager configurations
@@ -60,11 +64,7 @@ func NewOpenTSDBTransport(configuration *OpenTSDBTransportConfig) (*OpenTSDBTran
 	s := serializer.New(configuration.SerializerBufferSize)
 
 	t := &OpenTSDBTransport{
-		core: transportCore{
-			batchSendInterval: configuration.BatchSendInterval,
-			pointChannel:      make(chan interface{}, configuration.TransportBufferSize),
-			loggers:           logh.CreateContextualLogger("pkg", "timeline/opentsdb"),
-		},
+		core:          newTransportCore(&configuration.DefaultTransportConfiguration, "timeline/opentsdb"),
 		configuration: configuration,
 		serializer:    s,
 	}
@@ -87,7 +87,17 @@ func (t *OpenTSDBTransport) ConfigureBackend(backend *Backend) error {
 		return err
 	}
 
-	t.retryConnect()
+	ctx, cancel := context.WithTimeout(t.core.context, t.configuration.RequestTimeout)
+	defer cancel()
+
+	t.connectionMutex.Lock()
+	defer t.connectionMutex.Unlock()
+
+	if err := t.retryConnect(ctx); err != nil {
+		if logh.ErrorEnabled {
+			t.core.loggers.Error().Msg(fmt.Sprintf("%s (connecting again on the next send)", err.Error()))
+		}
+	}
 
 	return nil
 }
@@ -109,7 +119,7 @@ func (t *OpenTSDBTransport) recover() {
 }
 
 // TransferData - transfers the data to the backend throught this transport
-func (t *OpenTSDBTransport) TransferData(dataList []interface{}) error {
+func (t *OpenTSDBTransport) TransferData(ctx context.Context, dataList []interface{}) error {
 
 	numPoints := len(dataList)
 	points := make([]serializer.ArrayItem, numPoints)
@@ -126,26 +136,63 @@ func (t *OpenTSDBTransport) TransferData(dataList []interface{}) error {
 		return err
 	}
 
+	// the connection is shared by the transfer loop and the synchronous sends
+	t.connectionMutex.Lock()
+	defer t.connectionMutex.Unlock()
+
 	defer t.recover()
 
 	for {
-		if !t.writePayload(payload) {
-			t.closeConnection()
-			t.retryConnect()
-		} else {
-			break
+		if ctx.Err() != nil {
+			return fmt.Errorf("error writing payload: %s", ctx.Err().Error())
+		}
+
+		if t.connection != nil && t.writePayload(ctx, payload) {
+			return nil
+		}
+
+		t.closeConnection()
+
+		if err := t.retryConnect(ctx); err != nil {
+			return fmt.Errorf("error writing payload: %w", err)
 		}
 	}
+}
 
-	return nil
+// contextDeadline - returns the context deadline if it comes before the timeout
+func contextDeadline(ctx context.Context, timeout time.Duration) time.Time {
+
+	d := time.Now().Add(timeout)
+
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(d) {
+		return ctxDeadline
+	}
+
+	return d
 }
 
-// writePayload - writes the payload
-func (t *OpenTSDBTransport) writePayload(payload string) bool {
+// readDeadline - returns the deadline of the connection check, bounded by the max read timeout and by half of the
+// time left by the context (the other half is left to the write)
+func readDeadline(ctx context.Context, maxReadTimeout time.Duration) time.Time {
+
+	now := time.Now()
+	d := now.Add(maxReadTimeout)
+
+	if ctxDeadline, ok := ctx.Deadline(); ok {
+		if half := now.Add(ctxDeadline.Sub(now) / 2); half.Before(d) {
+			return half
+		}
+	}
+
+	return d
+}
+
+// writePayload - writes the payload (the read and write deadlines are bound to the context deadline)
+func (t *OpenTSDBTransport) writePayload(ctx context.Context, payload string) bool {
 
 	readBuffer := make([]byte, 32)
 
-	err := t.connection.SetReadDeadline(time.Now().Add(t.configuration.MaxReadTimeout))
+	err := t.connection.SetReadDeadline(readDeadline(ctx, t.configuration.MaxReadTimeout))
 	if err != nil {
 		if logh.ErrorEnabled {
 			t.core.loggers.Error().Msg(fmt.Sprintf("error setting read deadline: %s", err.Error()))
@@ -161,7 +208,7 @@ func (t *OpenTSDBTransport) writePayload(payload string) bool {
 		}
 	}
 
-	err = t.connection.SetWriteDeadline(time.Now().Add(t.configuration.RequestTimeout))
+	err = t.connection.SetWriteDeadline(contextDeadline(ctx, t.configuration.RequestTimeout))
 	if err != nil {
 		if logh.ErrorEnabled {
 			t.core.loggers.Error().Msg(fmt.Sprintf("error writing on connection: %s", err.Error()))
@@ -202,9 +249,13 @@ func (t *OpenTSDBTransport) logConnectionError(err error, operation rwOp) {
 	}
 }
 
-// closeConnection - closes the active connection
+// closeConnection - closes the active connection (must be called holding the connection lock)
 func (t *OpenTSDBTransport) closeConnection() {
 
+	if t.connection == nil {
+		return
+	}
+
 	err := t.connection.Close()
 	if err != nil {
 		if logh.ErrorEnabled {
@@ -228,6 +279,13 @@ func (t *OpenTSDBTransport) MatchType(tt transportType) bool {
 // DataChannelItemToFlattenedPoint - converts the data channel item to the flattened point one
 func (t *OpenTSDBTransport) DataChannelItemToFlattenedPoint(operation FlatOperation, instance interface{}) (*FlattenerPoint, error) {
 
+	return openTSDBItemToFlattenedPoint(operation, instance, &t.core)
+}
+
+// openTSDBItemToFlattenedPoint - converts the opentsdb data channel item to the flattened point one
+// (the core generates the missing timestamps)
+func openTSDBItemToFlattenedPoint(operation FlatOperation, instance interface{}, core *transportCore) (*FlattenerPoint, error) {
+
 	item, ok := instance.(*serializer.ArrayItem)
 	if !ok {
 		return nil, fmt.Errorf("error casting instance to data channel item")
@@ -238,7 +296,7 @@ func (t *OpenTSDBTransport) DataChannelItemToFlattenedPoint(operation FlatOperat
 	hashParameters = append(hashParameters, item.Tags...)
 
 	if item.Timestamp <= 0 {
-		item.Timestamp = time.Now().Unix()
+		item.Timestamp = core.now()
 	}
 
 	return &FlattenerPoint{
@@ -255,6 +313,12 @@ func (t *OpenTSDBTransport) DataChannelItemToFlattenedPoint(operation FlatOperat
 // FlattenedPointToDataChannelItem - converts the flattened point to the data channel one
 func (t *OpenTSDBTransport) FlattenedPointToDataChannelItem(point *FlattenerPoint) (interface{}, error) {
 
+	return flattenedPointToOpenTSDBItem(point)
+}
+
+// flattenedPointToOpenTSDBItem - converts the flattened point to the opentsdb data channel one
+func flattenedPointToOpenTSDBItem(point *FlattenerPoint) (interface{}, error) {
+
 	item, ok := point.dataChannelItem.(serializer.ArrayItem)
 	if !ok {
 		return nil, fmt.Errorf("error casting point's data channel item")
@@ -265,33 +329,34 @@ func (t *OpenTSDBTransport) FlattenedPointToDataChannelItem(point *FlattenerPoin
 	return item, nil
 }
 
-// retryConnect - connects the telnet client
-func (t *OpenTSDBTransport) retryConnect() {
+// retryConnect - connects the telnet client until the context is done (must be called holding the connection lock)
+func (t *OpenTSDBTransport) retryConnect(ctx context.Context) error {
 
-	connected := false
-	for {
-		connected = t.connect()
-		if connected {
-			break
+	for !t.connect(ctx) {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("error connecting to address %s: %w", t.address.String(), ctx.Err())
+		case <-time.After(t.configuration.ReconnectionTimeout):
 		}
-
-		<-time.After(t.configuration.ReconnectionTimeout)
 	}
 
 	if logh.InfoEnabled {
 		t.core.loggers.Info().Msg("connected!")
 	}
+
+	return nil
 }
 
-// connect - connects the telnet client
-func (t *OpenTSDBTransport) connect() bool {
+// connect - connects the telnet client (the dial is bound to the context deadline)
+func (t *OpenTSDBTransport) connect(ctx context.Context) bool {
 
 	if logh.InfoEnabled {
 		t.core.loggers.Info().Msg(fmt.Sprintf("connnecting to opentsdb telnet: %s:", t.address.String()))
 	}
 
-	var err error
-	t.connection, err = net.DialTCP("tcp", nil, t.address)
+	var dialer net.Dialer
+
+	connection, err := dialer.DialContext(ctx, "tcp", t.address.String())
 	if err != nil {
 		if logh.ErrorEnabled {
 			t.core.loggers.Info().Msg(fmt.Sprintf("error connecting to address: %s", t.address.String()))
@@ -299,14 +364,17 @@ func (t *OpenTSDBTransport) connect() bool {
 		return false
 	}
 
-	err = t.connection.SetDeadline(time.Time{})
+	err = connection.SetDeadline(time.Time{})
 	if err != nil {
+		connection.Close()
 		if logh.ErrorEnabled {
 			t.core.loggers.Error().Msg("error setting connection's deadline")
 		}
 		return false
 	}
 
+	t.connection = connection
+
 	return true
 }
 
@@ -316,10 +384,25 @@ func (t *OpenTSDBTransport) Start() error {
 	return t.core.Start()
 }
 
-// Close - closes this transport
-func (t *OpenTSDBTransport) Close() {
+// Close - closes this transport and the active connection
+func (t *OpenTSDBTransport) Close() error {
+
+	coreErr := t.core.Close()
+
+	t.connectionMutex.Lock()
+	defer t.connectionMutex.Unlock()
+
+	var connErr error
+	if t.connection != nil {
+		err := t.connection.Close()
+		if err != nil {
+			connErr = fmt.Errorf("error closing the connection: %s", err.Error())
+		}
 
-	t.core.Close()
+		t.connection = nil
+	}
+
+	return errors.Join(coreErr, connErr)
 }
 
 // Serialize - renders the text using the configured serializer
@@ -327,3 +410,25 @@ func (t *OpenTSDBTransport) Serialize(item interface{}) (string, error) {
 
 	return t.serializer.SerializeGeneric(item)
 }
+
+// HealthCheck - checks if the backend accepts connections
+func (t *OpenTSDBTransport) HealthCheck(ctx context.Context) error {
+
+	if t.address == nil {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	return dialHealthCheck(ctx, t.address.String())
+}
+
+// Name - returns the transport name
+func (t *OpenTSDBTransport) Name() string {
+
+	return "opentsdb"
+}
+
+// getCore - returns the transport core
+func (t *OpenTSDBTransport) getCore() *transportCore {
+
+	return &t.core
+}
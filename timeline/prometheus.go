@@ -0,0 +1,315 @@
+package timeline
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/golang/snappy"
+	"github.com/uol/gobol/logh"
+	"github.com/uol/gobol/structs"
+	"github.com/uol/gobol/util"
+	serializer "github.com/uol/serializer/opentsdb"
+)
+
+/**
+* The Prometheus remote-write transport implementation.
+**/
+
+const (
+	promMetricNameLabel        string = "__name__"
+	promRemoteWriteVersion     string = "0.1.0"
+	promRemoteWriteContentType string = "application/x-protobuf"
+)
+
+var (
+	promMetricNameRegexp = regexp.MustCompile("^[a-zA-Z_:][a-zA-Z0-9_:]*$")
+	promLabelNameRegexp  = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+)
+
+// PromRemoteWriteTransport - implements the Prometheus remote-write transport
+// (uses the same points as the openTSDB transport)
+type PromRemoteWriteTransport struct {
+	core           transportCore
+	httpClient     *http.Client
+	backendAddress string
+	serviceURL     string
+	configuration  *PromRemoteWriteTransportConfig
+}
+
+// PromRemoteWriteTransportConfig - has all Prometheus remote-write configurations
+type PromRemoteWriteTransportConfig struct {
+	DefaultTransportConfiguration
+	ServiceEndpoint string
+}
+
+// promLabel - a Prometheus label
+type promLabel struct {
+	name  string
+	value string
+}
+
+// promTimeSeries - a Prometheus time series with a single sample
+type promTimeSeries struct {
+	labels    []promLabel
+	value     float64
+	timestamp int64
+}
+
+// NewPromRemoteWriteTransport - creates a new Prometheus remote-write transport
+func NewPromRemoteWriteTransport(configuration *PromRemoteWriteTransportConfig) (*PromRemoteWriteTransport, error) {
+
+	if configuration == nil {
+		return nil, fmt.Errorf("null configuration found")
+	}
+
+	if err := configuration.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(configuration.ServiceEndpoint) == 0 {
+		return nil, fmt.Errorf("service endpoint is not configured")
+	}
+
+	t := &PromRemoteWriteTransport{
+		core:          newTransportCore(&configuration.DefaultTransportConfiguration, "timeline/prometheus"),
+		configuration: configuration,
+		httpClient:    util.CreateHTTPClient(configuration.RequestTimeout, true),
+	}
+
+	t.core.transport = t
+
+	return t, nil
+}
+
+// ConfigureBackend - configures the backend
+func (t *PromRemoteWriteTransport) ConfigureBackend(backend *Backend) error {
+
+	if backend == nil {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	t.backendAddress = fmt.Sprintf("%s:%d", backend.Host, backend.Port)
+	t.serviceURL = fmt.Sprintf("http://%s/%s", t.backendAddress, t.configuration.ServiceEndpoint)
+
+	if logh.InfoEnabled {
+		t.core.loggers.Info().Msg(fmt.Sprintf("backend was configured to use service: %s", t.serviceURL))
+	}
+
+	return nil
+}
+
+// DataChannel - send a new point
+func (t *PromRemoteWriteTransport) DataChannel() chan<- interface{} {
+
+	return t.core.pointChannel
+}
+
+// TransferData - transfers the data to the backend throught this transport
+func (t *PromRemoteWriteTransport) TransferData(ctx context.Context, dataList []interface{}) error {
+
+	series := make([]promTimeSeries, len(dataList))
+
+	for i := 0; i < len(dataList); i++ {
+
+		item, ok := dataList[i].(serializer.ArrayItem)
+		if !ok {
+			return fmt.Errorf("error casting data to serializer.ArrayItem")
+		}
+
+		s, err := toPromTimeSeries(&item, t.core.precision)
+		if err != nil {
+			return err
+		}
+
+		series[i] = *s
+	}
+
+	payload := snappy.Encode(nil, encodePromWriteRequest(series))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.serviceURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", promRemoteWriteContentType)
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", promRemoteWriteVersion)
+
+	res, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+
+		reqResponse, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return fmt.Errorf("error reading body: %s", err.Error())
+		}
+
+		return &StatusError{Status: res.StatusCode, Body: string(reqResponse)}
+	}
+
+	return nil
+}
+
+// toPromTimeSeries - converts an opentsdb item to a Prometheus time series
+// (the timestamp is converted from the precision to milliseconds)
+func toPromTimeSeries(item *serializer.ArrayItem, precision structs.TimestampPrecision) (*promTimeSeries, error) {
+
+	if !promMetricNameRegexp.MatchString(item.Metric) {
+		return nil, fmt.Errorf("invalid prometheus metric name: %s", item.Metric)
+	}
+
+	if len(item.Tags)%2 != 0 {
+		return nil, fmt.Errorf("expected an even number of tags for metric: %s", item.Metric)
+	}
+
+	labels := make([]promLabel, 0, len(item.Tags)/2+1)
+	labels = append(labels, promLabel{name: promMetricNameLabel, value: item.Metric})
+
+	for i := 0; i < len(item.Tags); i += 2 {
+
+		name := fmt.Sprint(item.Tags[i])
+		if !promLabelNameRegexp.MatchString(name) || name == promMetricNameLabel {
+			return nil, fmt.Errorf("invalid prometheus label name: %s", name)
+		}
+
+		value := fmt.Sprint(item.Tags[i+1])
+		if !utf8.ValidString(value) {
+			return nil, fmt.Errorf("invalid prometheus label value for label: %s", name)
+		}
+
+		if len(value) == 0 {
+			continue
+		}
+
+		labels = append(labels, promLabel{name: name, value: value})
+	}
+
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].name < labels[j].name
+	})
+
+	return &promTimeSeries{
+		labels:    labels,
+		value:     item.Value,
+		timestamp: precision.Milliseconds(item.Timestamp),
+	}, nil
+}
+
+// encodePromWriteRequest - encodes the series using the remote-write WriteRequest protobuf message
+func encodePromWriteRequest(series []promTimeSeries) []byte {
+
+	request := []byte{}
+
+	for _, s := range series {
+
+		ts := []byte{}
+
+		for _, l := range s.labels {
+			label := appendProtoBytes(nil, 1, []byte(l.name))
+			label = appendProtoBytes(label, 2, []byte(l.value))
+			ts = appendProtoBytes(ts, 1, label)
+		}
+
+		sample := appendProtoKey(nil, 1, 1)
+		sample = binary.LittleEndian.AppendUint64(sample, math.Float64bits(s.value))
+		sample = appendProtoKey(sample, 2, 0)
+		sample = binary.AppendUvarint(sample, uint64(s.timestamp))
+		ts = appendProtoBytes(ts, 2, sample)
+
+		request = appendProtoBytes(request, 1, ts)
+	}
+
+	return request
+}
+
+// appendProtoKey - appends a protobuf field key
+func appendProtoKey(buffer []byte, field int, wireType int) []byte {
+
+	return binary.AppendUvarint(buffer, uint64(field<<3|wireType))
+}
+
+// appendProtoBytes - appends a length delimited protobuf field
+func appendProtoBytes(buffer []byte, field int, data []byte) []byte {
+
+	buffer = appendProtoKey(buffer, field, 2)
+	buffer = binary.AppendUvarint(buffer, uint64(len(data)))
+
+	return append(buffer, data...)
+}
+
+// MatchType - checks if this transport implementation matches the given type
+func (t *PromRemoteWriteTransport) MatchType(tt transportType) bool {
+
+	return tt == typeOpenTSDB
+}
+
+// DataChannelItemToFlattenedPoint - converts the data channel item to the flattened point one
+func (t *PromRemoteWriteTransport) DataChannelItemToFlattenedPoint(operation FlatOperation, instance interface{}) (*FlattenerPoint, error) {
+
+	return openTSDBItemToFlattenedPoint(operation, instance, &t.core)
+}
+
+// FlattenedPointToDataChannelItem - converts the flattened point to the data channel one
+func (t *PromRemoteWriteTransport) FlattenedPointToDataChannelItem(point *FlattenerPoint) (interface{}, error) {
+
+	return flattenedPointToOpenTSDBItem(point)
+}
+
+// Start - starts this transport
+func (t *PromRemoteWriteTransport) Start() error {
+
+	return t.core.Start()
+}
+
+// Close - closes this transport
+func (t *PromRemoteWriteTransport) Close() error {
+
+	return t.core.Close()
+}
+
+// Serialize - renders the item as an uncompressed remote-write protobuf message
+func (t *PromRemoteWriteTransport) Serialize(item interface{}) (string, error) {
+
+	casted, ok := item.(serializer.ArrayItem)
+	if !ok {
+		return "", fmt.Errorf("error casting data to serializer.ArrayItem")
+	}
+
+	s, err := toPromTimeSeries(&casted, t.core.precision)
+	if err != nil {
+		return "", err
+	}
+
+	return string(encodePromWriteRequest([]promTimeSeries{*s})), nil
+}
+
+// HealthCheck - checks if the backend accepts connections
+func (t *PromRemoteWriteTransport) HealthCheck(ctx context.Context) error {
+
+	return dialHealthCheck(ctx, t.backendAddress)
+}
+
+// Name - returns the transport name
+func (t *PromRemoteWriteTransport) Name() string {
+
+	return "prometheus"
+}
+
+// getCore - returns the transport core
+func (t *PromRemoteWriteTransport) getCore() *transportCore {
+
+	return &t.core
+}
@@ -0,0 +1,64 @@
+package timeline
+
+import (
+	"net/http"
+
+	"github.com/uol/gobol/util"
+)
+
+/**
+* Exposes the timeline pipeline metrics in the prometheus text format.
+**/
+
+// PrometheusHandler - returns a http handler exposing the manager statistics in the prometheus text format
+// (timeline_points_sent_total, timeline_points_dropped_total, timeline_send_errors_total,
+// timeline_batch_send_duration_seconds and timeline_buffer_length labeled by transport)
+func (m *Manager) PrometheusHandler() http.Handler {
+
+	return util.PrometheusHandler(m.collectPrometheusMetrics)
+}
+
+// collectPrometheusMetrics - collects the manager statistics as prometheus metrics
+func (m *Manager) collectPrometheusMetrics(r *http.Request) []util.PrometheusMetric {
+
+	stats := m.Stats()
+	labels := map[string]string{"transport": stats.Transport}
+
+	return []util.PrometheusMetric{
+		{
+			Name:   "timeline_points_sent_total",
+			Help:   "The number of points sent to the backend.",
+			Type:   util.PrometheusCounter,
+			Labels: labels,
+			Value:  float64(stats.PointsSent),
+		},
+		{
+			Name:   "timeline_points_dropped_total",
+			Help:   "The number of points dropped before being sent.",
+			Type:   util.PrometheusCounter,
+			Labels: labels,
+			Value:  float64(stats.DroppedPoints),
+		},
+		{
+			Name:   "timeline_send_errors_total",
+			Help:   "The number of batches failed to be sent.",
+			Type:   util.PrometheusCounter,
+			Labels: labels,
+			Value:  float64(stats.SendErrors),
+		},
+		{
+			Name:   "timeline_batch_send_duration_seconds",
+			Help:   "The duration of the last batch send.",
+			Type:   util.PrometheusGauge,
+			Labels: labels,
+			Value:  stats.LastSendLatency.Seconds(),
+		},
+		{
+			Name:   "timeline_buffer_length",
+			Help:   "The number of points waiting in the buffer.",
+			Type:   util.PrometheusGauge,
+			Labels: labels,
+			Value:  float64(stats.BufferedPoints),
+		},
+	}
+}
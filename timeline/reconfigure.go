@@ -0,0 +1,89 @@
+package timeline
+
+import (
+	"fmt"
+
+	"github.com/uol/gobol/logh"
+	"github.com/uol/gobol/util"
+)
+
+/**
+* Applies a new configuration to a running transport.
+**/
+
+// reconfigure - applies the changeable settings and resets the batch send interval timer
+// (the buffer is only resized if the transport was not started)
+func (t *transportCore) reconfigure(configuration *DefaultTransportConfiguration) error {
+
+	if configuration.DisableBatching != t.disableBatching {
+		return fmt.Errorf("the batching mode cannot be changed")
+	}
+
+	if configuration.OverflowPolicy != t.overflowPolicy {
+		return fmt.Errorf("the overflow policy cannot be changed")
+	}
+
+	if configuration.TimestampPrecision != t.precision {
+		return fmt.Errorf("the timestamp precision cannot be changed")
+	}
+
+	if configuration.TransportBufferSize != cap(t.pointChannel) {
+
+		if t.isStarted() {
+			return fmt.Errorf("the transport buffer size cannot be changed while the transport is running")
+		}
+
+		t.pointChannel = make(chan interface{}, configuration.TransportBufferSize)
+	}
+
+	t.settingsMutex.Lock()
+	t.settings = newTransportSettings(configuration)
+	t.settingsMutex.Unlock()
+
+	select {
+	case t.resetChan <- struct{}{}:
+	default:
+	}
+
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg(fmt.Sprintf("transport was reconfigured with batch send interval: %s", configuration.BatchSendInterval))
+	}
+
+	return nil
+}
+
+// Reconfigure - applies the new configuration to the transport (safe to call while running)
+// (the batch send interval timer is reset, the endpoints and request settings apply to the next batch,
+// the buffer sizes, batching mode, overflow policy, timestamp precision and properties cannot be changed)
+func (t *HTTPTransport) Reconfigure(configuration *HTTPTransportConfig) error {
+
+	if err := validateHTTPConfiguration(configuration); err != nil {
+		return err
+	}
+
+	t.configMutex.Lock()
+	defer t.configMutex.Unlock()
+
+	if configuration.SerializerBufferSize != t.configuration.SerializerBufferSize {
+		return fmt.Errorf("the serializer buffer size cannot be changed")
+	}
+
+	if configuration.TimestampProperty != t.configuration.TimestampProperty {
+		return fmt.Errorf("the timestamp property cannot be changed")
+	}
+
+	if configuration.ValueProperty != t.configuration.ValueProperty {
+		return fmt.Errorf("the value property cannot be changed")
+	}
+
+	if err := t.core.reconfigure(&configuration.DefaultTransportConfiguration); err != nil {
+		return err
+	}
+
+	t.configuration = configuration
+	t.httpClient = util.CreateHTTPClient(configuration.RequestTimeout, true)
+	t.floatFactor = floatFactor(configuration.FloatPrecision)
+	t.targets = t.newTargets(t.backends)
+
+	return nil
+}
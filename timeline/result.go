@@ -0,0 +1,31 @@
+package timeline
+
+import "time"
+
+/**
+* The result of the synchronous sends and flushes.
+**/
+
+// SendResult - the delivery details of a synchronous send or flush (no points are sent when the error is set)
+type SendResult struct {
+	PointsSent  int
+	BatchesSent int
+	Duration    time.Duration
+	Err         error
+}
+
+// newSendResult - creates the result of sending the number of points in a single batch
+func newSendResult(numPoints int, start time.Time, err error) SendResult {
+
+	result := SendResult{
+		Duration: time.Since(start),
+		Err:      err,
+	}
+
+	if err == nil && numPoints > 0 {
+		result.PointsSent = numPoints
+		result.BatchesSent = 1
+	}
+
+	return result
+}
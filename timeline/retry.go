@@ -0,0 +1,175 @@
+package timeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/uol/gobol/logh"
+)
+
+/**
+* The batch send retries.
+**/
+
+// RetryJitter - the strategy randomizing the retry interval to avoid synchronized retries
+type RetryJitter uint8
+
+const (
+	// NoJitter - waits the exact retry interval (default)
+	NoJitter RetryJitter = 0
+
+	// FullJitter - waits a random duration between zero and the retry interval
+	FullJitter RetryJitter = 1
+
+	// EqualJitter - waits half of the retry interval plus a random duration up to the other half
+	EqualJitter RetryJitter = 2
+)
+
+// Delay - returns the retry delay for the interval using this jitter strategy
+func (j RetryJitter) Delay(interval time.Duration) time.Duration {
+
+	if interval <= 0 {
+		return interval
+	}
+
+	switch j {
+	case FullJitter:
+		return time.Duration(rand.Int63n(int64(interval) + 1))
+	case EqualJitter:
+		half := interval / 2
+		return half + time.Duration(rand.Int63n(int64(interval-half)+1))
+	default:
+		return interval
+	}
+}
+
+// StatusError - the error returned when the backend responds with an unexpected status
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+// Error - returns the error message
+func (e *StatusError) Error() string {
+
+	return fmt.Sprintf("error body (status %d): %s", e.Status, e.Body)
+}
+
+// PartialTransferError - the error returned when only part of the points were transferred (only the failed ones are retried)
+type PartialTransferError struct {
+	Failed []interface{}
+	Err    error
+}
+
+// Error - returns the error message
+func (e *PartialTransferError) Error() string {
+
+	return e.Err.Error()
+}
+
+// Unwrap - returns the transfer error
+func (e *PartialTransferError) Unwrap() error {
+
+	return e.Err
+}
+
+// failedPoints - returns the points not transferred (all points if the error is not a partial transfer error)
+func failedPoints(err error, points []interface{}) []interface{} {
+
+	var partialErr *PartialTransferError
+	if errors.As(err, &partialErr) {
+		return partialErr.Failed
+	}
+
+	return points
+}
+
+// DefaultRetryClassifier - retries the 5xx and 429 statuses, the network errors and the kafka producer errors
+func DefaultRetryClassifier(status int, err error) bool {
+
+	if status != 0 {
+		return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+	}
+
+	var netErr net.Error
+	var producerErr *ProducerError
+
+	return errors.As(err, &netErr) || errors.As(err, &producerErr)
+}
+
+// errorStatus - returns the status from the error (zero if it is not a status error)
+func errorStatus(err error) int {
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status
+	}
+
+	return 0
+}
+
+// transferData - transfers the data retrying the errors classified as retryable
+// (each attempt is bound to the request timeout and only sends the points not transferred by the previous one,
+// the error is a PartialTransferError with the points not transferred if any point was transferred)
+func (t *transportCore) transferData(parent context.Context, points []interface{}) error {
+
+	settings := t.currentSettings()
+
+	classifier := settings.retryClassifier
+	if classifier == nil {
+		classifier = DefaultRetryClassifier
+	}
+
+	partial := false
+
+	for attempt := 0; ; attempt++ {
+
+		ctx, cancel := context.WithTimeout(parent, settings.requestTimeout)
+		err := t.transport.TransferData(ctx, points)
+		cancel()
+
+		if err == nil || attempt >= settings.maxRetries || !classifier(errorStatus(err), err) {
+			return partialTransferError(err, points, partial)
+		}
+
+		atomic.AddUint64(&t.retries, 1)
+
+		var partialErr *PartialTransferError
+		if errors.As(err, &partialErr) {
+			points = partialErr.Failed
+			partial = true
+		}
+
+		if logh.WarnEnabled {
+			t.loggers.Warn().Err(err).Msg(fmt.Sprintf("retrying the batch send (%d of %d)...", attempt+1, settings.maxRetries))
+		}
+
+		select {
+		case <-time.After(settings.retryJitter.Delay(settings.retryInterval)):
+		case <-parent.Done():
+			return partialTransferError(err, points, partial)
+		}
+	}
+}
+
+// partialTransferError - wraps the error of an attempt sending only the points not transferred by the previous ones
+// (the error is returned as is if it is nil, already partial or no point was transferred)
+func partialTransferError(err error, points []interface{}, partial bool) error {
+
+	if err == nil || !partial {
+		return err
+	}
+
+	var partialErr *PartialTransferError
+	if errors.As(err, &partialErr) {
+		return err
+	}
+
+	return &PartialTransferError{Failed: points, Err: err}
+}
@@ -0,0 +1,125 @@
+package timeline
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/uol/gobol/logh"
+	"github.com/uol/gobol/structs"
+)
+
+/**
+* Emits the timeline's own statistics through the timeline.
+**/
+
+// SelfMetricsHTTPSchema - the json mapping name registered in the http transport to send the self metrics
+const SelfMetricsHTTPSchema string = "timelineSelfMetric"
+
+// selfMetrics - the self metrics emitter state
+type selfMetrics struct {
+	interval  time.Duration
+	prefix    string
+	terminate chan struct{}
+	done      chan struct{}
+}
+
+// EnableSelfMetrics - periodically emits the manager statistics as number points through this manager,
+// the metric names are prefixed by the specified prefix (prefix_points_sent, prefix_send_errors...)
+func (m *Manager) EnableSelfMetrics(interval time.Duration, prefix string) error {
+
+	if interval <= 0 {
+		return fmt.Errorf("invalid self metrics interval: %s", interval)
+	}
+
+	if len(prefix) == 0 {
+		return fmt.Errorf("self metrics prefix is required")
+	}
+
+	if m.selfMetrics != nil {
+		return fmt.Errorf("self metrics are already enabled")
+	}
+
+	if t, ok := m.transport.(*HTTPTransport); ok {
+		err := t.AddJSONMapping(SelfMetricsHTTPSchema, structs.NumberPoint{}, "metric", "value", "timestamp", "tags")
+		if err != nil {
+			return err
+		}
+	}
+
+	m.selfMetrics = &selfMetrics{
+		interval:  interval,
+		prefix:    prefix,
+		terminate: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	go m.selfMetricsLoop(m.selfMetrics)
+
+	return nil
+}
+
+// selfMetricsLoop - emits the self metrics until terminated (the emitted points are only read from the statistics,
+// so sending them never triggers a new emission)
+func (m *Manager) selfMetricsLoop(sm *selfMetrics) {
+
+	defer close(sm.done)
+
+	for {
+		select {
+		case <-time.After(sm.interval):
+		case <-sm.terminate:
+			return
+		}
+
+		stats := m.Stats()
+		timestamp := m.now()
+
+		values := []struct {
+			name  string
+			value float64
+		}{
+			{"points_sent", float64(stats.PointsSent)},
+			{"batches_sent", float64(stats.BatchesSent)},
+			{"send_errors", float64(stats.SendErrors)},
+			{"points_dropped", float64(stats.DroppedPoints)},
+			{"buffer_depth", float64(stats.BufferedPoints)},
+			{"send_latency_ms", float64(stats.LastSendLatency) / float64(time.Millisecond)},
+			{"cardinality_exceeded", float64(m.CardinalityExceeded())},
+		}
+
+		for _, v := range values {
+			if err := m.sendSelfMetric(sm.prefix+"_"+v.name, v.value, timestamp, stats.Transport); err != nil {
+				if logh.ErrorEnabled {
+					m.loggers.Error().Err(err).Msg("error sending self metric")
+				}
+			}
+		}
+	}
+}
+
+// sendSelfMetric - sends a self metric using the transport's point type
+func (m *Manager) sendSelfMetric(metric string, value float64, timestamp int64, transport string) error {
+
+	if _, ok := m.transport.(*HTTPTransport); ok {
+		return m.SendHTTP(
+			SelfMetricsHTTPSchema,
+			"metric", metric,
+			"value", value,
+			"timestamp", timestamp,
+			"tags", map[string]string{"transport": transport},
+		)
+	}
+
+	return m.SendOpenTSDB(value, timestamp, metric, "transport", transport)
+}
+
+// stopSelfMetrics - stops the self metrics emitter (if enabled)
+func (m *Manager) stopSelfMetrics() {
+
+	if m.selfMetrics == nil {
+		return
+	}
+
+	close(m.selfMetrics.terminate)
+	<-m.selfMetrics.done
+}
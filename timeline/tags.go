@@ -0,0 +1,463 @@
+package timeline
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+/**
+* Manages the tag processing applied to the points before they are serialized.
+**/
+
+// httpTagsParameter - the parameter name containing the tag map (map[string]string) of the http points
+const httpTagsParameter string = "tags"
+
+// httpMetricParameter - the parameter name containing the metric name of the http points
+const httpMetricParameter string = "metric"
+
+// UnsetEnvTagValue - the default tag value used when the environment variable is not set
+const UnsetEnvTagValue string = "unknown"
+
+// TagLengthMode - the action taken when a tag key or value exceeds the configured length
+type TagLengthMode uint8
+
+const (
+	// TruncateTag - truncates the over-long tag key or value
+	TruncateTag TagLengthMode = 0
+
+	// RejectTag - rejects the whole point containing the over-long tag key or value
+	RejectTag TagLengthMode = 1
+)
+
+// TagLengthLimits - the tag length limits (zero means no limit)
+type TagLengthLimits struct {
+	MaxTagKeyLength   int
+	MaxTagValueLength int
+	Mode              TagLengthMode
+	TruncationMarker  string
+}
+
+// tagProcessor - processes the point tags without mutating the caller's data
+type tagProcessor struct {
+	defaults    map[string]string
+	defaultKeys []string
+	transforms  map[string]func(string) string
+	limits      TagLengthLimits
+	trace       *traceTag
+	required    map[string][]string
+	cardinality *cardinalityGuard
+	mutex       sync.RWMutex
+}
+
+// SetDefaultTags - sets the tags added to all points (the point's own tags have precedence)
+func (m *Manager) SetDefaultTags(tags map[string]string) {
+
+	defaults := make(map[string]string, len(tags))
+	keys := make([]string, 0, len(tags))
+	for k, v := range tags {
+		defaults[k] = v
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	m.tags.defaults = defaults
+	m.tags.defaultKeys = keys
+}
+
+// SetDefaultTagsFromEnv - sets the default tags resolving the values from the mapped environment variables
+// (e.g. {"env": "DEPLOY_ENV"}), the variables are read once and the unset ones resolve to UnsetEnvTagValue
+func (m *Manager) SetDefaultTagsFromEnv(envTags map[string]string) {
+
+	tags := make(map[string]string, len(envTags))
+	for k, env := range envTags {
+		value, ok := os.LookupEnv(env)
+		if !ok || len(value) == 0 {
+			value = UnsetEnvTagValue
+		}
+
+		tags[k] = value
+	}
+
+	m.SetDefaultTags(tags)
+}
+
+// SetTagTransform - sets a function to transform the value of the specified tag key before sending,
+// if the function returns an empty string the tag is dropped
+func (m *Manager) SetTagTransform(key string, fn func(string) string) {
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	if m.tags.transforms == nil {
+		m.tags.transforms = map[string]func(string) string{}
+	}
+
+	if fn == nil {
+		delete(m.tags.transforms, key)
+		return
+	}
+
+	m.tags.transforms[key] = fn
+}
+
+// SetTagLengthLimits - sets the tag key and value length limits applied before sending
+func (m *Manager) SetTagLengthLimits(limits TagLengthLimits) error {
+
+	if limits.MaxTagKeyLength < 0 {
+		return fmt.Errorf("invalid max tag key length: %d", limits.MaxTagKeyLength)
+	}
+
+	if limits.MaxTagValueLength < 0 {
+		return fmt.Errorf("invalid max tag value length: %d", limits.MaxTagValueLength)
+	}
+
+	if limits.Mode != TruncateTag && limits.Mode != RejectTag {
+		return fmt.Errorf("invalid tag length mode: %d", limits.Mode)
+	}
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	m.tags.limits = limits
+
+	return nil
+}
+
+// RequireTags - sets the tag keys required in every point of the metric (the points missing them are rejected
+// when sent or flattened), calling it without keys removes the requirement
+func (m *Manager) RequireTags(metric string, keys ...string) {
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	if m.tags.required == nil {
+		m.tags.required = map[string][]string{}
+	}
+
+	if len(keys) == 0 {
+		delete(m.tags.required, metric)
+		return
+	}
+
+	m.tags.required[metric] = append([]string{}, keys...)
+}
+
+// requiredTags - returns the tag keys required by the metric
+func (tp *tagProcessor) requiredTags(metric string) []string {
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	return tp.required[metric]
+}
+
+// checkRequiredList - checks if the processed tag key/value list has all tags required by the metric
+func (tp *tagProcessor) checkRequiredList(metric string, tags []interface{}) error {
+
+	required := tp.requiredTags(metric)
+
+	for _, key := range required {
+		found := false
+		for i := 0; i+1 < len(tags); i += 2 {
+			if fmt.Sprint(tags[i]) == key {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("metric \"%s\" requires the tag: %s", metric, key)
+		}
+	}
+
+	return nil
+}
+
+// checkRequiredParameters - checks if the processed http parameters have all tags required by the metric
+func (tp *tagProcessor) checkRequiredParameters(parameters []interface{}) error {
+
+	var metric string
+	var tags map[string]string
+
+	for i := 0; i+1 < len(parameters); i += 2 {
+
+		key, ok := parameters[i].(string)
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case httpMetricParameter:
+			metric, _ = parameters[i+1].(string)
+		case httpTagsParameter:
+			tags, _ = parameters[i+1].(map[string]string)
+		}
+	}
+
+	for _, key := range tp.requiredTags(metric) {
+		if _, ok := tags[key]; !ok {
+			return fmt.Errorf("metric \"%s\" requires the tag: %s", metric, key)
+		}
+	}
+
+	return nil
+}
+
+// enabled - checks if there is any processing to be done
+func (tp *tagProcessor) enabled() bool {
+
+	return len(tp.defaults) > 0 || len(tp.transforms) > 0 || tp.limits.MaxTagKeyLength > 0 || tp.limits.MaxTagValueLength > 0 || tp.cardinality != nil
+}
+
+// truncate - truncates the text to the max length (in bytes) appending the marker
+func (tp *tagProcessor) truncate(text string, max int) string {
+
+	marker := tp.limits.TruncationMarker
+	if len(marker) >= max {
+		marker = ""
+	}
+
+	end := max - len(marker)
+	for end > 0 && !utf8.RuneStart(text[end]) {
+		end--
+	}
+
+	return text[:end] + marker
+}
+
+// limit - applies the length limit to the tag key or value
+func (tp *tagProcessor) limit(kind, text string, max int) (string, error) {
+
+	if max <= 0 || len(text) <= max {
+		return text, nil
+	}
+
+	if tp.limits.Mode == RejectTag {
+		return "", fmt.Errorf("tag %s \"%s\" exceeds the max length: %d", kind, text, max)
+	}
+
+	return tp.truncate(text, max), nil
+}
+
+// processTag - processes a tag returning false if the tag must be dropped
+func (tp *tagProcessor) processTag(key, value string) (string, string, bool, error) {
+
+	if fn, ok := tp.transforms[key]; ok {
+		value = fn(value)
+		if len(value) == 0 {
+			return "", "", false, nil
+		}
+	}
+
+	value, err := tp.limit("value", value, tp.limits.MaxTagValueLength)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	key, err = tp.limit("key", key, tp.limits.MaxTagKeyLength)
+	if err != nil {
+		return "", "", false, err
+	}
+
+	return key, value, true, nil
+}
+
+// processMap - returns a processed copy of the tag map (the cardinality guard tracks the new values only if track is set)
+func (tp *tagProcessor) processMap(tags map[string]string, track bool) (map[string]string, error) {
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	if !tp.enabled() {
+		return tags, nil
+	}
+
+	result := make(map[string]string, len(tags)+len(tp.defaults))
+
+	for k, v := range tp.defaults {
+		if _, exists := tags[k]; exists {
+			continue
+		}
+
+		if err := tp.addToMap(result, k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	for k, v := range tags {
+		if err := tp.addToMap(result, k, v); err != nil {
+			return nil, err
+		}
+	}
+
+	if tp.cardinality == nil {
+		return result, nil
+	}
+
+	keys := make([]string, 0, len(result))
+	values := make([]string, 0, len(result))
+	for k, v := range result {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	dropped, err := tp.cardinality.admit(keys, values, track)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, key := range keys {
+		if dropped[i] {
+			delete(result, key)
+		}
+	}
+
+	return result, nil
+}
+
+// addToMap - processes the tag and adds it to the map
+func (tp *tagProcessor) addToMap(result map[string]string, k, v string) error {
+
+	key, value, ok, err := tp.processTag(k, v)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		result[key] = value
+	}
+
+	return nil
+}
+
+// processList - returns a processed copy of the tag key/value list (the cardinality guard tracks the new values only
+// if track is set)
+func (tp *tagProcessor) processList(tags []interface{}, track bool) ([]interface{}, error) {
+
+	tp.mutex.RLock()
+	defer tp.mutex.RUnlock()
+
+	if !tp.enabled() {
+		return tags, nil
+	}
+
+	result := make([]interface{}, 0, len(tags)+2*len(tp.defaults))
+	keys := make(map[string]struct{}, len(tags)/2)
+
+	for i := 0; i+1 < len(tags); i += 2 {
+		keys[fmt.Sprint(tags[i])] = struct{}{}
+	}
+
+	for _, k := range tp.defaultKeys {
+		if _, exists := keys[k]; exists {
+			continue
+		}
+
+		key, value, ok, err := tp.processTag(k, tp.defaults[k])
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			result = append(result, key, value)
+		}
+	}
+
+	for i := 0; i+1 < len(tags); i += 2 {
+
+		k := fmt.Sprint(tags[i])
+		v := fmt.Sprint(tags[i+1])
+
+		key, value, ok, err := tp.processTag(k, v)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		if key == k && value == v {
+			result = append(result, tags[i], tags[i+1])
+		} else {
+			result = append(result, key, value)
+		}
+	}
+
+	if tp.cardinality == nil {
+		return result, nil
+	}
+
+	return tp.limitCardinality(result, track)
+}
+
+// limitCardinality - checks the processed tag key/value list against the cardinality guard removing the dropped tags
+func (tp *tagProcessor) limitCardinality(tags []interface{}, track bool) ([]interface{}, error) {
+
+	keys := make([]string, 0, len(tags)/2)
+	values := make([]string, 0, len(tags)/2)
+	for i := 0; i+1 < len(tags); i += 2 {
+		keys = append(keys, fmt.Sprint(tags[i]))
+		values = append(values, fmt.Sprint(tags[i+1]))
+	}
+
+	dropped, err := tp.cardinality.admit(keys, values, track)
+	if err != nil {
+		return nil, err
+	}
+
+	result := tags[:0]
+	for i := range keys {
+		if !dropped[i] {
+			result = append(result, tags[2*i], tags[2*i+1])
+		}
+	}
+
+	return result, nil
+}
+
+// processParameters - returns a copy of the http parameters with the tags parameter processed
+// (the default tags are only added when the tags parameter is present, the cardinality guard tracks the new values
+// only if track is set)
+func (tp *tagProcessor) processParameters(parameters []interface{}, track bool) ([]interface{}, error) {
+
+	tp.mutex.RLock()
+	enabled := tp.enabled()
+	tp.mutex.RUnlock()
+
+	if !enabled {
+		return parameters, nil
+	}
+
+	for i := 0; i+1 < len(parameters); i += 2 {
+
+		if key, ok := parameters[i].(string); !ok || key != httpTagsParameter {
+			continue
+		}
+
+		tags, ok := parameters[i+1].(map[string]string)
+		if !ok {
+			return parameters, nil
+		}
+
+		processed, err := tp.processMap(tags, track)
+		if err != nil {
+			return nil, err
+		}
+
+		result := make([]interface{}, len(parameters))
+		copy(result, parameters)
+		result[i+1] = processed
+
+		return result, nil
+	}
+
+	return parameters, nil
+}
@@ -0,0 +1,129 @@
+package timeline
+
+import (
+	"context"
+	"fmt"
+)
+
+/**
+* Propagates the trace id from the context as a point tag.
+**/
+
+// traceTag - the context key containing the trace id and the tag key to store it
+type traceTag struct {
+	contextKey interface{}
+	tagKey     string
+}
+
+// SetTraceIDTag - configures the context key containing the trace id (a string or fmt.Stringer),
+// added as the specified tag by the context aware send functions
+func (m *Manager) SetTraceIDTag(contextKey interface{}, tagKey string) error {
+
+	if contextKey == nil {
+		return fmt.Errorf("trace id context key is required")
+	}
+
+	if len(tagKey) == 0 {
+		return fmt.Errorf("trace id tag key is required")
+	}
+
+	m.tags.mutex.Lock()
+	defer m.tags.mutex.Unlock()
+
+	m.tags.trace = &traceTag{
+		contextKey: contextKey,
+		tagKey:     tagKey,
+	}
+
+	return nil
+}
+
+// traceID - returns the trace id tag key and value from the context
+func (m *Manager) traceID(ctx context.Context) (string, string, bool) {
+
+	m.tags.mutex.RLock()
+	trace := m.tags.trace
+	m.tags.mutex.RUnlock()
+
+	if trace == nil || ctx == nil {
+		return "", "", false
+	}
+
+	var id string
+
+	switch v := ctx.Value(trace.contextKey).(type) {
+	case string:
+		id = v
+	case fmt.Stringer:
+		id = v.String()
+	}
+
+	if len(id) == 0 {
+		return "", "", false
+	}
+
+	return trace.tagKey, id, true
+}
+
+// SendHTTPCtx - sends a new data using the http transport adding the context trace id as a tag
+// (only when the tags parameter is present and it does not already contain the trace tag)
+func (m *Manager) SendHTTPCtx(ctx context.Context, schemaName string, parameters ...interface{}) error {
+
+	key, id, ok := m.traceID(ctx)
+	if !ok {
+		return m.SendHTTP(schemaName, parameters...)
+	}
+
+	for i := 0; i+1 < len(parameters); i += 2 {
+
+		if name, ok := parameters[i].(string); !ok || name != httpTagsParameter {
+			continue
+		}
+
+		tags, ok := parameters[i+1].(map[string]string)
+		if !ok {
+			break
+		}
+
+		if _, exists := tags[key]; exists {
+			break
+		}
+
+		traced := make(map[string]string, len(tags)+1)
+		for k, v := range tags {
+			traced[k] = v
+		}
+
+		traced[key] = id
+
+		copied := make([]interface{}, len(parameters))
+		copy(copied, parameters)
+		copied[i+1] = traced
+
+		return m.SendHTTP(schemaName, copied...)
+	}
+
+	return m.SendHTTP(schemaName, parameters...)
+}
+
+// SendOpenTSDBCtx - sends a new data using the openTSDB transport adding the context trace id as a tag
+// (if the tags do not already contain the trace tag)
+func (m *Manager) SendOpenTSDBCtx(ctx context.Context, value float64, timestamp int64, metric string, tags ...interface{}) error {
+
+	key, id, ok := m.traceID(ctx)
+	if !ok {
+		return m.SendOpenTSDB(value, timestamp, metric, tags...)
+	}
+
+	for i := 0; i+1 < len(tags); i += 2 {
+		if fmt.Sprint(tags[i]) == key {
+			return m.SendOpenTSDB(value, timestamp, metric, tags...)
+		}
+	}
+
+	traced := make([]interface{}, len(tags), len(tags)+2)
+	copy(traced, tags)
+	traced = append(traced, key, id)
+
+	return m.SendOpenTSDB(value, timestamp, metric, traced...)
+}
@@ -1,10 +1,16 @@
 package timeline
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/uol/gobol/logh"
+	"github.com/uol/gobol/structs"
 )
 
 /**
@@ -28,14 +34,14 @@ type Transport interface {
 	// ConfigureBackend - configures the backend
 	ConfigureBackend(backend *Backend) error
 
-	// TransferData - transfers the data using this specific implementation
-	TransferData(dataList []interface{}) error
+	// TransferData - transfers the data using this specific implementation (respecting the context deadline)
+	TransferData(ctx context.Context, dataList []interface{}) error
 
 	// Start - starts this transport
 	Start() error
 
-	// Close - closes this transport
-	Close()
+	// Close - closes this transport (returns all errors found while closing)
+	Close() error
 
 	// MatchType - checks if this transport implementation matches the given type
 	MatchType(tt transportType) bool
@@ -48,14 +54,96 @@ type Transport interface {
 
 	// Serialize - renders the text using the configured serializer
 	Serialize(item interface{}) (string, error)
+
+	// Name - returns the transport name
+	Name() string
+}
+
+// HealthChecker - a transport able to check if its backend is healthy
+type HealthChecker interface {
+
+	// HealthCheck - checks the backend health (respecting the context deadline)
+	HealthCheck(ctx context.Context) error
+}
+
+// coreTransport - a transport implemented over the default transport core
+type coreTransport interface {
+
+	// getCore - returns the transport core
+	getCore() *transportCore
+}
+
+// OverflowPolicy - the behaviour when a point is sent to a full buffer
+type OverflowPolicy uint8
+
+const (
+	// Block - blocks the sender until there is space in the buffer (default)
+	Block OverflowPolicy = 0
+
+	// DropOldest - the buffer works as a ring, dropping the oldest point to store the new one
+	DropOldest OverflowPolicy = 1
+)
+
+// Stats - the transport statistics
+type Stats struct {
+	Transport        string
+	PointsSent       uint64
+	BatchesSent      uint64
+	SendErrors       uint64
+	FallbackBatches  uint64
+	DroppedPoints    uint64
+	SkippedIntervals uint64
+	Retries          uint64
+	BufferedPoints   int
+	PendingPoints    int
+	LastSendLatency  time.Duration
+}
+
+// transportSettings - the transport core settings changeable while the transport is running
+type transportSettings struct {
+	batchSendInterval time.Duration
+	requestTimeout    time.Duration
+	maxRetries        int
+	retryInterval     time.Duration
+	retryJitter       RetryJitter
+	retryClassifier   func(status int, err error) bool
+	maxPendingPoints  int
 }
 
 // transportCore - implements a default transport behaviour
 type transportCore struct {
-	transport         Transport
-	batchSendInterval time.Duration
-	pointChannel      chan interface{}
-	loggers           *logh.ContextualLogger
+	transport       Transport
+	settings        transportSettings
+	settingsMutex   sync.RWMutex
+	resetChan       chan struct{}
+	disableBatching bool
+	verifyOnStart   bool
+	overflowPolicy  OverflowPolicy
+	pointChannel    chan interface{}
+	loggers         *logh.ContextualLogger
+	context         context.Context
+	cancel          context.CancelFunc
+	terminateChan   chan struct{}
+	flushChan       chan chan SendResult
+	drainChan       chan chan bool
+	loopDone        chan error
+	fallback        Transport
+	onBatchSent     func(count int, duration time.Duration, err error)
+	pointsSent      uint64
+	batchesSent     uint64
+	sendErrors      uint64
+	lastSendLatency int64
+	fallbackBatches uint64
+	droppedPoints   uint64
+	onDrop          func(count int)
+	lastDropWarn    int64
+	unwarnedDrops   uint64
+	skipIntervals   uint64
+	retries         uint64
+	pending         []interface{}
+	pendingPoints   int64
+	precision       structs.TimestampPrecision
+	started         int32
 }
 
 // DefaultTransportConfiguration - the default fields used by the transport configuration
@@ -64,6 +152,15 @@ type DefaultTransportConfiguration struct {
 	BatchSendInterval    time.Duration
 	RequestTimeout       time.Duration
 	SerializerBufferSize int
+	DisableBatching      bool
+	VerifyOnStart        bool
+	OverflowPolicy       OverflowPolicy
+	MaxRetries           int
+	RetryInterval        time.Duration
+	RetryJitter          RetryJitter
+	RetryClassifier      func(status int, err error) bool
+	MaxPendingPoints     int
+	TimestampPrecision   structs.TimestampPrecision
 }
 
 // Validate - validates the default itens from the configuration
@@ -85,9 +182,79 @@ func (c *DefaultTransportConfiguration) Validate() error {
 		return fmt.Errorf("invalid request timeout interval: %s", c.RequestTimeout)
 	}
 
+	if c.OverflowPolicy != Block && c.OverflowPolicy != DropOldest {
+		return fmt.Errorf("invalid overflow policy: %d", c.OverflowPolicy)
+	}
+
+	if c.MaxRetries < 0 {
+		return fmt.Errorf("invalid max retries: %d", c.MaxRetries)
+	}
+
+	if c.RetryInterval < 0 {
+		return fmt.Errorf("invalid retry interval: %s", c.RetryInterval)
+	}
+
+	if c.RetryJitter > EqualJitter {
+		return fmt.Errorf("invalid retry jitter: %d", c.RetryJitter)
+	}
+
+	if c.MaxPendingPoints < 0 {
+		return fmt.Errorf("invalid max pending points: %d", c.MaxPendingPoints)
+	}
+
+	if c.TimestampPrecision > structs.Nanoseconds {
+		return fmt.Errorf("invalid timestamp precision: %d", c.TimestampPrecision)
+	}
+
 	return nil
 }
 
+// newTransportSettings - creates the changeable settings from the configuration
+func newTransportSettings(configuration *DefaultTransportConfiguration) transportSettings {
+
+	return transportSettings{
+		batchSendInterval: configuration.BatchSendInterval,
+		requestTimeout:    configuration.RequestTimeout,
+		maxRetries:        configuration.MaxRetries,
+		retryInterval:     configuration.RetryInterval,
+		retryJitter:       configuration.RetryJitter,
+		retryClassifier:   configuration.RetryClassifier,
+		maxPendingPoints:  configuration.MaxPendingPoints,
+	}
+}
+
+// currentSettings - returns a copy of the current settings
+func (t *transportCore) currentSettings() transportSettings {
+
+	t.settingsMutex.RLock()
+	defer t.settingsMutex.RUnlock()
+
+	return t.settings
+}
+
+// newTransportCore - creates a new transport core using the default configuration
+func newTransportCore(configuration *DefaultTransportConfiguration, pkg string) transportCore {
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return transportCore{
+		settings:        newTransportSettings(configuration),
+		resetChan:       make(chan struct{}, 1),
+		disableBatching: configuration.DisableBatching,
+		verifyOnStart:   configuration.VerifyOnStart,
+		overflowPolicy:  configuration.OverflowPolicy,
+		precision:       configuration.TimestampPrecision,
+		pointChannel:    make(chan interface{}, configuration.TransportBufferSize),
+		loggers:         logh.CreateContextualLogger("pkg", pkg),
+		context:         ctx,
+		cancel:          cancel,
+		terminateChan:   make(chan struct{}),
+		flushChan:       make(chan chan SendResult),
+		drainChan:       make(chan chan bool),
+		loopDone:        make(chan error, 1),
+	}
+}
+
 // Start - starts the transport
 func (t *transportCore) Start() error {
 
@@ -95,11 +262,121 @@ func (t *transportCore) Start() error {
 		t.loggers.Info().Msg("starting transport...")
 	}
 
-	go t.transferDataLoop()
+	if t.verifyOnStart {
+		if err := t.healthCheck(); err != nil {
+			return err
+		}
+	}
+
+	atomic.StoreInt32(&t.started, 1)
+
+	if t.disableBatching {
+		go t.transferPointLoop()
+	} else {
+		go t.transferDataLoop()
+	}
 
 	return nil
 }
 
+// now - returns the current timestamp using the configured timestamp precision
+// (used when the point has no timestamp)
+func (t *transportCore) now() int64 {
+
+	return t.precision.Timestamp(time.Now())
+}
+
+// enqueue - buffers the item using the configured overflow policy
+// (a send blocked by the full buffer gives up when the done channel is closed)
+func (t *transportCore) enqueue(item interface{}, done <-chan struct{}) error {
+
+	if t.overflowPolicy == Block {
+		select {
+		case t.pointChannel <- item:
+			return nil
+		case <-done:
+			return ErrTransportClosed
+		}
+	}
+
+	for {
+		select {
+		case t.pointChannel <- item:
+			return nil
+		default:
+		}
+
+		select {
+		case <-t.pointChannel:
+			t.drop(1)
+		default:
+		}
+	}
+}
+
+// enqueue - buffers the item in the transport (using the overflow policy if the transport has a core),
+// returning ErrTransportClosed if the done channel is closed while the buffer is full
+func enqueue(transport Transport, item interface{}, done <-chan struct{}) error {
+
+	if ct, ok := transport.(coreTransport); ok {
+		return ct.getCore().enqueue(item, done)
+	}
+
+	select {
+	case transport.DataChannel() <- item:
+		return nil
+	case <-done:
+		return ErrTransportClosed
+	}
+}
+
+// tryEnqueue - buffers the item in the transport without blocking, returning false if the buffer is full
+// (the overflow policy is not applied)
+func tryEnqueue(transport Transport, item interface{}) bool {
+
+	select {
+	case transport.DataChannel() <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// healthCheck - checks the backend health using the request timeout
+func (t *transportCore) healthCheck() error {
+
+	hc, ok := t.transport.(HealthChecker)
+	if !ok {
+		return fmt.Errorf("transport does not support health checks: %s", t.transport.Name())
+	}
+
+	ctx, cancel := context.WithTimeout(t.context, t.currentSettings().requestTimeout)
+	defer cancel()
+
+	if err := hc.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("backend health check failed: %w", err)
+	}
+
+	return nil
+}
+
+// dialHealthCheck - checks if the backend address accepts connections
+func dialHealthCheck(ctx context.Context, address string) error {
+
+	if len(address) == 0 {
+		return fmt.Errorf("no backend was configured")
+	}
+
+	var dialer net.Dialer
+
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+
+	return conn.Close()
+}
+
 // transferDataLoop - transfers the data to the backend throught this transport
 func (t *transportCore) transferDataLoop() {
 
@@ -107,12 +384,30 @@ func (t *transportCore) transferDataLoop() {
 		t.loggers.Info().Msg("initializing transfer data loop...")
 	}
 
-outterFor:
+	var interval <-chan time.Time
+
 	for {
-		<-time.After(t.batchSendInterval)
+		var flushed chan SendResult
+
+		if interval == nil {
+			interval = time.After(t.currentSettings().batchSendInterval)
+		}
+
+		select {
+		case <-interval:
+		case <-t.terminateChan:
+		case flushed = <-t.flushChan:
+		case checked := <-t.drainChan:
+			checked <- t.idle()
+			continue
+		case <-t.resetChan:
+			interval = nil
+			continue
+		}
+
+		interval = nil
 
 		points := []interface{}{}
-		numPoints := 0
 
 	innerLoop:
 		for {
@@ -123,7 +418,19 @@ outterFor:
 					if logh.InfoEnabled {
 						t.loggers.Info().Msg("breaking data transfer loop")
 					}
-					break outterFor
+
+					var result SendResult
+					if len(points) > 0 || len(t.pending) > 0 {
+						result = t.sendBuffered(points, flushed != nil)
+					}
+
+					if flushed != nil {
+						flushed <- result
+					}
+
+					t.loopDone <- result.Err
+
+					return
 				}
 
 				points = append(points, point)
@@ -133,39 +440,307 @@ outterFor:
 			}
 		}
 
-		numPoints = len(points)
-
-		if numPoints == 0 {
+		if len(points) == 0 && len(t.pending) == 0 {
+			atomic.AddUint64(&t.skipIntervals, 1)
 			if logh.InfoEnabled {
 				t.loggers.Info().Msg("buffer is empty, no data will be send")
 			}
+
+			if flushed != nil {
+				flushed <- SendResult{}
+			}
+
 			continue
 		}
 
-		if logh.InfoEnabled {
-			t.loggers.Info().Msg(fmt.Sprintf("sending a batch of %d points...", numPoints))
+		result := t.sendBuffered(points, flushed != nil)
+
+		if flushed != nil {
+			flushed <- result
 		}
+	}
+}
+
+// transferPointLoop - transfers each point to the backend as soon as it is received (batching disabled)
+func (t *transportCore) transferPointLoop() {
+
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg("initializing transfer point loop...")
+	}
 
-		err := t.transport.TransferData(points)
-		if err != nil {
-			if logh.ErrorEnabled {
-				t.loggers.Error().Msg(err.Error())
+	errs := []error{}
+
+pointLoop:
+	for {
+		var point interface{}
+		var ok bool
+
+		select {
+		case point, ok = <-t.pointChannel:
+			if !ok {
+				break pointLoop
 			}
-		} else {
-			if logh.InfoEnabled {
-				t.loggers.Info().Msg(fmt.Sprintf("batch of %d points were sent!", numPoints))
+		case flushed := <-t.flushChan:
+			points := t.drainBuffer()
+
+			var result SendResult
+			if len(points) > 0 || len(t.pending) > 0 {
+				result = t.sendBuffered(points, true)
 			}
+
+			flushed <- result
+			continue
+		case checked := <-t.drainChan:
+			checked <- t.idle()
+			continue
+		}
+
+		err := t.sendBuffered([]interface{}{point}, false).Err
+
+		select {
+		case <-t.terminateChan:
+			errs = append(errs, err)
+		default:
+		}
+	}
+
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg("breaking point transfer loop")
+	}
+
+	t.loopDone <- errors.Join(errs...)
+}
+
+// drainBuffer - reads the buffered points without blocking (the closed channel is left to the transfer loop)
+func (t *transportCore) drainBuffer() []interface{} {
+
+	points := []interface{}{}
+
+	for len(t.pointChannel) > 0 {
+		point, ok := <-t.pointChannel
+		if !ok {
+			break
+		}
+
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// sendBuffered - sends the buffered points after the pending ones (oldest first), keeping the ones not transferred
+// as pending if the send fails and the pending area is enabled or the send was flushed (retain)
+func (t *transportCore) sendBuffered(points []interface{}, retain bool) SendResult {
+
+	start := time.Now()
+	maxPendingPoints := t.currentSettings().maxPendingPoints
+
+	if maxPendingPoints == 0 && !retain && len(t.pending) == 0 {
+		return newSendResult(len(points), start, t.sendBatch(points))
+	}
+
+	if len(t.pending) > 0 {
+		points = append(t.pending, points...)
+		t.pending = nil
+	}
+
+	err := t.sendBatch(points)
+	if err != nil && (retain || maxPendingPoints > 0) {
+		t.retainPending(failedPoints(err, points), maxPendingPoints)
+	}
+
+	atomic.StoreInt64(&t.pendingPoints, int64(len(t.pending)))
+
+	return newSendResult(len(points), start, err)
+}
+
+// retainPending - keeps the failed points as pending, dropping the oldest ones exceeding the max pending points
+// (all points are kept if the max pending points is not configured)
+func (t *transportCore) retainPending(points []interface{}, maxPendingPoints int) {
+
+	if excess := len(points) - maxPendingPoints; maxPendingPoints > 0 && excess > 0 {
+		t.drop(excess)
+		points = points[excess:]
+	}
+
+	t.pending = make([]interface{}, len(points))
+	copy(t.pending, points)
+
+	if logh.WarnEnabled {
+		t.loggers.Warn().Msg(fmt.Sprintf("%d points are pending to be sent", len(t.pending)))
+	}
+}
+
+// sendBatch - sends a batch of points using a context bound to the request timeout
+func (t *transportCore) sendBatch(points []interface{}) error {
+
+	return t.sendBatchCtx(t.context, points)
+}
+
+// sendBatchCtx - sends a batch of points using the parent context bound to the request timeout
+func (t *transportCore) sendBatchCtx(parent context.Context, points []interface{}) error {
+
+	numPoints := len(points)
+
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg(fmt.Sprintf("sending a batch of %d points...", numPoints))
+	}
+
+	start := time.Now()
+	err := t.transferData(parent, points)
+	duration := time.Since(start)
+	atomic.StoreInt64(&t.lastSendLatency, int64(duration))
+
+	if t.onBatchSent != nil {
+		go t.onBatchSent(numPoints, duration, err)
+	}
+
+	if err != nil {
+		atomic.AddUint64(&t.sendErrors, 1)
+		if logh.ErrorEnabled {
+			t.loggers.Error().Msg(err.Error())
+		}
+
+		if t.fallback != nil {
+			return t.sendFallback(parent, failedPoints(err, points), err)
+		}
+
+		return err
+	}
+
+	atomic.AddUint64(&t.pointsSent, uint64(numPoints))
+	atomic.AddUint64(&t.batchesSent, 1)
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg(fmt.Sprintf("batch of %d points were sent!", numPoints))
+	}
+
+	return nil
+}
+
+// sendFallback - sends the failed batch using the fallback transport (returns the original error if it fails too)
+func (t *transportCore) sendFallback(parent context.Context, points []interface{}, cause error) error {
+
+	if logh.InfoEnabled {
+		t.loggers.Info().Msg(fmt.Sprintf("sending a failed batch of %d points to the fallback transport: %s", len(points), t.fallback.Name()))
+	}
+
+	ctx, cancel := context.WithTimeout(parent, t.currentSettings().requestTimeout)
+	err := t.fallback.TransferData(ctx, points)
+	cancel()
+
+	if err != nil {
+		if logh.ErrorEnabled {
+			t.loggers.Error().Err(err).Msg("error sending the batch to the fallback transport")
 		}
 
+		return errors.Join(cause, err)
 	}
+
+	atomic.AddUint64(&t.fallbackBatches, 1)
+
+	return nil
 }
 
-// Close - closes the transport
-func (t *transportCore) Close() {
+// Close - closes the transport, sending the remaining buffered points (returns the final send error)
+func (t *transportCore) Close() error {
 
 	if logh.InfoEnabled {
 		t.loggers.Info().Msg("closing...")
 	}
 
 	close(t.pointChannel)
+	close(t.terminateChan)
+
+	defer t.cancel()
+
+	if t.isStarted() {
+		return <-t.loopDone
+	}
+
+	points := []interface{}{}
+	for point := range t.pointChannel {
+		points = append(points, point)
+	}
+
+	if len(points) == 0 {
+		return nil
+	}
+
+	return t.sendBatch(points)
+}
+
+// flush - requests the transfer loop to send the buffered and pending points, waiting for the send result
+func (t *transportCore) flush(ctx context.Context) SendResult {
+
+	if !t.isStarted() {
+		return SendResult{Err: fmt.Errorf("transport is not started")}
+	}
+
+	flushed := make(chan SendResult, 1)
+
+	select {
+	case t.flushChan <- flushed:
+	case <-t.terminateChan:
+		return SendResult{Err: ErrTransportClosed}
+	case <-ctx.Done():
+		return SendResult{Err: ctx.Err()}
+	}
+
+	select {
+	case result := <-flushed:
+		return result
+	case <-ctx.Done():
+		return SendResult{Err: ctx.Err()}
+	}
+}
+
+// isStarted - checks if the transfer loop was started (safe for concurrent use)
+func (t *transportCore) isStarted() bool {
+
+	return atomic.LoadInt32(&t.started) == 1
+}
+
+// drained - checks if there is no buffered, in flight or pending point (the check is done by the running transfer
+// loop between its sends, so a point already taken from the buffer is never missed while it is being sent)
+func (t *transportCore) drained(ctx context.Context) bool {
+
+	if !t.isStarted() {
+		return t.idle()
+	}
+
+	checked := make(chan bool, 1)
+
+	select {
+	case t.drainChan <- checked:
+	case <-t.terminateChan:
+		return t.idle()
+	case <-ctx.Done():
+		return false
+	}
+
+	return <-checked
+}
+
+// idle - checks if there is no buffered or pending point
+func (t *transportCore) idle() bool {
+
+	return len(t.pointChannel) == 0 && atomic.LoadInt64(&t.pendingPoints) == 0
+}
+
+// stats - returns the transport statistics
+func (t *transportCore) stats() Stats {
+
+	return Stats{
+		Transport:        t.transport.Name(),
+		PointsSent:       atomic.LoadUint64(&t.pointsSent),
+		BatchesSent:      atomic.LoadUint64(&t.batchesSent),
+		SendErrors:       atomic.LoadUint64(&t.sendErrors),
+		FallbackBatches:  atomic.LoadUint64(&t.fallbackBatches),
+		DroppedPoints:    atomic.LoadUint64(&t.droppedPoints),
+		SkippedIntervals: atomic.LoadUint64(&t.skipIntervals),
+		Retries:          atomic.LoadUint64(&t.retries),
+		BufferedPoints:   len(t.pointChannel),
+		PendingPoints:    int(atomic.LoadInt64(&t.pendingPoints)),
+		LastSendLatency:  time.Duration(atomic.LoadInt64(&t.lastSendLatency)),
+	}
 }
@@ -0,0 +1,99 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+* A minimal prometheus text exposition format writer.
+**/
+
+const (
+	// PrometheusCounter - the prometheus counter metric type
+	PrometheusCounter string = "counter"
+
+	// PrometheusGauge - the prometheus gauge metric type
+	PrometheusGauge string = "gauge"
+
+	// PrometheusContentType - the prometheus text exposition format content type
+	PrometheusContentType string = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+// PrometheusMetric - a metric sample exposed in the prometheus text format
+type PrometheusMetric struct {
+	Name   string
+	Help   string
+	Type   string
+	Labels map[string]string
+	Value  float64
+}
+
+// labelValueEscaper - escapes the label values
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// helpEscaper - escapes the help texts
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// WritePrometheusMetrics - writes the metrics in the prometheus text exposition format
+// (the samples of the same metric must be consecutive to share the help and type lines)
+func WritePrometheusMetrics(w io.Writer, metrics []PrometheusMetric) error {
+
+	bw := bufio.NewWriter(w)
+
+	for i, m := range metrics {
+
+		if i == 0 || metrics[i-1].Name != m.Name {
+			if len(m.Help) > 0 {
+				fmt.Fprintf(bw, "# HELP %s %s\n", m.Name, helpEscaper.Replace(m.Help))
+			}
+
+			if len(m.Type) > 0 {
+				fmt.Fprintf(bw, "# TYPE %s %s\n", m.Name, m.Type)
+			}
+		}
+
+		bw.WriteString(m.Name)
+
+		if len(m.Labels) > 0 {
+			keys := make([]string, 0, len(m.Labels))
+			for k := range m.Labels {
+				keys = append(keys, k)
+			}
+
+			sort.Strings(keys)
+
+			bw.WriteByte('{')
+			for j, k := range keys {
+				if j > 0 {
+					bw.WriteByte(',')
+				}
+
+				fmt.Fprintf(bw, "%s=\"%s\"", k, labelValueEscaper.Replace(m.Labels[k]))
+			}
+			bw.WriteByte('}')
+		}
+
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.FormatFloat(m.Value, 'g', -1, 64))
+		bw.WriteByte('\n')
+	}
+
+	return bw.Flush()
+}
+
+// PrometheusHandler - creates a http handler exposing the metrics returned by the collect function
+func PrometheusHandler(collect func(r *http.Request) []PrometheusMetric) http.Handler {
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", PrometheusContentType)
+
+		WritePrometheusMetrics(w, collect(r))
+	})
+}